@@ -0,0 +1,573 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Danondso/palaver/internal/chime"
+	"github.com/Danondso/palaver/internal/clipboard"
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/control"
+	"github.com/Danondso/palaver/internal/dictation"
+	"github.com/Danondso/palaver/internal/history"
+	"github.com/Danondso/palaver/internal/hotkey"
+	"github.com/Danondso/palaver/internal/pipeline"
+	"github.com/Danondso/palaver/internal/postprocess"
+	"github.com/Danondso/palaver/internal/profile"
+	"github.com/Danondso/palaver/internal/recorder"
+	"github.com/Danondso/palaver/internal/transcriber"
+	"github.com/Danondso/palaver/internal/vocabulary"
+)
+
+// app holds the recording pipeline and its inputs, shared by the TUI and
+// the headless daemon.
+type app struct {
+	cfg      *config.Config
+	trans    transcriber.Transcriber // the provider itself, used for streaming
+	chunked  transcriber.Transcriber // trans wrapped for long recordings
+	pp       postprocess.PostProcessor
+	rec      *recorder.Recorder
+	listener hotkey.Listener
+	bindings []binding // hotkey.binding entries, and hotkey.undo_key
+	paster   *clipboard.Backend
+	history  *history.Store // nil if history is disabled
+	pipe     *pipeline.Pipeline
+
+	// Set by startInput for the control API.
+	gate      *hotkey.Gate   // hotkey.key's gate
+	gates     []*hotkey.Gate // gate and those of recording bindings
+	handsFree bool
+	listen    func(arm, toggle bool) error // arms, disarms, or toggles hands-free listening
+
+	// setTheme switches the TUI theme; nil when running headless.
+	setTheme func(name string) error
+	// devicesChanged reports the input devices hotkey.key is read from
+	// when they change; nil when running headless.
+	devicesChanged func(devices []string)
+}
+
+// newApp creates the transcriber, post-processor, recorder, hotkey
+// listener, and pipeline from cfg. PortAudio must already be initialized.
+func newApp(cfg *config.Config, dbg *log.Logger) *app {
+	vocab, err := vocabulary.New(&cfg.Vocabulary)
+	if err != nil {
+		log.Fatalf("load vocabulary: %v", err)
+	}
+	commands, err := dictation.New(&cfg.Commands)
+	if err != nil {
+		log.Fatalf("load spoken commands: %v", err)
+	}
+	paster, err := clipboard.New(&cfg.Paste)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	dbg.Printf("paste: mode=%s backend=%s", cfg.Paste.Mode, paster.Name)
+	if paster.Err != nil {
+		dbg.Printf("paste: %s may not work: %v", paster.Name, paster.Err)
+	}
+
+	// Create transcriber
+	trans, err := newTranscriber(cfg, vocab, dbg)
+	if err != nil {
+		log.Fatalf("create transcriber: %v", err)
+	}
+
+	// Create post-processor
+	pp := postprocess.New(&cfg.PostProcessing, cfg.CustomTones, dbg)
+
+	// Profiles are checked after the custom tones they may use are registered.
+	profiles, err := profile.New(cfg.Profiles)
+	if err != nil {
+		log.Fatalf("load profiles: %v", err)
+	}
+
+	// Warn if sending audio over plaintext HTTP to a non-local host
+	if u, err := url.Parse(cfg.Transcription.BaseURL); err == nil {
+		if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
+			log.Printf("WARNING: transcription base_url uses plaintext HTTP to non-local host %q — audio data will be sent unencrypted", u.Hostname())
+		}
+	}
+
+	// Warn if sending transcribed text over plaintext HTTP to a non-local host
+	if cfg.PostProcessing.Enabled {
+		if u, err := url.Parse(cfg.PostProcessing.BaseURL); err == nil {
+			if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
+				log.Printf("WARNING: post_processing base_url uses plaintext HTTP to non-local host %q — transcribed text will be sent unencrypted", u.Hostname()) //nolint:gosec // hostname from user config, safely quoted with %q
+			}
+		}
+	}
+
+	// Create chime player
+	chimePlayer, err := chime.New(cfg.Audio.ChimeStart, cfg.Audio.ChimeStop, cfg.Audio.ChimeEnabled, dbg)
+	if err != nil {
+		log.Fatalf("create chime player: %v", err)
+	}
+
+	// Create recorder
+	rec, err := recorder.New(cfg.Audio.TargetSampleRate, cfg.Audio.MaxDurationSec)
+	if err != nil {
+		log.Fatalf("create recorder: %v", err)
+	}
+	rec.SetVAD(recorder.VADConfig{
+		Enabled:     cfg.Audio.VADEnabled,
+		Threshold:   cfg.Audio.VADThreshold,
+		PaddingMs:   cfg.Audio.VADPaddingMs,
+		MinSpeechMs: cfg.Audio.VADMinSpeechMs,
+	}, dbg)
+
+	// Create the hotkey listeners (platform-specific): hotkey.key first,
+	// then the bindings.
+	bindings, err := newBindings(cfg)
+	if err != nil {
+		log.Fatalf("create hotkey binding: %v", err)
+	}
+	keys := []string{cfg.Hotkey.Key}
+	for _, b := range bindings {
+		keys = append(keys, b.Key)
+	}
+	listeners, err := createListeners(keys, cfg, dbg)
+	if err != nil {
+		log.Fatalf("create hotkey listener: %v", err)
+	}
+	listener := listeners[0]
+	for i := range bindings {
+		bindings[i].listener = listeners[i+1]
+	}
+	dbg.Printf("hotkey: %s", listener.KeyName())
+
+	var store *history.Store
+	if cfg.History.Enabled {
+		store = history.New(&cfg.History)
+	}
+
+	chunked := withChunking(trans, cfg, dbg)
+	pipe := pipeline.New(cfg, chunked, pp, chimePlayer, store, dbg)
+	pipe.SetVocabulary(vocab)
+	pipe.SetCommands(commands)
+	pipe.SetPaster(paster)
+	pipe.SetProfiles(profiles, profile.FocusedWindow)
+	return &app{
+		cfg:      cfg,
+		trans:    trans,
+		chunked:  chunked,
+		pp:       pp,
+		rec:      rec,
+		listener: listener,
+		bindings: bindings,
+		paster:   paster,
+		history:  store,
+		pipe:     pipe,
+	}
+}
+
+// binding is a key that triggers an action other than hotkey.key's.
+type binding struct {
+	config.HotkeyBinding
+	listener hotkey.Listener
+}
+
+// newBindings validates the [[hotkey.binding]] entries. Their listeners
+// are created with hotkey.key's. hotkey.undo_key is a binding for the undo
+// action.
+func newBindings(cfg *config.Config) ([]binding, error) {
+	entries := cfg.Hotkey.Bindings
+	if cfg.Hotkey.UndoKey != "" {
+		entries = append(slices.Clone(entries), config.HotkeyBinding{Key: cfg.Hotkey.UndoKey, Action: hotkey.ActionUndo})
+	}
+	bindings := make([]binding, 0, len(entries))
+	for _, b := range entries {
+		if err := hotkey.ValidateAction(b.Action); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Key, err)
+		}
+		if b.Tone != "" {
+			if !hotkey.TakesTone(b.Action) {
+				return nil, fmt.Errorf("%s: tone does not apply to action %s", b.Key, b.Action)
+			}
+			if !postprocess.HasTone(b.Tone) {
+				return nil, fmt.Errorf("%s: unknown tone %q", b.Key, b.Tone)
+			}
+		}
+		bindings = append(bindings, binding{HotkeyBinding: b})
+	}
+	return bindings, nil
+}
+
+// startInput wires the hotkey listener and recorder to the pipeline and
+// starts listening until ctx is cancelled.
+func (a *app) startInput(ctx context.Context, dbg *log.Logger) error {
+	cfg, rec, pipe, listener := a.cfg, a.rec, a.pipe, a.listener
+	var recMu sync.Mutex
+
+	if err := recorder.ValidateTrigger(cfg.Audio.Trigger); err != nil {
+		return fmt.Errorf("audio trigger: %w", err)
+	}
+	handsFree := strings.EqualFold(strings.TrimSpace(cfg.Audio.Trigger), recorder.TriggerVAD)
+
+	// Streaming transcription: chunks are transcribed while the key is held.
+	streamer, canStream := a.trans.(transcriber.StreamTranscriber)
+	streaming := cfg.Transcription.Streaming && canStream && !handsFree
+	switch {
+	case cfg.Transcription.Streaming && !canStream && len(cfg.Transcription.Backends) > 0:
+		log.Printf("WARNING: transcription streaming is disabled because transcription.backends is set; the fallback chain transcribes whole recordings")
+	case cfg.Transcription.Streaming && !canStream:
+		dbg.Printf("transcription streaming not supported by provider %q, using batch mode", cfg.Transcription.Provider)
+	}
+	if cfg.Transcription.Streaming && handsFree {
+		dbg.Printf("transcription streaming is not used with the vad trigger, using batch mode")
+	}
+
+	// handleRecording forwards a finished recording (or why there is none)
+	// to the pipeline.
+	handleRecording := func(wavData []byte, truncated bool, err error) {
+		if errors.Is(err, recorder.ErrNoSpeech) {
+			pipe.RecordingDiscarded(err.Error())
+			return
+		}
+		if err != nil {
+			dbg.Printf("recorder stop error: %v", err)
+			pipe.TranscriptionFailed(fmt.Errorf("recording: %w", err))
+			return
+		}
+		dbg.Printf("recording stopped: wav_size=%d bytes, truncated=%v", len(wavData), truncated)
+		pipe.RecordingStopped(wavData, streaming)
+	}
+
+	// Hands-free listening: the mic stays open and speech starts segments.
+	listenCfg := recorder.ListenConfig{
+		Threshold:  cfg.Audio.TriggerThreshold,
+		StartMs:    cfg.Audio.TriggerStartMs,
+		HangoverMs: cfg.Audio.TriggerHangoverMs,
+		PreRollMs:  cfg.Audio.TriggerPreRollMs,
+	}
+	// setListening arms or disarms the mic; toggle flips the current state
+	// instead, under the same lock.
+	setListening := func(arm, toggle bool) error {
+		recMu.Lock()
+		defer recMu.Unlock()
+		if toggle {
+			arm = !rec.IsArmed()
+		}
+		if arm == rec.IsArmed() {
+			return nil
+		}
+		if !arm {
+			if err := rec.Disarm(); err != nil {
+				dbg.Printf("recorder disarm error: %v", err)
+			}
+			pipe.Listening(false)
+			return nil
+		}
+		// Segments heard while an earlier one is transcribed are queued by
+		// the pipeline.
+		err := rec.Arm(listenCfg,
+			pipe.SegmentStarted,
+			func(wavData []byte, err error) {
+				if err != nil {
+					handleRecording(nil, false, err)
+					return
+				}
+				pipe.SegmentStopped(wavData)
+			},
+		)
+		if err != nil {
+			dbg.Printf("recorder arm error: %v", err)
+			pipe.TranscriptionFailed(fmt.Errorf("listening: %w", err))
+			return err
+		}
+		pipe.Listening(true)
+		return nil
+	}
+	toggleListening := func() { _ = setListening(false, true) }
+	var streamStopped chan bool // receives whether rec.Stop succeeded; guarded by recMu
+
+	// newGate creates a gate that records in mode and processes each
+	// recording with o. Gates share the recorder, so only one records at a
+	// time; the others fail to start meanwhile.
+	newGate := func(mode string, o pipeline.Override) (*hotkey.Gate, error) {
+		return hotkey.NewGate(mode, time.Duration(cfg.Hotkey.HoldThresholdMs)*time.Millisecond,
+			// onStart: start recording
+			func() error {
+				recMu.Lock()
+				defer recMu.Unlock()
+				if streaming {
+					chunks, err := rec.StartStream(cfg.Transcription.StreamChunkSec)
+					if err != nil {
+						dbg.Printf("recorder start error: %v", err)
+						return err
+					}
+					// Started first so the stream uses the profile's language.
+					pipe.RecordingStartedWith(o)
+					streamStopped = make(chan bool, 1)
+					go streamTranscription(streamer, chunks, streamStopped, pipe, dbg)
+					return nil
+				}
+				if err := rec.Start(); err != nil {
+					dbg.Printf("recorder start error: %v", err)
+					return err
+				}
+				pipe.RecordingStartedWith(o)
+				return nil
+			},
+			// onStop: stop recording, send WAV data
+			func() {
+				recMu.Lock()
+				defer recMu.Unlock()
+				wavData, truncated, err := rec.Stop()
+				if streamStopped != nil {
+					streamStopped <- err == nil
+					streamStopped = nil
+				}
+				handleRecording(wavData, truncated, err)
+			},
+			// onLatch: hybrid tap keeps recording after release
+			func() {
+				dbg.Printf("hotkey latched: recording until next tap")
+				pipe.RecordingLatched()
+			},
+		)
+	}
+	gate, err := newGate(cfg.Hotkey.Mode, pipeline.Override{})
+	if err != nil {
+		return fmt.Errorf("hotkey mode: %w", err)
+	}
+	dbg.Printf("hotkey mode: %s", gate.Mode())
+	a.gate, a.gates, a.handsFree = gate, []*hotkey.Gate{gate}, handsFree
+	a.listen = setListening
+
+	if handsFree {
+		dbg.Printf("recording trigger: vad (hotkey pauses/resumes listening)")
+		go toggleListening()
+	}
+
+	if dw, ok := listener.(hotkey.DeviceWatcher); ok {
+		dw.NotifyDevices(func(devices []string) {
+			dbg.Printf("hotkey: devices: %s", strings.Join(devices, ", "))
+			if a.devicesChanged != nil {
+				a.devicesChanged(devices)
+			}
+		})
+	}
+
+	go func() {
+		err := listener.Start(ctx,
+			func() {
+				dbg.Printf("hotkey down: %s", listener.KeyName())
+				if handsFree {
+					toggleListening()
+					return
+				}
+				gate.Down()
+			},
+			func() {
+				dbg.Printf("hotkey up: %s", listener.KeyName())
+				if handsFree {
+					return
+				}
+				gate.Up()
+			},
+		)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "hotkey listener error: %v\n", err)
+		}
+	}()
+
+	for _, b := range a.bindings {
+		onDown, onUp, err := a.bindingHandlers(b.HotkeyBinding, newGate, toggleListening, dbg)
+		if err != nil {
+			return fmt.Errorf("hotkey binding %s: %w", b.listener.KeyName(), err)
+		}
+		go func() {
+			err := b.listener.Start(ctx, func() {
+				dbg.Printf("hotkey down: %s (%s)", b.listener.KeyName(), b.Action)
+				onDown()
+			}, onUp)
+			if err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "hotkey listener error (%s): %v\n", b.listener.KeyName(), err)
+			}
+		}()
+	}
+	return nil
+}
+
+// bindingHandlers returns the key down and up handlers for a
+// [[hotkey.binding]]. With the vad trigger, recording bindings pause and
+// resume listening like hotkey.key.
+func (a *app) bindingHandlers(b config.HotkeyBinding, newGate func(string, pipeline.Override) (*hotkey.Gate, error), toggleListening func(), dbg *log.Logger) (onDown, onUp func(), err error) {
+	action := strings.ToLower(strings.TrimSpace(b.Action))
+	switch action {
+	case hotkey.ActionUndo:
+		return func() {
+			if err := a.Undo(); err != nil {
+				dbg.Printf("undo: %v", err)
+			}
+		}, nil, nil
+	case hotkey.ActionCycleTone:
+		return func() {
+			next := postprocess.NextTone(a.pipe.Status().Tone)
+			if err := a.SetTone(next); err != nil {
+				dbg.Printf("tone: %v", err)
+				return
+			}
+			dbg.Printf("tone: %s", next)
+		}, nil, nil
+	}
+
+	if a.handsFree {
+		return toggleListening, nil, nil
+	}
+	mode := a.cfg.Hotkey.Mode
+	o := pipeline.Override{Tone: b.Tone}
+	switch action {
+	case hotkey.ActionRecordRaw:
+		o.Tone = "off"
+	case hotkey.ActionRecordCopy:
+		o.Copy = true
+	case hotkey.ActionToggle:
+		mode = hotkey.ModeToggle
+	}
+	gate, err := newGate(mode, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.gates = append(a.gates, gate)
+	return gate.Down, gate.Up, nil
+}
+
+// close releases the microphone if hands-free listening is armed.
+func (a *app) close() {
+	if a.rec.IsArmed() {
+		_ = a.rec.Disarm()
+	}
+}
+
+// Start begins recording, or arms listening with the vad trigger. It
+// implements control.Controller.
+func (a *app) Start() error {
+	if a.handsFree {
+		return a.listen(true, false)
+	}
+	return a.gate.Start()
+}
+
+// Stop ends the current recording, whichever key started it, or pauses
+// listening with the vad trigger.
+func (a *app) Stop() error {
+	if a.handsFree {
+		return a.listen(false, false)
+	}
+	a.stopRecording()
+	return nil
+}
+
+// Toggle stops an active recording or starts a new one; with the vad
+// trigger it pauses or resumes listening, like the hotkey.
+func (a *app) Toggle() error {
+	if a.handsFree {
+		return a.listen(false, true)
+	}
+	if a.stopRecording() {
+		return nil
+	}
+	return a.gate.Start()
+}
+
+// stopRecording stops the gate that is recording, if any, and reports
+// whether there was one.
+func (a *app) stopRecording() bool {
+	for _, g := range a.gates {
+		if g.Stop() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTone switches the post-processing tone, keeping the current model.
+func (a *app) SetTone(name string) error {
+	return a.pipe.SetPostProcessing(name, a.pipe.Status().PostModel)
+}
+
+// SetModel switches the post-processing model, keeping the current tone.
+// The model is used once a tone other than off is selected.
+func (a *app) SetModel(name string) error {
+	return a.pipe.SetPostProcessing(a.pipe.Status().Tone, name)
+}
+
+// SetTheme switches the TUI theme. Headless instances have no theme.
+func (a *app) SetTheme(name string) error {
+	if a.setTheme == nil {
+		return fmt.Errorf("%w: themes only apply to the TUI", control.ErrInvalid)
+	}
+	return a.setTheme(name)
+}
+
+// Repaste pastes the last transcript again after delayMs, or after the
+// configured paste delay if that is longer.
+func (a *app) Repaste(delayMs int) error {
+	text := a.pipe.Status().LastTranscript
+	if text == "" {
+		return fmt.Errorf("%w: no transcript yet", control.ErrNotFound)
+	}
+	return a.pipe.Repaste(text, max(delayMs, a.cfg.Paste.DelayMs))
+}
+
+// Undo deletes the last paste, provided the window it went to still has
+// focus.
+func (a *app) Undo() error {
+	return a.pipe.Undo(false)
+}
+
+// Status returns the pipeline's current status.
+func (a *app) Status() pipeline.Status {
+	return a.pipe.Status()
+}
+
+// Subscribe delivers pipeline events; see pipeline.Pipeline.Subscribe.
+func (a *app) Subscribe() (<-chan pipeline.Event, func()) {
+	return a.pipe.Subscribe()
+}
+
+// serveControl starts the control API if it is enabled. It returns nil if
+// the API is disabled or the socket could not be created; the failure is
+// only logged, since the app works without it.
+func (a *app) serveControl(dbg *log.Logger) *control.Server {
+	if !a.cfg.Control.Enabled {
+		return nil
+	}
+	srv, err := control.Listen(control.SocketPath(a.cfg.Control.Socket), a, dbg)
+	if err != nil {
+		log.Printf("WARNING: control API disabled: %v", err)
+		return nil
+	}
+	go func() {
+		if err := srv.Serve(); err != nil {
+			log.Printf("control API error: %v", err)
+		}
+	}()
+	return srv
+}
+
+// streamTranscription feeds recorder chunks to a streaming transcriber,
+// forwarding partial text to the pipeline. The final result is only
+// delivered once the recording has stopped, so it always follows
+// RecordingStopped.
+func streamTranscription(st transcriber.StreamTranscriber, chunks <-chan []byte, stopped <-chan bool, pipe *pipeline.Pipeline, dbg *log.Logger) {
+	text, err := st.TranscribeStream(pipe.TranscribeContext(context.Background()), chunks, pipe.Partial)
+	if ok := <-stopped; !ok {
+		return // the stop error has already been reported
+	}
+	if err != nil {
+		dbg.Printf("transcription stream error: %v", err)
+		pipe.TranscriptionFailed(err)
+		return
+	}
+	pipe.TranscriptionResult(text)
+}
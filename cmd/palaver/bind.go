@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/hotkey"
+	"github.com/Danondso/palaver/internal/tui"
+)
+
+// handleBind implements `palaver bind`: press a key, mouse button, or
+// combo on any input device and save it as the hotkey.
+func handleBind(args []string) {
+	fs := flag.NewFlagSet("bind", flag.ExitOnError)
+	action := fs.String("action", "", "add a [[hotkey.binding]] for this action (record, record_raw, record_copy, toggle, undo, cycle_tone) instead of setting hotkey.key")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: palaver bind [flags]\n\nPick the hotkey by pressing it on any keyboard, mouse, or pedal.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if newCapture == nil {
+		log.Fatalf("bind: not supported on macOS; set hotkey.key to a combo such as Cmd+Option (see Hotkey Combos)")
+	}
+	if *action != "" {
+		if err := hotkey.ValidateAction(*action); err != nil {
+			log.Fatalf("bind: %v", err)
+		}
+	}
+
+	path := config.DefaultPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	final, err := tea.NewProgram(tui.NewBindModel(cfg, path, *action, newCapture)).Run()
+	if err != nil {
+		log.Fatalf("bind: %v", err)
+	}
+	m := final.(tui.BindModel)
+	switch {
+	case m.Err != nil:
+		log.Fatalf("bind: %v", m.Err)
+	case m.Saved == "":
+		fmt.Println("Cancelled; nothing saved.")
+	case *action != "":
+		fmt.Printf("Added a %s binding for %s to %s. Restart palaver to use it.\n", *action, m.Saved, path)
+	default:
+		fmt.Printf("Saved hotkey.key = %s to %s. Restart palaver to use it.\n", m.Saved, path)
+	}
+}
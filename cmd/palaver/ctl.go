@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/control"
+)
+
+// Exit codes for `palaver ctl`, so scripts can tell failures apart.
+const (
+	ctlExitFailed     = 1 // the request failed or was rejected
+	ctlExitUsage      = 2 // bad command line
+	ctlExitNotRunning = 3 // no instance is listening on the socket
+	ctlExitNotFound   = 4 // nothing to show, re-paste, or undo
+	ctlExitBusy       = 5 // the instance is busy, or focus changed before an undo
+)
+
+const ctlUsage = `Usage: palaver ctl [flags] <command> [args]
+
+Control a running palaver instance (the TUI or palaver daemon).
+
+Commands:
+  toggle             start or stop recording
+  start              start recording
+  stop               stop recording
+  status             print the current status as JSON
+  last               print the last transcript
+  repaste [-delay N] paste the last transcript again after N ms
+  undo               delete the last paste from the focused window
+  tone <name>        switch the post-processing tone
+  model <name>       switch the post-processing model
+  theme <name>       switch the TUI theme
+
+Exit status: 0 on success, 1 if the request failed, 2 for usage errors,
+3 if palaver is not running, 4 if there is nothing to show, re-paste, or
+undo, and 5 if busy or the focused window changed since the paste.
+
+Flags:
+`
+
+// handleCtl implements `palaver ctl`: a client for the control API.
+func handleCtl(args []string) {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	socket := fs.String("socket", "", "control socket path (default: [control] socket, or $XDG_RUNTIME_DIR/palaver.sock)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), ctlUsage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(ctlExitUsage)
+	}
+
+	path := *socket
+	if path == "" {
+		cfg, err := config.Load(config.DefaultPath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "palaver ctl: load config: %v\n", err)
+			os.Exit(ctlExitFailed)
+		}
+		path = control.SocketPath(cfg.Control.Socket)
+	}
+
+	verb, rest := fs.Arg(0), fs.Args()[1:]
+	if err := runCtl(context.Background(), control.NewClient(path), verb, rest); err != nil {
+		fmt.Fprintf(os.Stderr, "palaver ctl: %s: %v\n", verb, err)
+		os.Exit(ctlExitCode(err))
+	}
+}
+
+// errCtlUsage marks command-line mistakes.
+var errCtlUsage = errors.New("usage")
+
+// runCtl performs one ctl command, printing its output to stdout.
+func runCtl(ctx context.Context, c *control.Client, verb string, args []string) error {
+	noArgs := func() error {
+		if len(args) != 0 {
+			return fmt.Errorf("%w: %s takes no arguments", errCtlUsage, verb)
+		}
+		return nil
+	}
+
+	switch verb {
+	case "toggle", "start", "stop", "undo":
+		if err := noArgs(); err != nil {
+			return err
+		}
+		action := c.Toggle
+		switch verb {
+		case "start":
+			action = c.Start
+		case "stop":
+			action = c.Stop
+		case "undo":
+			action = c.Undo
+		}
+		st, err := action(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(st.State)
+	case "status":
+		if err := noArgs(); err != nil {
+			return err
+		}
+		st, err := c.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(os.Stdout).Encode(st)
+	case "last":
+		if err := noArgs(); err != nil {
+			return err
+		}
+		text, err := c.LastTranscript(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(text)
+	case "repaste":
+		fs := flag.NewFlagSet("repaste", flag.ContinueOnError)
+		delay := fs.Int("delay", 0, "milliseconds to wait before pasting (at least paste.delay_ms)")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+			return fmt.Errorf("%w: palaver ctl repaste [-delay ms]", errCtlUsage)
+		}
+		_, err := c.Repaste(ctx, *delay)
+		return err
+	case "tone", "model", "theme":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: palaver ctl %s <name>", errCtlUsage, verb)
+		}
+		set := c.SetTone
+		switch verb {
+		case "model":
+			set = c.SetModel
+		case "theme":
+			set = c.SetTheme
+		}
+		_, err := set(ctx, args[0])
+		return err
+	default:
+		return fmt.Errorf("%w: unknown command %q (see palaver ctl -h)", errCtlUsage, verb)
+	}
+	return nil
+}
+
+// ctlExitCode maps a runCtl error to the exit status.
+func ctlExitCode(err error) int {
+	var apiErr *control.APIError
+	switch {
+	case errors.Is(err, errCtlUsage):
+		return ctlExitUsage
+	case errors.Is(err, control.ErrNotRunning):
+		return ctlExitNotRunning
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		return ctlExitNotFound
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict:
+		return ctlExitBusy
+	}
+	return ctlExitFailed
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gordonklaus/portaudio"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+// handleDaemon implements `palaver daemon` (also `palaver --headless`): the
+// hotkey → record → transcribe → paste pipeline without the TUI, logging
+// to stderr or a file so it can run as a systemd user service.
+func handleDaemon(args []string) {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	debug := fs.Bool("debug", false, "log internals and transcript text at debug level")
+	logFile := fs.String("log-file", "", "append logs to this file instead of stderr")
+	logFormat := fs.String("log-format", "text", "log format: text or json")
+	statusFile := fs.String("status-file", "", "keep the current pipeline status in this file as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: palaver daemon [flags]\n\nRun the dictation pipeline without the TUI.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	var out io.Writer = os.Stderr
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // log path is an explicit command-line argument
+		if err != nil {
+			log.Fatalf("open log file: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if *debug {
+		opts.Level = slog.LevelDebug
+	}
+	var handler slog.Handler
+	switch *logFormat {
+	case "text":
+		handler = slog.NewTextHandler(out, opts)
+	case "json":
+		handler = slog.NewJSONHandler(out, opts)
+	default:
+		log.Fatalf("unknown log format %q (expected text or json)", *logFormat)
+	}
+	logger := slog.New(handler)
+	log.SetOutput(out) // startup warnings and fatal errors go to the same place
+
+	dbg := log.New(io.Discard, "", 0)
+	if *debug {
+		dbg = slog.NewLogLogger(handler, slog.LevelDebug)
+	}
+
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	if err := initPortAudio(); err != nil {
+		log.Fatalf("portaudio init: %v", err)
+	}
+	defer func() { _ = portaudio.Terminate() }()
+
+	a := newApp(cfg, dbg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := startBackendIfNeeded(ctx, cfg, a.chunked, dbg)
+	ctl := a.serveControl(dbg)
+
+	events, unsubscribe := a.pipe.Subscribe()
+	defer unsubscribe()
+	if err := a.startInput(ctx, dbg); err != nil {
+		log.Fatalf("%v", err)
+	}
+	logger.Info("daemon started", "hotkey", a.listener.KeyName(), "mode", cfg.Hotkey.Mode, "trigger", cfg.Audio.Trigger, "paste", a.paster.Name, "pid", os.Getpid())
+	if a.paster.Err != nil {
+		logger.Warn("paste backend may not work", "backend", a.paster.Name, "err", a.paster.Err)
+	}
+	writeStatus(*statusFile, a.pipe.Status(), logger)
+
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case ev := <-events:
+			logEvent(logger, ev, *debug)
+			writeStatus(*statusFile, ev.Status, logger)
+			if ev.Kind == pipeline.EventSettings {
+				saveSettings(config.DefaultPath(), cfg, a.pipe.Status(), logger)
+			}
+		}
+	}
+
+	logger.Info("daemon stopping")
+	if ctl != nil {
+		_ = ctl.Close()
+	}
+	a.close()
+	if srv != nil {
+		_ = srv.Stop()
+	}
+	if *statusFile != "" {
+		_ = os.Remove(*statusFile)
+	}
+}
+
+// logEvent writes one pipeline event. Transcript text is only logged when
+// withText is set, since the log may outlive the history file's permissions.
+func logEvent(logger *slog.Logger, ev pipeline.Event, withText bool) {
+	level := slog.LevelInfo
+	switch ev.Kind {
+	case pipeline.EventPartial:
+		level = slog.LevelDebug
+	case pipeline.EventError:
+		level = slog.LevelError
+	}
+	attrs := []any{"state", ev.Status.State.String()}
+	if ev.Err != "" {
+		attrs = append(attrs, "error", ev.Err)
+	}
+	if ev.Kind == pipeline.EventRecordingDiscarded {
+		attrs = append(attrs, "reason", ev.Text)
+	} else if ev.Text != "" && withText {
+		attrs = append(attrs, "text", ev.Text)
+	}
+	if ev.Kind == pipeline.EventSettings {
+		attrs = append(attrs, "tone", ev.Status.Tone, "post_model", ev.Status.PostModel)
+	}
+	if ev.Kind == pipeline.EventTranscribed && ev.Status.Backend != "" {
+		attrs = append(attrs, "backend", ev.Status.Backend)
+	}
+	if ev.Kind == pipeline.EventListening {
+		attrs = append(attrs, "armed", ev.Status.Armed)
+	}
+	if ev.Entry != nil {
+		attrs = append(attrs, "latency_ms", ev.Entry.LatencyMs, "chars", len(ev.Entry.Text))
+	}
+	logger.Log(context.Background(), level, string(ev.Kind), attrs...)
+}
+
+// saveSettings persists a tone or model change made through the control
+// API to path, so it survives a restart as it does when made in the TUI.
+// While post-processing is off, the configured tone is kept for when it is
+// enabled again.
+func saveSettings(path string, cfg *config.Config, st pipeline.Status, logger *slog.Logger) {
+	pp := &cfg.PostProcessing
+	enabled := !strings.EqualFold(st.Tone, "off")
+	tone := pp.Tone
+	if enabled {
+		tone = st.Tone
+	}
+	if strings.EqualFold(pp.Tone, tone) && pp.Model == st.PostModel && pp.Enabled == enabled {
+		return
+	}
+	pp.Tone = tone
+	pp.Model = st.PostModel
+	pp.Enabled = enabled
+	if err := config.Save(path, cfg); err != nil {
+		logger.Error("save config", "error", err)
+	}
+}
+
+// writeStatus replaces path with the JSON-encoded status, for status bars
+// and scripts. It does nothing if path is empty.
+func writeStatus(path string, st pipeline.Status, logger *slog.Logger) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		logger.Error("encode status", "error", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".palaver-status-*")
+	if err != nil {
+		logger.Error("write status", "error", err)
+		return
+	}
+	_, werr := tmp.Write(append(data, '\n'))
+	cerr := tmp.Close()
+	if werr == nil && cerr == nil {
+		werr = os.Rename(tmp.Name(), path)
+	}
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		logger.Error("write status", "error", errors.Join(werr, cerr))
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+func TestSaveSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		tone        string // configured tone
+		status      pipeline.Status
+		wantSaved   bool
+		wantTone    string
+		wantModel   string
+		wantEnabled bool
+	}{
+		{
+			name: "model changed while disabled", tone: "formal",
+			status:    pipeline.Status{Tone: "off", PostModel: "qwen3"},
+			wantSaved: true, wantTone: "formal", wantModel: "qwen3",
+		},
+		{
+			name: "tone chosen", tone: "off",
+			status:    pipeline.Status{Tone: "direct"},
+			wantSaved: true, wantTone: "direct", wantEnabled: true,
+		},
+		{
+			name: "tone turned off", enabled: true, tone: "formal",
+			status:    pipeline.Status{Tone: "off"},
+			wantSaved: true, wantTone: "formal",
+		},
+		{
+			name: "unchanged", enabled: true, tone: "formal",
+			status:   pipeline.Status{Tone: "FORMAL", PostModel: "llama3.2"},
+			wantTone: "formal", wantEnabled: true,
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			cfg := config.Default()
+			cfg.PostProcessing.Enabled = tt.enabled
+			cfg.PostProcessing.Tone = tt.tone
+
+			saveSettings(path, cfg, tt.status, logger)
+			_, err := os.Stat(path)
+			if saved := err == nil; saved != tt.wantSaved {
+				t.Fatalf("expected saved=%v, got %v", tt.wantSaved, saved)
+			}
+			if !tt.wantSaved {
+				return
+			}
+			loaded, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			pp := loaded.PostProcessing
+			if pp.Tone != tt.wantTone || pp.Model != tt.wantModel || pp.Enabled != tt.wantEnabled {
+				t.Errorf("expected tone=%q model=%q enabled=%v, got tone=%q model=%q enabled=%v",
+					tt.wantTone, tt.wantModel, tt.wantEnabled, pp.Tone, pp.Model, pp.Enabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/history"
+)
+
+// handleHistory implements `palaver history`: list, filter, and export the
+// transcription history written by the TUI.
+func handleHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	since := fs.String("since", "", "only entries at or after this time (today, yesterday, YYYY-MM-DD, RFC 3339, or 12h/7d ago)")
+	until := fs.String("until", "", "only entries before this time (same forms as -since)")
+	tone := fs.String("tone", "", "only entries rewritten with this tone (\"off\" for none)")
+	grep := fs.String("grep", "", "only entries whose text contains this string (case-insensitive)")
+	format := fs.String("format", history.FormatText, "output format: text, jsonl, markdown, csv")
+	limit := fs.Int("limit", 0, "only the most recent N matching entries (0 = all)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: palaver history [flags]\n\nList and export past transcriptions.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	exportFormat, err := history.ParseFormat(*format)
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+
+	now := time.Now()
+	filter := history.Filter{Tone: *tone, Contains: *grep}
+	if *since != "" {
+		if filter.Since, err = history.ParseTime(*since, now); err != nil {
+			log.Fatalf("history: -since: %v", err)
+		}
+	}
+	if *until != "" {
+		if filter.Until, err = history.ParseTime(*until, now); err != nil {
+			log.Fatalf("history: -until: %v", err)
+		}
+	}
+
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	store := history.New(&cfg.History)
+	entries, err := store.Load()
+	if err != nil {
+		log.Fatalf("history: %v", err)
+	}
+
+	entries = filter.Apply(entries)
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[len(entries)-*limit:]
+	}
+
+	if err := history.Export(os.Stdout, entries, exportFormat); err != nil {
+		log.Fatalf("history: %v", err)
+	}
+}
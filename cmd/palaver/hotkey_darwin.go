@@ -11,16 +11,23 @@ import (
 	"github.com/Danondso/palaver/internal/hotkey"
 )
 
-func createListener(cfg *config.Config, dbg *log.Logger) (hotkey.Listener, error) {
-	mods, key, keyName, err := hotkey.ParseHotkeyCombo(cfg.Hotkey.Key)
-	if err != nil {
-		return nil, err
+// createListeners listens for keys, each a key combination.
+func createListeners(keys []string, _ *config.Config, _ *log.Logger) ([]hotkey.Listener, error) {
+	ls := make([]hotkey.Listener, len(keys))
+	for i, combo := range keys {
+		mods, key, keyName, err := hotkey.ParseHotkeyCombo(combo)
+		if err != nil {
+			return nil, err
+		}
+		ls[i] = hotkey.NewListener(mods, key, keyName)
 	}
-	dbg.Printf("hotkey: %s", keyName)
-
-	return hotkey.NewListener(mods, key, keyName), nil
+	return ls, nil
 }
 
+// newCapture is nil: the hotkey capture reads evdev devices, which macOS
+// does not have.
+var newCapture func() (hotkey.Capture, error)
+
 // initPortAudio initializes PortAudio. On macOS, no stderr suppression is needed
 // since CoreAudio doesn't produce ALSA/JACK noise.
 func initPortAudio() error {
@@ -5,6 +5,7 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 	"syscall"
 
 	"github.com/gordonklaus/portaudio"
@@ -13,22 +14,33 @@ import (
 	"github.com/Danondso/palaver/internal/hotkey"
 )
 
-func createListener(cfg *config.Config, dbg *log.Logger) (hotkey.Listener, error) {
-	keyCode, err := hotkey.KeyCodeFromName(cfg.Hotkey.Key)
-	if err != nil {
-		return nil, err
+// createListeners listens for keys, each a single key or a combo such as
+// Ctrl+Alt+Space, on the configured device or on every device with their
+// keys. The devices are opened once and shared by the listeners.
+func createListeners(keys []string, cfg *config.Config, dbg *log.Logger) ([]hotkey.Listener, error) {
+	chords := make([]hotkey.Chord, len(keys))
+	for i, key := range keys {
+		chord, err := hotkey.ParseChord(key)
+		if err != nil {
+			return nil, err
+		}
+		chords[i] = chord
 	}
-	dbg.Printf("hotkey: %s (code=%d)", cfg.Hotkey.Key, keyCode)
 
-	dev, err := hotkey.FindKeyboard(cfg.Hotkey.Device)
+	ls, err := hotkey.NewListeners(cfg.Hotkey.Device, chords)
 	if err != nil {
 		return nil, err
 	}
-	dbg.Printf("keyboard device: %s", dev.Path())
-
-	return hotkey.NewListener(dev, keyCode, cfg.Hotkey.Key), nil
+	if dw, ok := ls[0].(hotkey.DeviceWatcher); ok {
+		dbg.Printf("hotkey: devices: %s", strings.Join(dw.Devices(), ", "))
+	}
+	return ls, nil
 }
 
+// newCapture opens every input device for `palaver bind` and the TUI's
+// hotkey capture.
+var newCapture = hotkey.NewCapture
+
 // initPortAudio suppresses ALSA/JACK noise during PortAudio initialization
 // by temporarily redirecting stderr to /dev/null, then calls portaudio.Initialize().
 func initPortAudio() error {
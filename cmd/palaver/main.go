@@ -6,20 +6,19 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net/url"
 	"os"
-	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gordonklaus/portaudio"
 
-	"github.com/Danondso/palaver/internal/chime"
 	"github.com/Danondso/palaver/internal/config"
-	"github.com/Danondso/palaver/internal/postprocess"
+	"github.com/Danondso/palaver/internal/control"
+	"github.com/Danondso/palaver/internal/hotkey"
 	"github.com/Danondso/palaver/internal/recorder"
 	"github.com/Danondso/palaver/internal/server"
 	"github.com/Danondso/palaver/internal/transcriber"
 	"github.com/Danondso/palaver/internal/tui"
+	"github.com/Danondso/palaver/internal/vocabulary"
 )
 
 // micCheckerAdapter adapts the package-level recorder.MicAvailable function
@@ -46,6 +45,7 @@ func handleSetup() {
 
 func runSetup(cfg *config.Config, dbg *log.Logger) {
 	srv := server.New(&cfg.Server, dbg)
+	srv.Language = cfg.Transcription.Language
 
 	fmt.Println("=== Palaver Setup ===")
 	fmt.Println()
@@ -85,16 +85,78 @@ func runSetup(cfg *config.Config, dbg *log.Logger) {
 	fmt.Println("Setup complete. Run 'palaver' to start.")
 }
 
+// newTranscriber creates the transcriber from cfg, adding the vocabulary's
+// terms to the prompt.
+func newTranscriber(cfg *config.Config, vocab *vocabulary.Vocabulary, dbg *log.Logger) (transcriber.Transcriber, error) {
+	tc := cfg.Transcription
+	tc.Prompt = vocab.Prompt(tc.Prompt)
+	return transcriber.New(&tc, dbg)
+}
+
+// chunkSearchMs is how far before each chunk limit the splitter looks for
+// a pause to cut at.
+const chunkSearchMs = 5000
+
+// withChunking wraps trans so recordings longer than transcription.chunk_sec
+// are split at pauses and transcribed in parallel.
+func withChunking(trans transcriber.Transcriber, cfg *config.Config, dbg *log.Logger) transcriber.Transcriber {
+	if cfg.Transcription.ChunkSec <= 0 {
+		return trans
+	}
+	splitCfg := recorder.SplitConfig{
+		MaxChunkSec:      cfg.Transcription.ChunkSec,
+		OverlapMs:        cfg.Transcription.ChunkOverlapMs,
+		SearchMs:         chunkSearchMs,
+		SilenceThreshold: cfg.Audio.VADThreshold,
+	}
+	split := func(wavData []byte) ([]transcriber.Chunk, error) {
+		parts, err := recorder.SplitWAV(wavData, splitCfg)
+		if err != nil {
+			return nil, err
+		}
+		chunks := make([]transcriber.Chunk, len(parts))
+		for i, p := range parts {
+			chunks[i] = transcriber.Chunk{WAV: p.WAV, Offset: p.Offset, Overlap: p.Overlap}
+		}
+		return chunks, nil
+	}
+	return transcriber.NewChunked(trans, split, cfg.Transcription.ChunkParallelism, dbg)
+}
+
 func run() {
-	// Handle setup subcommand before flag parsing
-	if len(os.Args) > 1 && os.Args[1] == "setup" {
-		handleSetup()
-		return
+	// Handle subcommands before flag parsing
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "setup":
+			handleSetup()
+			return
+		case "history":
+			handleHistory(os.Args[2:])
+			return
+		case "transcribe":
+			handleTranscribe(os.Args[2:])
+			return
+		case "ctl":
+			handleCtl(os.Args[2:])
+			return
+		case "bind":
+			handleBind(os.Args[2:])
+			return
+		case "daemon", "-headless", "--headless":
+			handleDaemon(os.Args[2:])
+			return
+		}
 	}
 
 	debug := flag.Bool("debug", false, "enable debug logging to stderr")
+	headless := flag.Bool("headless", false, "run without the TUI (same as 'palaver daemon')")
 	flag.Parse()
 
+	if *headless {
+		handleDaemon(append([]string{fmt.Sprintf("-debug=%t", *debug)}, flag.Args()...))
+		return
+	}
+
 	// Set up debug logger
 	var dbg *log.Logger
 	if *debug {
@@ -109,6 +171,11 @@ func run() {
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
+	// The TUI draws on standard output, so transcripts written there would
+	// corrupt it.
+	if cfg.Paste.Backend == "stdout" {
+		log.Fatalf(`paste.backend = "stdout" only works with 'palaver daemon'`)
+	}
 
 	// Initialize PortAudio (Linux suppresses ALSA/JACK stderr noise)
 	if err := initPortAudio(); err != nil {
@@ -118,54 +185,13 @@ func run() {
 
 	dbg.Printf("portaudio initialized")
 
-	// Create transcriber
-	trans, err := transcriber.New(&cfg.Transcription, dbg)
-	if err != nil {
-		log.Fatalf("create transcriber: %v", err)
-	}
-
-	// Create post-processor
-	pp := postprocess.New(&cfg.PostProcessing, cfg.CustomTones, dbg)
-
-	// Warn if sending audio over plaintext HTTP to a non-local host
-	if u, err := url.Parse(cfg.Transcription.BaseURL); err == nil {
-		if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
-			log.Printf("WARNING: transcription base_url uses plaintext HTTP to non-local host %q — audio data will be sent unencrypted", u.Hostname())
-		}
-	}
-
-	// Warn if sending transcribed text over plaintext HTTP to a non-local host
-	if cfg.PostProcessing.Enabled {
-		if u, err := url.Parse(cfg.PostProcessing.BaseURL); err == nil {
-			if u.Scheme == "http" && u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1" && u.Hostname() != "::1" {
-				log.Printf("WARNING: post_processing base_url uses plaintext HTTP to non-local host %q — transcribed text will be sent unencrypted", u.Hostname()) //nolint:gosec // hostname from user config, safely quoted with %q
-			}
-		}
-	}
-
-	// Create chime player
-	chimePlayer, err := chime.New(cfg.Audio.ChimeStart, cfg.Audio.ChimeStop, cfg.Audio.ChimeEnabled, dbg)
-	if err != nil {
-		log.Fatalf("create chime player: %v", err)
-	}
-
-	// Create recorder
-	rec, err := recorder.New(cfg.Audio.TargetSampleRate, cfg.Audio.MaxDurationSec)
-	if err != nil {
-		log.Fatalf("create recorder: %v", err)
-	}
-
-	// Create hotkey listener (platform-specific)
-	listener, err := createListener(cfg, dbg)
-	if err != nil {
-		log.Fatalf("create hotkey listener: %v", err)
-	}
-	dbg.Printf("hotkey: %s", listener.KeyName())
+	a := newApp(cfg, dbg)
 
 	// Managed server (auto-start if configured and installed)
 	var srv *server.Server
 	if cfg.Server.AutoStart {
 		srv = server.New(&cfg.Server, dbg)
+		srv.Language = cfg.Transcription.Language
 		if srv.IsInstalled() {
 			dbg.Printf("managed parakeet server is installed, will auto-start")
 		} else {
@@ -175,8 +201,20 @@ func run() {
 	}
 
 	// Create TUI model and program
-	model := tui.NewModel(cfg, trans, pp, chimePlayer, rec, micCheckerAdapter{}, dbg, *debug)
+	model := tui.NewModel(cfg, a.pipe, a.chunked, a.pp, a.rec, micCheckerAdapter{}, dbg, *debug)
 	model.Server = srv
+	model.History = a.history
+	model.PasteBackend = a.paster.Name
+	model.NewCapture = newCapture
+	if a.paster.Err != nil {
+		model.PasteErr = a.paster.Err.Error()
+	}
+	if srv != nil {
+		model.LanguageWarn = srv.LanguageWarning(cfg.Transcription.Language)
+	}
+	if dw, ok := a.listener.(hotkey.DeviceWatcher); ok {
+		model.InputDevices = dw.Devices()
+	}
 	serverCtx, serverCancel := context.WithCancel(context.Background())
 	model.ServerCtx = serverCtx
 	model.ServerCancel = serverCancel
@@ -187,44 +225,27 @@ func run() {
 		dbg.SetOutput(tui.NewLogWriter(p))
 	}
 
+	events, unsubscribe := a.pipe.Subscribe()
+	defer unsubscribe()
+	go tui.ForwardEvents(p, events)
+	a.setTheme = func(name string) error {
+		if !tui.HasTheme(name) {
+			return fmt.Errorf("%w: unknown theme %s", control.ErrInvalid, name)
+		}
+		p.Send(tui.ThemeMsg{Name: name})
+		return nil
+	}
+	a.devicesChanged = func(devices []string) {
+		p.Send(tui.InputDevicesMsg{Devices: devices})
+	}
+
 	// Hotkey listener
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-
-	var recMu sync.Mutex
-
-	go func() {
-		err := listener.Start(ctx,
-			// onDown: start recording
-			func() {
-				dbg.Printf("hotkey down: %s", listener.KeyName())
-				recMu.Lock()
-				defer recMu.Unlock()
-				if err := rec.Start(); err != nil {
-					dbg.Printf("recorder start error: %v", err)
-					return
-				}
-				p.Send(tui.RecordingStartedMsg{})
-			},
-			// onUp: stop recording, send WAV data
-			func() {
-				dbg.Printf("hotkey up: %s", listener.KeyName())
-				recMu.Lock()
-				defer recMu.Unlock()
-				wavData, truncated, err := rec.Stop()
-				if err != nil {
-					dbg.Printf("recorder stop error: %v", err)
-					p.Send(tui.TranscriptionErrorMsg{Err: fmt.Errorf("recording: %w", err)})
-					return
-				}
-				dbg.Printf("recording stopped: wav_size=%d bytes, truncated=%v", len(wavData), truncated)
-				p.Send(tui.RecordingStoppedMsg{WavData: wavData})
-			},
-		)
-		if err != nil && ctx.Err() == nil {
-			fmt.Fprintf(os.Stderr, "hotkey listener error: %v\n", err)
-		}
-	}()
+	if err := a.startInput(ctx, dbg); err != nil {
+		log.Fatalf("%v", err)
+	}
+	ctl := a.serveControl(dbg)
 
 	// Run TUI
 	if _, err := p.Run(); err != nil {
@@ -233,6 +254,10 @@ func run() {
 
 	// Clean shutdown
 	cancel()
+	if ctl != nil {
+		_ = ctl.Close()
+	}
+	a.close()
 	serverCancel()
 	if srv != nil {
 		_ = srv.Stop()
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/postprocess"
+	"github.com/Danondso/palaver/internal/recorder"
+	"github.com/Danondso/palaver/internal/server"
+	"github.com/Danondso/palaver/internal/transcriber"
+	"github.com/Danondso/palaver/internal/vocabulary"
+)
+
+// handleTranscribe implements `palaver transcribe <file...>`: run WAV files
+// through the configured transcriber (and optionally a tone rewrite) without
+// the TUI or a microphone.
+func handleTranscribe(args []string) {
+	fs := flag.NewFlagSet("transcribe", flag.ExitOnError)
+	tone := fs.String("tone", "", "rewrite the transcript with this tone via post_processing (default: no rewrite)")
+	sidecar := fs.Bool("sidecar", false, "write <file>.txt next to each input instead of printing to stdout")
+	debug := fs.Bool("debug", false, "enable debug logging to stderr")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: palaver transcribe [flags] <file.wav>...\n\nTranscribe WAV files with the configured provider.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	files := fs.Args()
+	if len(files) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var dbg *log.Logger
+	if *debug {
+		dbg = log.New(os.Stderr, "[DEBUG] ", log.Ltime|log.Lmicroseconds)
+	} else {
+		dbg = log.New(io.Discard, "", 0)
+	}
+
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	vocab, err := vocabulary.New(&cfg.Vocabulary)
+	if err != nil {
+		log.Fatalf("load vocabulary: %v", err)
+	}
+
+	trans, err := newTranscriber(cfg, vocab, dbg)
+	if err != nil {
+		log.Fatalf("create transcriber: %v", err)
+	}
+	trans = withChunking(trans, cfg, dbg)
+
+	var pp postprocess.PostProcessor = &postprocess.NoopPostProcessor{}
+	if *tone != "" {
+		postprocess.RegisterCustomTones(cfg.CustomTones, dbg)
+		if postprocess.ResolveTone(*tone).Name != strings.ToLower(*tone) {
+			log.Fatalf("unknown tone: %s (available: %s)", *tone, strings.Join(postprocess.ToneNames(), ", "))
+		}
+		ppCfg := cfg.PostProcessing
+		ppCfg.Enabled = true
+		ppCfg.Tone = *tone
+		pp = postprocess.New(&ppCfg, cfg.CustomTones, dbg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if srv := startBackendIfNeeded(ctx, cfg, trans, dbg); srv != nil {
+		defer func() { _ = srv.Stop() }()
+	}
+
+	failed := false
+	for i, path := range files {
+		text, err := transcribeFile(ctx, path, cfg.Audio.TargetSampleRate, trans, vocab, pp, dbg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "palaver: %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		if *sidecar {
+			out := strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"
+			if err := os.WriteFile(out, []byte(text+"\n"), 0o644); err != nil { //nolint:gosec // sidecar transcripts are ordinary user files
+				fmt.Fprintf(os.Stderr, "palaver: %s: write %s: %v\n", path, out, err)
+				failed = true
+			}
+			continue
+		}
+		if len(files) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("==> %s <==\n", path)
+		}
+		fmt.Println(text)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// transcribeFile converts a WAV file to the transcriber's input format,
+// transcribes it, and applies the vocabulary and post-processor. A failed
+// rewrite falls back to the unrewritten transcript, as in the TUI.
+func transcribeFile(ctx context.Context, path string, targetSR int, trans transcriber.Transcriber, vocab *vocabulary.Vocabulary, pp postprocess.PostProcessor, dbg *log.Logger) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is an explicit command-line argument
+	if err != nil {
+		return "", err
+	}
+	wavData, err := recorder.ConvertWAV(data, targetSR)
+	if err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	text, err := trans.Transcribe(ctx, wavData)
+	if err != nil {
+		return "", fmt.Errorf("transcribe: %w", err)
+	}
+	dbg.Printf("transcribe %s: %d bytes in %s", path, len(wavData), time.Since(start).Round(time.Millisecond))
+	text = vocab.Apply(text)
+
+	rewritten, err := pp.Rewrite(ctx, text)
+	if err != nil {
+		dbg.Printf("post-processing error (falling back to original): %v", err)
+		return text, nil
+	}
+	return rewritten, nil
+}
+
+// startBackendIfNeeded starts the managed server when the backend is not
+// already reachable (e.g. from a running TUI) and auto_start is enabled.
+// It returns the started server, or nil if nothing was started.
+func startBackendIfNeeded(ctx context.Context, cfg *config.Config, trans transcriber.Transcriber, dbg *log.Logger) *server.Server {
+	if hc, ok := trans.(transcriber.HealthChecker); ok {
+		pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		err := hc.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			dbg.Printf("transcription backend already running")
+			return nil
+		}
+	}
+	if !cfg.Server.AutoStart {
+		return nil
+	}
+	srv := server.New(&cfg.Server, dbg)
+	srv.Language = cfg.Transcription.Language
+	if !srv.IsInstalled() {
+		dbg.Printf("managed server not installed (run 'palaver setup' first)")
+		return nil
+	}
+	if w := srv.LanguageWarning(cfg.Transcription.Language); w != "" {
+		log.Printf("WARNING: %s", w)
+	}
+	fmt.Fprintln(os.Stderr, "Starting transcription server...")
+	if err := srv.Start(ctx); err != nil {
+		log.Fatalf("start server: %v", err)
+	}
+	return srv
+}
@@ -0,0 +1,83 @@
+package clipboard
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// restoreDelay is how long the focused application gets to read the
+// clipboard after the paste shortcut, before the previous contents are put
+// back.
+const restoreDelay = 300 * time.Millisecond
+
+// chord is a paste shortcut such as ctrl+shift+v.
+type chord struct {
+	mods []string // "ctrl", "shift", "alt", or "super", in the order given
+	key  string   // a lower-case letter or digit, or "insert"
+}
+
+// modifierNames maps the accepted modifier spellings to their canonical
+// names. cmd is super, so "cmd+v" reads naturally on macOS.
+var modifierNames = map[string]string{
+	"ctrl": "ctrl", "control": "ctrl",
+	"shift": "shift",
+	"alt":   "alt", "option": "alt",
+	"super": "super", "cmd": "super", "command": "super", "meta": "super",
+}
+
+// parseChord parses a shortcut such as "Ctrl+Shift+V" or "shift+insert".
+func parseChord(s string) (chord, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "+")
+	var c chord
+	for _, p := range parts[:len(parts)-1] {
+		mod, ok := modifierNames[strings.TrimSpace(p)]
+		if !ok {
+			return chord{}, fmt.Errorf("invalid paste chord %q: unknown modifier %q", s, p)
+		}
+		if !slices.Contains(c.mods, mod) {
+			c.mods = append(c.mods, mod)
+		}
+	}
+	c.key = strings.TrimSpace(parts[len(parts)-1])
+	if c.key != "insert" && (len(c.key) != 1 || !strings.ContainsAny(c.key, "abcdefghijklmnopqrstuvwxyz0123456789")) {
+		return chord{}, fmt.Errorf("invalid paste chord %q: key must be a letter, a digit, or insert", s)
+	}
+	return c, nil
+}
+
+func (c chord) String() string {
+	return strings.Join(append(slices.Clone(c.mods), c.key), "+")
+}
+
+// clipboardPaste holds the clipboard-mode settings shared by the backends
+// that press a paste shortcut.
+type clipboardPaste struct {
+	chord   chord
+	restore bool // put the previous clipboard contents back after pasting
+}
+
+// clipboardSettings reads the clipboard-mode settings from cfg.
+func clipboardSettings(cfg *config.PasteConfig) (clipboardPaste, error) {
+	c, err := parseChord(cmp.Or(cfg.Chord, defaultChord))
+	if err != nil {
+		return clipboardPaste{}, err
+	}
+	return clipboardPaste{chord: c, restore: cfg.RestoreClipboard}, nil
+}
+
+// keyboardBackend adapts a backend constructor that takes the
+// clipboard-mode settings to the registry.
+func keyboardBackend(newFn func(clipboardPaste) Paster) func(cfg *config.PasteConfig) (Paster, error) {
+	return func(cfg *config.PasteConfig) (Paster, error) {
+		cp, err := clipboardSettings(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newFn(cp), nil
+	}
+}
@@ -0,0 +1,50 @@
+package clipboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "ctrl+v", want: "ctrl+v"},
+		{in: " Ctrl+Shift+V ", want: "ctrl+shift+v"},
+		{in: "Shift+Insert", want: "shift+insert"},
+		{in: "Cmd+Option+v", want: "super+alt+v"},
+		{in: "ctrl+ctrl+v", want: "ctrl+v"},
+		{in: "v", want: "v"},
+		{in: "hyper+v", wantErr: `unknown modifier "hyper"`},
+		{in: "ctrl+F5", wantErr: "key must be"},
+		{in: "ctrl+", wantErr: "key must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := parseChord(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, c)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidChord(t *testing.T) {
+	_, err := New(&config.PasteConfig{Backend: "auto", Chord: "ctrl+F5"})
+	if err == nil || !strings.Contains(err.Error(), "invalid paste chord") {
+		t.Errorf("expected an invalid chord error, got %v", err)
+	}
+}
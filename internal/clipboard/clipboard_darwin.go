@@ -4,29 +4,102 @@ package clipboard
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Danondso/palaver/internal/config"
 )
 
-// PasteText inserts text into the currently focused application.
-// mode "clipboard" (default on macOS) uses pbcopy + Cmd+V via osascript.
+// defaultChord is the paste shortcut when paste.chord is not set.
+const defaultChord = "cmd+v"
+
+func init() {
+	register(registration{name: "osascript", auto: true, probe: probeOsascript, new: keyboardBackend(func(cp clipboardPaste) Paster { return osascript{cp} })})
+}
+
+// defaultBackend is used when auto-detection finds nothing that works.
+func defaultBackend() string {
+	return "osascript"
+}
+
+// osascript sends keystrokes through System Events.
+// mode "clipboard" (default on macOS) uses the clipboard + Cmd+V.
 // mode "type" uses osascript keystroke for direct typing.
-func PasteText(text string, delayMs int, mode string) error {
-	if delayMs > 0 {
-		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+type osascript struct {
+	cp clipboardPaste
+}
+
+func probeOsascript(*config.PasteConfig) error {
+	if _, err := exec.LookPath("osascript"); err != nil {
+		return fmt.Errorf("osascript not found")
 	}
+	return nil
+}
 
+func (o osascript) Paste(text, mode string) error {
 	if mode == "type" {
 		return typeAppleScript(text)
 	}
+	if o.cp.restore {
+		return pasteRestoring(text, o.cp.chord)
+	}
+	return pasteClipboard(text, o.cp.chord)
+}
+
+// Backspace presses Delete n times in the focused application, to delete
+// text that was pasted.
+func (osascript) Backspace(n int) error {
+	script := fmt.Sprintf(`tell application "System Events"
+	repeat %d times
+		key code 51
+	end repeat
+end tell`, n)
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil { //nolint:gosec // script built from an integer count
+		return fmt.Errorf("osascript key code 51: %w (grant Accessibility permissions in System Settings > Privacy & Security)", err)
+	}
+	return nil
+}
 
-	return pasteClipboard(text)
+// FocusedWindow returns the process id of the frontmost application.
+func FocusedWindow() (string, error) {
+	script := `tell application "System Events" to get unix id of first application process whose frontmost is true`
+	out, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return "", fmt.Errorf("osascript frontmost process: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ActivateWindow brings the application returned by FocusedWindow to the
+// front.
+func ActivateWindow(id string) error {
+	pid, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid process id %q", id)
+	}
+	script := fmt.Sprintf(`tell application "System Events" to set frontmost of first application process whose unix id is %d to true`, pid)
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil { //nolint:gosec // script built from an integer pid
+		return fmt.Errorf("osascript activate: %w", err)
+	}
+	return nil
+}
+
+// CopyText places text on the macOS clipboard without pasting it.
+func CopyText(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pbcopy: %w", err)
+	}
+	return nil
 }
 
 // pasteClipboard writes text to the macOS clipboard via pbcopy,
-// then simulates Cmd+V via osascript.
-func pasteClipboard(text string) error {
+// then simulates the paste chord via osascript.
+func pasteClipboard(text string, c chord) error {
 	// Write to clipboard via pbcopy
 	cmd := exec.Command("pbcopy")
 	cmd.Stdin = strings.NewReader(text)
@@ -34,10 +107,9 @@ func pasteClipboard(text string) error {
 		return fmt.Errorf("pbcopy: %w", err)
 	}
 
-	// Simulate Cmd+V
-	script := `tell application "System Events" to keystroke "v" using command down`
-	if err := exec.Command("osascript", "-e", script).Run(); err != nil {
-		return fmt.Errorf("osascript Cmd+V: %w (grant Accessibility permissions in System Settings > Privacy & Security)", err)
+	script := `tell application "System Events" to ` + appleScriptChord(c)
+	if err := exec.Command("osascript", "-e", script).Run(); err != nil { //nolint:gosec // script built from a validated chord
+		return fmt.Errorf("osascript %s: %w (grant Accessibility permissions in System Settings > Privacy & Security)", c, err)
 	}
 
 	// Clear clipboard after a short delay (best-effort).
@@ -51,6 +123,61 @@ func pasteClipboard(text string) error {
 	return nil
 }
 
+// pasteRestoring pastes text from the clipboard and then puts back what
+// was there before. AppleScript keeps the saved contents in their original
+// form, so images and rich text survive. The text is read from a temp
+// file rather than embedded in the script.
+func pasteRestoring(text string, c chord) error {
+	f, err := os.CreateTemp("", "palaver-paste-*.txt")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	script := fmt.Sprintf(`on run argv
+	set saved to missing value
+	try
+		set saved to the clipboard
+	end try
+	set the clipboard to (read POSIX file (item 1 of argv) as «class utf8»)
+	tell application "System Events" to %s
+	delay %.1f
+	if saved is missing value then
+		set the clipboard to ""
+	else
+		set the clipboard to saved
+	end if
+end run`, appleScriptChord(c), restoreDelay.Seconds())
+	if err := exec.Command("osascript", "-e", script, f.Name()).Run(); err != nil { //nolint:gosec // script built from a validated chord
+		return fmt.Errorf("osascript %s: %w (grant Accessibility permissions in System Settings > Privacy & Security)", c, err)
+	}
+	return nil
+}
+
+// appleScriptChord returns the System Events command that presses c.
+func appleScriptChord(c chord) string {
+	names := map[string]string{"ctrl": "control down", "shift": "shift down", "alt": "option down", "super": "command down"}
+	var mods []string
+	for _, m := range c.mods {
+		mods = append(mods, names[m])
+	}
+	press := fmt.Sprintf("keystroke %q", c.key)
+	if c.key == "insert" {
+		press = "key code 114" // Help, where Insert is on PC keyboards
+	}
+	if len(mods) == 0 {
+		return press
+	}
+	return press + " using {" + strings.Join(mods, ", ") + "}"
+}
+
 // typeAppleScript types text directly using osascript keystroke.
 func typeAppleScript(text string) error {
 	escaped := escapeAppleScript(text)
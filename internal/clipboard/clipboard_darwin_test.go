@@ -36,3 +36,24 @@ func TestEscapeAppleScript(t *testing.T) {
 func TestPasteTextRequiresAccessibility(t *testing.T) {
 	t.Skip("requires Accessibility permissions in System Settings > Privacy & Security")
 }
+
+func TestAppleScriptChord(t *testing.T) {
+	tests := []struct {
+		chord string
+		want  string
+	}{
+		{"cmd+v", `keystroke "v" using {command down}`},
+		{"cmd+shift+v", `keystroke "v" using {command down, shift down}`},
+		{"v", `keystroke "v"`},
+		{"shift+insert", `key code 114 using {shift down}`},
+	}
+	for _, tt := range tests {
+		c, err := parseChord(tt.chord)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := appleScriptChord(c); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.chord, tt.want, got)
+		}
+	}
+}
@@ -7,10 +7,16 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	atclip "github.com/atotto/clipboard"
+	evdev "github.com/holoplot/go-evdev"
+
+	"github.com/Danondso/palaver/internal/config"
 )
 
 // isWayland returns true if the session is running under Wayland.
@@ -18,26 +24,261 @@ func isWayland() bool {
 	return os.Getenv("WAYLAND_DISPLAY") != ""
 }
 
-// PasteText inserts text into the currently focused application.
-// mode "type" uses direct typing (ydotool type / xdotool type) which works in all apps.
-// mode "clipboard" uses clipboard + Ctrl+V (legacy behavior).
-func PasteText(text string, delayMs int, mode string) error {
-	if delayMs > 0 {
-		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+// defaultChord is the paste shortcut when paste.chord is not set.
+const defaultChord = "ctrl+v"
+
+func init() {
+	register(registration{name: "xdotool", auto: true, probe: probeXdotool, new: keyboardBackend(func(cp clipboardPaste) Paster { return xdotool{cp} })})
+	register(registration{name: "ydotool", auto: true, probe: probeYdotool, new: keyboardBackend(func(cp clipboardPaste) Paster { return ydotool{cp} })})
+	register(registration{name: "wtype", auto: true, probe: probeWtype, new: keyboardBackend(func(cp clipboardPaste) Paster { return wtype{cp} })})
+	register(registration{name: "uinput", auto: true, probe: probeUinput, new: keyboardBackend(func(cp clipboardPaste) Paster { return uinput{cp} })})
+}
+
+// defaultBackend is used when auto-detection finds nothing that works, so
+// paste errors explain what to install.
+func defaultBackend() string {
+	if isWayland() {
+		return "ydotool"
 	}
+	return "xdotool"
+}
+
+// xdotool types and presses keys in X11 sessions.
+type xdotool struct {
+	cp clipboardPaste
+}
 
+func probeXdotool(*config.PasteConfig) error {
+	if isWayland() {
+		return fmt.Errorf("cannot type into Wayland windows")
+	}
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return fmt.Errorf("xdotool not found (install with: apt install xdotool)")
+	}
+	return nil
+}
+
+func (x xdotool) Paste(text, mode string) error {
 	if mode == "type" {
-		if isWayland() {
-			return typeWaylandDirect(text)
-		}
 		return typeX11Direct(text)
 	}
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return fmt.Errorf("xdotool not found: %w (install with: apt install xdotool)", err)
+	}
+	return pasteFromClipboard(text, x.cp, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		// --clearmodifiers releases a still-held hotkey modifier first.
+		if err := exec.CommandContext(ctx, "xdotool", "key", "--clearmodifiers", xdotoolChord(x.cp.chord)).Run(); err != nil {
+			return fmt.Errorf("xdotool key %s: %w", x.cp.chord, err)
+		}
+		return nil
+	})
+}
+
+// xdotoolChord spells c as an xdotool key sequence, e.g. "ctrl+shift+v".
+func xdotoolChord(c chord) string {
+	key := c.key
+	if key == "insert" {
+		key = "Insert"
+	}
+	return strings.Join(append(slices.Clone(c.mods), key), "+")
+}
+
+func (xdotool) Backspace(n int) error {
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return fmt.Errorf("xdotool not found: %w (install with: apt install xdotool)", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "xdotool", "key", "--delay", "0", "--repeat", strconv.Itoa(n), "BackSpace")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("xdotool key BackSpace: %w", err)
+	}
+	return nil
+}
+
+// ydotool types through ydotoold and /dev/uinput, which works with every
+// compositor.
+type ydotool struct {
+	cp clipboardPaste
+}
+
+func probeYdotool(*config.PasteConfig) error {
+	if _, err := exec.LookPath("ydotool"); err != nil {
+		return fmt.Errorf("ydotool not found (install with: apt install ydotool)")
+	}
+	return nil
+}
+
+func (y ydotool) Paste(text, mode string) error {
+	if mode == "type" {
+		return typeWaylandDirect(text)
+	}
+	if _, err := exec.LookPath("ydotool"); err != nil {
+		return fmt.Errorf("ydotool not found: %w (install with: apt install ydotool)", err)
+	}
+	ensureYdotoold()
+	return pasteFromClipboard(text, y.cp, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := exec.CommandContext(ctx, "ydotool", ydotoolChordArgs(y.cp.chord)...).Run(); err != nil {
+			return fmt.Errorf("ydotool key %s: %w", y.cp.chord, err)
+		}
+		return nil
+	})
+}
+
+func (ydotool) Backspace(n int) error {
+	if _, err := exec.LookPath("ydotool"); err != nil {
+		return fmt.Errorf("ydotool not found: %w (install with: apt install ydotool)", err)
+	}
+	ensureYdotoold()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := exec.CommandContext(ctx, "ydotool", ydotoolBackspaceArgs(n)...).Run(); err != nil {
+		return fmt.Errorf("ydotool key BackSpace: %w", err)
+	}
+	return nil
+}
+
+// ydotoolChordArgs returns the ydotool arguments that press c. ydotool 1.0
+// takes raw key codes: 29:1 47:1 47:0 29:0 is ctrl+v.
+func ydotoolChordArgs(c chord) []string {
+	mods, key := chordCodes(c)
+	args := []string{"key", "--delay", "0"}
+	for _, m := range mods {
+		args = append(args, fmt.Sprintf("%d:1", m))
+	}
+	args = append(args, fmt.Sprintf("%d:1", key), fmt.Sprintf("%d:0", key))
+	for _, m := range slices.Backward(mods) {
+		args = append(args, fmt.Sprintf("%d:0", m))
+	}
+	return args
+}
+
+// ydotoolBackspaceArgs returns the ydotool arguments that press BackSpace
+// n times, as raw key codes like ydotoolChordArgs.
+func ydotoolBackspaceArgs(n int) []string {
+	args := []string{"key", "--delay", "0"}
+	for range n {
+		args = append(args, fmt.Sprintf("%d:1", evdev.KEY_BACKSPACE), fmt.Sprintf("%d:0", evdev.KEY_BACKSPACE))
+	}
+	return args
+}
+
+// wtype types through the Wayland virtual-keyboard protocol, which
+// wlroots-based compositors such as Sway and Hyprland support. It handles
+// any Unicode text without /dev/uinput access.
+type wtype struct {
+	cp clipboardPaste
+}
+
+func probeWtype(*config.PasteConfig) error {
+	if !isWayland() {
+		return fmt.Errorf("needs a Wayland session")
+	}
+	if _, err := exec.LookPath("wtype"); err != nil {
+		return fmt.Errorf("wtype not found (install with: apt install wtype)")
+	}
+	return nil
+}
 
-	// clipboard mode (legacy)
+func (w wtype) Paste(text, mode string) error {
+	if mode == "type" {
+		return w.run("--", text)
+	}
+	return pasteFromClipboard(text, w.cp, func() error {
+		return w.run(wtypeChord(w.cp.chord)...)
+	})
+}
+
+// wtypeChord returns the wtype arguments that press c.
+func wtypeChord(c chord) []string {
+	var press, release []string
+	for _, m := range c.mods {
+		if m == "super" {
+			m = "logo"
+		}
+		press = append(press, "-M", m)
+		release = append([]string{"-m", m}, release...)
+	}
+	if c.key == "insert" {
+		press = append(press, "-k", "Insert")
+	} else {
+		press = append(press, c.key)
+	}
+	return append(press, release...)
+}
+
+func (w wtype) Backspace(n int) error {
+	args := make([]string, 0, 2*n)
+	for range n {
+		args = append(args, "-k", "BackSpace")
+	}
+	return w.run(args...)
+}
+
+func (wtype) run(args ...string) error {
+	if _, err := exec.LookPath("wtype"); err != nil {
+		return fmt.Errorf("wtype not found: %w (install with: apt install wtype)", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "wtype", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("wtype: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// FocusedWindow returns the X11 id of the focused window. It returns ""
+// under Wayland, where the focused window cannot be detected.
+func FocusedWindow() (string, error) {
 	if isWayland() {
-		return typeWayland(text)
+		return "", nil
 	}
-	return pasteX11(text)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "xdotool", "getactivewindow").Output()
+	if err != nil {
+		return "", fmt.Errorf("xdotool getactivewindow: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ActivateWindow focuses the window returned by FocusedWindow.
+func ActivateWindow(id string) error {
+	if isWayland() {
+		return fmt.Errorf("cannot focus windows under Wayland")
+	}
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return fmt.Errorf("invalid window id %q", id)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := exec.CommandContext(ctx, "xdotool", "windowactivate", "--sync", id).Run(); err != nil {
+		return fmt.Errorf("xdotool windowactivate: %w", err)
+	}
+	return nil
+}
+
+// CopyText places text on the system clipboard without pasting it.
+func CopyText(text string) error {
+	if isWayland() {
+		if _, err := exec.LookPath("wl-copy"); err != nil {
+			return fmt.Errorf("wl-copy not found: %w (install with: apt install wl-clipboard)", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := exec.CommandContext(ctx, "wl-copy", "--", text).Run(); err != nil {
+			return fmt.Errorf("wl-copy: %w", err)
+		}
+		return nil
+	}
+	if err := atclip.WriteAll(text); err != nil {
+		return fmt.Errorf("write to clipboard: %w", err)
+	}
+	return nil
 }
 
 // ensureYdotoold starts ydotoold in the background if it's not already running.
@@ -88,55 +329,3 @@ func typeX11Direct(text string) error {
 	}
 	return nil
 }
-
-func typeWayland(text string) error {
-	// Use wl-copy to set the Wayland clipboard, then ydotool to press Ctrl+V.
-	// ydotool works via /dev/uinput (kernel-level) which works on all compositors.
-	if _, err := exec.LookPath("wl-copy"); err != nil {
-		return fmt.Errorf("wl-copy not found: %w (install with: apt install wl-clipboard)", err)
-	}
-	if _, err := exec.LookPath("ydotool"); err != nil {
-		return fmt.Errorf("ydotool not found: %w (install with: apt install ydotool)", err)
-	}
-
-	ensureYdotoold()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "wl-copy", "--", text)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("wl-copy: %w", err)
-	}
-	cmd = exec.CommandContext(ctx, "ydotool", "key", "--delay", "0", "ctrl+v")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ydotool key ctrl+v: %w", err)
-	}
-
-	// Clear clipboard after paste (best-effort)
-	time.Sleep(100 * time.Millisecond)
-	exec.CommandContext(ctx, "wl-copy", "--clear").Run()
-
-	return nil
-}
-
-func pasteX11(text string) error {
-	if _, err := exec.LookPath("xdotool"); err != nil {
-		return fmt.Errorf("xdotool not found: %w (install with: apt install xdotool)", err)
-	}
-	if err := atclip.WriteAll(text); err != nil {
-		return fmt.Errorf("write to clipboard: %w", err)
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "xdotool", "key", "ctrl+v")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("xdotool paste: %w", err)
-	}
-
-	// Clear clipboard after paste (best-effort)
-	time.Sleep(100 * time.Millisecond)
-	atclip.WriteAll("")
-
-	return nil
-}
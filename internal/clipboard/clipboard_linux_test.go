@@ -4,7 +4,10 @@ package clipboard
 
 import (
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/holoplot/go-evdev"
 )
 
 func TestIsWayland(t *testing.T) {
@@ -30,3 +33,60 @@ func TestIsWaylandDetection(t *testing.T) {
 func TestPasteTextRequiresDisplay(t *testing.T) {
 	t.Log("clipboard.PasteText requires a display server for full testing")
 }
+
+func TestPickType(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+		want  string
+	}{
+		{"wayland text and html", []string{"text/html", "text/plain;charset=utf-8", "text/plain"}, "text/plain;charset=utf-8"},
+		{"x11 targets", []string{"TARGETS", "TIMESTAMP", "MULTIPLE", "UTF8_STRING", "STRING"}, "UTF8_STRING"},
+		{"image only", []string{"TARGETS", "image/png", "image/bmp"}, "image/png"},
+		{"nothing usable", []string{"TARGETS", "TIMESTAMP"}, ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickType(tt.types); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestChordSpellings(t *testing.T) {
+	c, err := parseChord("ctrl+shift+v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := xdotoolChord(c); got != "ctrl+shift+v" {
+		t.Errorf("unexpected xdotool chord %q", got)
+	}
+	if got := strings.Join(wtypeChord(c), " "); got != "-M ctrl -M shift v -m shift -m ctrl" {
+		t.Errorf("unexpected wtype arguments %q", got)
+	}
+	ins, err := parseChord("super+insert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := xdotoolChord(ins); got != "super+Insert" {
+		t.Errorf("unexpected xdotool chord %q", got)
+	}
+	if got := strings.Join(wtypeChord(ins), " "); got != "-M logo -k Insert -m logo" {
+		t.Errorf("unexpected wtype arguments %q", got)
+	}
+	mods, key := chordCodes(ins)
+	if len(mods) != 1 || mods[0] != evdev.KEY_LEFTMETA || key != evdev.KEY_INSERT {
+		t.Errorf("unexpected key codes %v %v", mods, key)
+	}
+	if got := strings.Join(ydotoolChordArgs(c), " "); got != "key --delay 0 29:1 42:1 47:1 47:0 42:0 29:0" {
+		t.Errorf("unexpected ydotool arguments %q", got)
+	}
+}
+
+func TestYdotoolBackspaceArgs(t *testing.T) {
+	if got := strings.Join(ydotoolBackspaceArgs(2), " "); got != "key --delay 0 14:1 14:0 14:1 14:0" {
+		t.Errorf("unexpected ydotool arguments %q", got)
+	}
+}
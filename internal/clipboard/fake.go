@@ -0,0 +1,49 @@
+package clipboard
+
+import "sync"
+
+// Fake is a Paster that records calls instead of sending keystrokes. It is
+// meant for tests.
+type Fake struct {
+	mu     sync.Mutex
+	pasted []string
+	erased []int
+	// Err, if set, is returned by every call.
+	Err error
+}
+
+// Paste records text.
+func (f *Fake) Paste(text, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.pasted = append(f.pasted, text)
+	return nil
+}
+
+// Backspace records n.
+func (f *Fake) Backspace(n int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.erased = append(f.erased, n)
+	return nil
+}
+
+// Pasted returns the texts pasted so far.
+func (f *Fake) Pasted() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.pasted...)
+}
+
+// Erased returns the Backspace counts so far.
+func (f *Fake) Erased() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.erased...)
+}
@@ -0,0 +1,128 @@
+// Package clipboard delivers transcripts to the focused application, by
+// typing them or pasting them from the clipboard.
+package clipboard
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// Paster delivers text to the focused application.
+type Paster interface {
+	// Paste inserts text. mode is "type" (keystrokes) or "clipboard"
+	// (clipboard and paste shortcut); backends with one way of inserting
+	// text ignore it.
+	Paste(text, mode string) error
+	// Backspace deletes the n characters before the cursor, or returns
+	// ErrUnsupported.
+	Backspace(n int) error
+}
+
+// LineWriter is implemented by backends that put each transcript on its
+// own line instead of typing it after the last one, such as stdout.
+type LineWriter interface {
+	// WritesLines reports whether transcripts go on their own lines, so
+	// they need no space between them.
+	WritesLines() bool
+}
+
+// ErrUnsupported is returned by Paster.Backspace for backends that cannot
+// delete text, such as stdout.
+var ErrUnsupported = errors.New("not supported by this paste backend")
+
+// registration is a backend known to New.
+type registration struct {
+	name string
+	// auto backends are considered by auto-detection, in registration
+	// order.
+	auto bool
+	// probe reports why the backend cannot work in this session, or nil.
+	probe func(cfg *config.PasteConfig) error
+	new   func(cfg *config.PasteConfig) (Paster, error)
+}
+
+// backends holds the registered backends. Platform files register theirs
+// in init; auto-detection prefers earlier ones.
+var backends []registration
+
+func register(r registration) {
+	backends = append(backends, r)
+}
+
+// Backends returns the names of the backends available on this platform,
+// in auto-detection order.
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for _, r := range backends {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// Backend is the paste backend chosen by New.
+type Backend struct {
+	Paster
+	Name string
+	// Err is why the backend failed its startup probe, or nil. Pasting is
+	// still attempted and reports the problem in more detail.
+	Err error
+}
+
+// WritesLines reports whether the backend is a LineWriter that puts each
+// transcript on its own line.
+func (b *Backend) WritesLines() bool {
+	lw, ok := b.Paster.(LineWriter)
+	return ok && lw.WritesLines()
+}
+
+// New creates the backend named by cfg.Backend. "auto" picks the first
+// backend whose probe passes, or the platform's default if none does.
+func New(cfg *config.PasteConfig) (*Backend, error) {
+	name := cmp.Or(cfg.Backend, "auto")
+	if name == "auto" {
+		return detect(cfg)
+	}
+	i := slices.IndexFunc(backends, func(r registration) bool { return r.name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("unknown paste backend %q (available: auto, %s)", name, strings.Join(Backends(), ", "))
+	}
+	return newBackend(backends[i], cfg)
+}
+
+// detect implements auto-detection for New.
+func detect(cfg *config.PasteConfig) (*Backend, error) {
+	var failures []string
+	for _, r := range backends {
+		if !r.auto {
+			continue
+		}
+		err := r.probe(cfg)
+		if err == nil {
+			return newBackend(r, cfg)
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", r.name, err))
+	}
+	i := slices.IndexFunc(backends, func(r registration) bool { return r.name == defaultBackend() })
+	if i < 0 {
+		return nil, errors.New("no paste backend available")
+	}
+	b, err := newBackend(backends[i], cfg)
+	if err != nil {
+		return nil, err
+	}
+	b.Err = fmt.Errorf("no paste backend available (%s)", strings.Join(failures, "; "))
+	return b, nil
+}
+
+func newBackend(r registration, cfg *config.PasteConfig) (*Backend, error) {
+	p, err := r.new(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("paste backend %s: %w", r.name, err)
+	}
+	return &Backend{Paster: p, Name: r.name, Err: r.probe(cfg)}, nil
+}
@@ -0,0 +1,148 @@
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// withBackends replaces the registry for the duration of a test.
+func withBackends(t *testing.T, regs ...registration) {
+	t.Helper()
+	orig := backends
+	backends = regs
+	t.Cleanup(func() { backends = orig })
+}
+
+func fakeRegistration(name string, auto bool, probeErr error) registration {
+	return registration{
+		name:  name,
+		auto:  auto,
+		probe: func(*config.PasteConfig) error { return probeErr },
+		new:   func(*config.PasteConfig) (Paster, error) { return &Fake{}, nil },
+	}
+}
+
+func TestNewDetectsBackend(t *testing.T) {
+	missing := errors.New("not installed")
+	tests := []struct {
+		name    string
+		regs    []registration
+		want    string
+		wantErr string // substring of Backend.Err
+	}{
+		{
+			name: "first working auto backend",
+			regs: []registration{
+				fakeRegistration("broken", true, missing),
+				fakeRegistration("manual", false, nil),
+				fakeRegistration("works", true, nil),
+				fakeRegistration("also-works", true, nil),
+			},
+			want: "works",
+		},
+		{
+			name: "platform default when nothing works",
+			regs: []registration{
+				fakeRegistration("broken", true, missing),
+				fakeRegistration(defaultBackend(), true, missing),
+			},
+			want:    defaultBackend(),
+			wantErr: "broken: not installed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withBackends(t, tt.regs...)
+			b, err := New(&config.PasteConfig{Backend: "auto"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Name != tt.want {
+				t.Errorf("expected backend %s, got %s", tt.want, b.Name)
+			}
+			if tt.wantErr == "" && b.Err != nil {
+				t.Errorf("expected a working backend, got %v", b.Err)
+			}
+			if tt.wantErr != "" && (b.Err == nil || !strings.Contains(b.Err.Error(), tt.wantErr)) {
+				t.Errorf("expected probe error containing %q, got %v", tt.wantErr, b.Err)
+			}
+		})
+	}
+}
+
+func TestNewByName(t *testing.T) {
+	withBackends(t, fakeRegistration("works", true, nil), fakeRegistration("manual", false, errors.New("no pane")))
+	b, err := New(&config.PasteConfig{Backend: "manual"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Name != "manual" || b.Err == nil {
+		t.Errorf("expected the named backend with its probe error, got %s, %v", b.Name, b.Err)
+	}
+	if b.WritesLines() {
+		t.Error("expected a keyboard backend not to write lines")
+	}
+	if _, err := New(&config.PasteConfig{Backend: "xdotoolz"}); err == nil || !strings.Contains(err.Error(), "available: auto, works, manual") {
+		t.Errorf("expected an unknown backend error listing the backends, got %v", err)
+	}
+}
+
+func TestFileBackend(t *testing.T) {
+	if _, err := New(&config.PasteConfig{Backend: "file"}); err == nil {
+		t.Error("expected an error without paste.file")
+	}
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	b, err := New(&config.PasteConfig{Backend: "file", File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"first", "second"} {
+		if err := b.Paste(text, "type"); err != nil {
+			t.Fatalf("Paste: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("unexpected file contents %q", data)
+	}
+	if err := b.Backspace(3); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if !b.WritesLines() {
+		t.Error("expected the file backend to write lines")
+	}
+}
+
+func TestCommandBackend(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.txt")
+	b, err := New(&config.PasteConfig{Backend: "command", Command: `{ cat; echo " $PALAVER_PASTE_MODE"; } > "` + out + `"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Paste("hello; rm -rf $HOME", "clipboard"); err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello; rm -rf $HOME clipboard\n" {
+		t.Errorf("expected the text on stdin and the mode in the environment, got %q", data)
+	}
+
+	b, err = New(&config.PasteConfig{Backend: "command", Command: "echo nope >&2; exit 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Paste("x", "type"); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected the command's stderr in the error, got %v", err)
+	}
+}
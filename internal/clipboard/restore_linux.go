@@ -0,0 +1,122 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	atclip "github.com/atotto/clipboard"
+)
+
+// textTypes are the clipboard types that hold plain text, most preferred
+// first. Wayland uses MIME types and X11 uses targets such as UTF8_STRING.
+var textTypes = []string{"text/plain;charset=utf-8", "UTF8_STRING", "text/plain", "STRING", "TEXT"}
+
+// snapshot is the clipboard's contents in a single type.
+type snapshot struct {
+	typ  string
+	data []byte
+}
+
+// pasteFromClipboard puts text on the clipboard, calls press to send the
+// paste shortcut, and then puts back what was on the clipboard before, or
+// clears it.
+func pasteFromClipboard(text string, cp clipboardPaste, press func() error) error {
+	var saved *snapshot
+	if cp.restore {
+		saved = saveClipboard()
+	}
+	if err := CopyText(text); err != nil {
+		return err
+	}
+	err := press()
+
+	// Give the application time to read the clipboard before it changes.
+	time.Sleep(restoreDelay)
+	if saved == nil || saved.restore() != nil {
+		clearClipboard()
+	}
+	return err
+}
+
+// saveClipboard returns the clipboard's contents, or nil if it is empty or
+// cannot be read. Plain text is kept if the clipboard has it; otherwise
+// the first type offered, such as image/png.
+func saveClipboard() *snapshot {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var list, read []string
+	if isWayland() {
+		list = []string{"wl-paste", "--list-types"}
+		read = []string{"wl-paste", "--no-newline", "--type"}
+	} else {
+		// atotto/clipboard cannot list or read other types, so this needs
+		// xclip.
+		list = []string{"xclip", "-selection", "clipboard", "-o", "-t", "TARGETS"}
+		read = []string{"xclip", "-selection", "clipboard", "-o", "-t"}
+	}
+	out, err := exec.CommandContext(ctx, list[0], list[1:]...).Output() //nolint:gosec // fixed commands
+	if err != nil {
+		return nil
+	}
+	typ := pickType(strings.Fields(string(out)))
+	if typ == "" {
+		return nil
+	}
+	args := append(slices.Clone(read[1:]), typ)
+	data, err := exec.CommandContext(ctx, read[0], args...).Output() //nolint:gosec // type comes from the clipboard's own list
+	if err != nil {
+		return nil
+	}
+	return &snapshot{typ: typ, data: data}
+}
+
+// pickType chooses which of the offered clipboard types to keep.
+func pickType(types []string) string {
+	for _, t := range textTypes {
+		if slices.Contains(types, t) {
+			return t
+		}
+	}
+	// Skip X11 bookkeeping targets such as TARGETS and TIMESTAMP.
+	for _, t := range types {
+		if strings.Contains(t, "/") {
+			return t
+		}
+	}
+	return ""
+}
+
+// restore puts the snapshot back on the clipboard. wl-copy and xclip fork
+// and keep serving it after they return.
+func (s *snapshot) restore() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var cmd *exec.Cmd
+	if isWayland() {
+		cmd = exec.CommandContext(ctx, "wl-copy", "--type", s.typ) //nolint:gosec // type comes from the clipboard's own list
+	} else {
+		cmd = exec.CommandContext(ctx, "xclip", "-selection", "clipboard", "-i", "-t", s.typ) //nolint:gosec // type comes from the clipboard's own list
+	}
+	cmd.Stdin = bytes.NewReader(s.data)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("restore clipboard: %w", err)
+	}
+	return nil
+}
+
+// clearClipboard empties the clipboard (best-effort).
+func clearClipboard() {
+	if isWayland() {
+		_ = exec.Command("wl-copy", "--clear").Run()
+		return
+	}
+	_ = atclip.WriteAll("")
+}
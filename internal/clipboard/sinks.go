@@ -0,0 +1,137 @@
+package clipboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// Backends that send text somewhere other than the focused window. They
+// are only used when selected by name.
+func init() {
+	register(registration{name: "tmux", probe: probeTmux, new: func(cfg *config.PasteConfig) (Paster, error) {
+		return tmux{target: cfg.TmuxTarget}, nil
+	}})
+	register(registration{name: "stdout", probe: func(*config.PasteConfig) error { return nil }, new: func(*config.PasteConfig) (Paster, error) {
+		return NewWriter(os.Stdout), nil
+	}})
+	register(registration{name: "file", probe: func(*config.PasteConfig) error { return nil }, new: func(cfg *config.PasteConfig) (Paster, error) {
+		if cfg.File == "" {
+			return nil, fmt.Errorf("paste.file is not set")
+		}
+		return filePaster{path: cfg.File}, nil
+	}})
+	register(registration{name: "command", probe: func(*config.PasteConfig) error { return nil }, new: func(cfg *config.PasteConfig) (Paster, error) {
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("paste.command is not set")
+		}
+		return commandPaster{command: cfg.Command}, nil
+	}})
+}
+
+// tmux types into a tmux pane with send-keys: tmux_target, or the pane
+// Palaver runs in.
+type tmux struct {
+	target string
+}
+
+func probeTmux(cfg *config.PasteConfig) error {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found")
+	}
+	if cfg.TmuxTarget == "" && os.Getenv("TMUX") == "" {
+		return fmt.Errorf("not inside tmux and paste.tmux_target is not set")
+	}
+	return nil
+}
+
+func (t tmux) Paste(text, _ string) error {
+	return t.sendKeys("-l", "--", text)
+}
+
+func (t tmux) Backspace(n int) error {
+	return t.sendKeys("-N", strconv.Itoa(n), "BSpace")
+}
+
+func (t tmux) sendKeys(args ...string) error {
+	if t.target != "" {
+		args = append([]string{"-t", t.target}, args...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "tmux", append([]string{"send-keys"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tmux send-keys: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// writerPaster writes each transcript to w on its own line, for piping
+// palaver daemon into other programs.
+type writerPaster struct {
+	w io.Writer
+}
+
+// NewWriter returns the stdout backend writing to w instead.
+func NewWriter(w io.Writer) Paster {
+	return writerPaster{w: w}
+}
+
+func (writerPaster) WritesLines() bool { return true }
+
+func (p writerPaster) Paste(text, _ string) error {
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
+
+func (writerPaster) Backspace(int) error { return ErrUnsupported }
+
+// filePaster appends each transcript to a file on its own line.
+type filePaster struct {
+	path string
+}
+
+func (p filePaster) Paste(text, _ string) error {
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // path is from the user's config
+	if err != nil {
+		return fmt.Errorf("open paste file: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, text); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write paste file: %w", err)
+	}
+	return f.Close()
+}
+
+func (filePaster) Backspace(int) error { return ErrUnsupported }
+
+func (filePaster) WritesLines() bool { return true }
+
+// commandPaster runs a shell command with the transcript on stdin. The
+// paste mode is in $PALAVER_PASTE_MODE.
+type commandPaster struct {
+	command string
+}
+
+func (p commandPaster) Paste(text, mode string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", p.command) //nolint:gosec // user-configured command, intended behavior
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), "PALAVER_PASTE_MODE="+mode)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("paste command: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (commandPaster) Backspace(int) error { return ErrUnsupported }
+
+func (commandPaster) WritesLines() bool { return true }
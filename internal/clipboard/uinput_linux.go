@@ -0,0 +1,286 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/holoplot/go-evdev"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+const (
+	uinputPath = "/dev/uinput"
+	// uinputSettle gives the compositor time to pick up the virtual
+	// keyboard before the first key is sent.
+	uinputSettle = 200 * time.Millisecond
+	// uinputKeyDelay paces keystrokes so that no application drops them.
+	uinputKeyDelay = time.Millisecond
+)
+
+// uinput types through Palaver's own virtual keyboard on /dev/uinput.
+type uinput struct {
+	cp clipboardPaste
+}
+
+func probeUinput(*config.PasteConfig) error {
+	if err := syscall.Access(uinputPath, 2); err != nil { // 2 = W_OK
+		return fmt.Errorf("no write access to %s (see README: Native Wayland Typing)", uinputPath)
+	}
+	return nil
+}
+
+func (u uinput) Paste(text, mode string) error {
+	if mode == "type" {
+		return u.typeText(text)
+	}
+	return u.paste(text)
+}
+
+// keyWriter is the part of *evdev.InputDevice used to send key events.
+type keyWriter interface {
+	WriteOne(event *evdev.InputEvent) error
+}
+
+// keystroke is a key press, optionally with Shift held.
+type keystroke struct {
+	code  evdev.EvCode
+	shift bool
+}
+
+// usKeymap maps the characters a US keyboard can type to their keys.
+var usKeymap = func() map[rune]keystroke {
+	m := map[rune]keystroke{
+		' ':  {code: evdev.KEY_SPACE},
+		'\n': {code: evdev.KEY_ENTER},
+		'\t': {code: evdev.KEY_TAB},
+	}
+	letters := []evdev.EvCode{
+		evdev.KEY_A, evdev.KEY_B, evdev.KEY_C, evdev.KEY_D, evdev.KEY_E, evdev.KEY_F, evdev.KEY_G,
+		evdev.KEY_H, evdev.KEY_I, evdev.KEY_J, evdev.KEY_K, evdev.KEY_L, evdev.KEY_M, evdev.KEY_N,
+		evdev.KEY_O, evdev.KEY_P, evdev.KEY_Q, evdev.KEY_R, evdev.KEY_S, evdev.KEY_T, evdev.KEY_U,
+		evdev.KEY_V, evdev.KEY_W, evdev.KEY_X, evdev.KEY_Y, evdev.KEY_Z,
+	}
+	for i, code := range letters {
+		m['a'+rune(i)] = keystroke{code: code}
+		m['A'+rune(i)] = keystroke{code: code, shift: true}
+	}
+	// Each key's plain and shifted character.
+	keys := []struct {
+		plain, shifted rune
+		code           evdev.EvCode
+	}{
+		{'1', '!', evdev.KEY_1}, {'2', '@', evdev.KEY_2}, {'3', '#', evdev.KEY_3},
+		{'4', '$', evdev.KEY_4}, {'5', '%', evdev.KEY_5}, {'6', '^', evdev.KEY_6},
+		{'7', '&', evdev.KEY_7}, {'8', '*', evdev.KEY_8}, {'9', '(', evdev.KEY_9},
+		{'0', ')', evdev.KEY_0}, {'-', '_', evdev.KEY_MINUS}, {'=', '+', evdev.KEY_EQUAL},
+		{'[', '{', evdev.KEY_LEFTBRACE}, {']', '}', evdev.KEY_RIGHTBRACE},
+		{'\\', '|', evdev.KEY_BACKSLASH}, {';', ':', evdev.KEY_SEMICOLON},
+		{'\'', '"', evdev.KEY_APOSTROPHE}, {'`', '~', evdev.KEY_GRAVE},
+		{',', '<', evdev.KEY_COMMA}, {'.', '>', evdev.KEY_DOT}, {'/', '?', evdev.KEY_SLASH},
+	}
+	for _, k := range keys {
+		m[k.plain] = keystroke{code: k.code}
+		m[k.shifted] = keystroke{code: k.code, shift: true}
+	}
+	return m
+}()
+
+// keystrokes returns the key presses that type text on a US layout. It
+// returns false if text contains a character the layout cannot type.
+func keystrokes(text string) ([]keystroke, bool) {
+	strokes := make([]keystroke, 0, len(text))
+	for _, r := range text {
+		k, ok := usKeymap[r]
+		if !ok {
+			return nil, false
+		}
+		strokes = append(strokes, k)
+	}
+	return strokes, true
+}
+
+var (
+	uinputMu  sync.Mutex
+	uinputDev keyWriter // created on first use and kept for the process lifetime
+)
+
+// virtualKeyboard returns the virtual keyboard, creating it through
+// /dev/uinput on first use. Must be called with uinputMu held.
+func virtualKeyboard() (keyWriter, error) {
+	if uinputDev != nil {
+		return uinputDev, nil
+	}
+	codes := []evdev.EvCode{evdev.KEY_BACKSPACE, evdev.KEY_INSERT}
+	for _, code := range modifierCodes {
+		codes = append(codes, code)
+	}
+	for _, k := range usKeymap {
+		codes = append(codes, k.code)
+	}
+	dev, err := evdev.CreateDevice("palaver virtual keyboard", evdev.InputID{BusType: evdev.BUS_VIRTUAL},
+		map[evdev.EvType][]evdev.EvCode{evdev.EV_KEY: codes})
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, fmt.Errorf("create uinput keyboard: %w (add a udev rule giving the input group write access to /dev/uinput)", err)
+		}
+		return nil, fmt.Errorf("create uinput keyboard: %w", err)
+	}
+	time.Sleep(uinputSettle)
+	uinputDev = dev
+	return dev, nil
+}
+
+// sendKey writes a key press (value 1) or release (value 0) followed by a
+// sync report.
+func sendKey(w keyWriter, code evdev.EvCode, value int32) error {
+	if err := w.WriteOne(&evdev.InputEvent{Type: evdev.EV_KEY, Code: code, Value: value}); err != nil {
+		return fmt.Errorf("uinput write: %w", err)
+	}
+	if err := w.WriteOne(&evdev.InputEvent{Type: evdev.EV_SYN, Code: evdev.SYN_REPORT}); err != nil {
+		return fmt.Errorf("uinput write: %w", err)
+	}
+	return nil
+}
+
+// modifierCodes maps chord modifiers to their left-hand keys.
+var modifierCodes = map[string]evdev.EvCode{
+	"ctrl":  evdev.KEY_LEFTCTRL,
+	"shift": evdev.KEY_LEFTSHIFT,
+	"alt":   evdev.KEY_LEFTALT,
+	"super": evdev.KEY_LEFTMETA,
+}
+
+// chordCodes returns the key codes of c's modifiers and key.
+func chordCodes(c chord) ([]evdev.EvCode, evdev.EvCode) {
+	mods := make([]evdev.EvCode, 0, len(c.mods))
+	for _, m := range c.mods {
+		mods = append(mods, modifierCodes[m])
+	}
+	return mods, evdev.KEYFromString["KEY_"+strings.ToUpper(c.key)]
+}
+
+// tap presses and releases each keystroke, holding mods while they are
+// pressed and Shift where a keystroke needs it.
+func tap(w keyWriter, mods []evdev.EvCode, strokes ...keystroke) error {
+	for i, m := range mods {
+		if err := sendKey(w, m, 1); err != nil {
+			return err
+		}
+		defer func() { _ = sendKey(w, mods[i], 0) }()
+	}
+	for _, k := range strokes {
+		if k.shift {
+			if err := sendKey(w, evdev.KEY_LEFTSHIFT, 1); err != nil {
+				return err
+			}
+		}
+		if err := sendKey(w, k.code, 1); err != nil {
+			return err
+		}
+		if err := sendKey(w, k.code, 0); err != nil {
+			return err
+		}
+		if k.shift {
+			if err := sendKey(w, evdev.KEY_LEFTSHIFT, 0); err != nil {
+				return err
+			}
+		}
+		time.Sleep(uinputKeyDelay)
+	}
+	return nil
+}
+
+// keyboardLayout returns the active XKB layout and variant, or "" if they
+// cannot be found. It is a variable so that tests can replace it.
+var keyboardLayout = func() (layout, variant string) {
+	if l := os.Getenv("XKB_DEFAULT_LAYOUT"); l != "" {
+		return l, os.Getenv("XKB_DEFAULT_VARIANT")
+	}
+	// Under Wayland, Xwayland reports its own layout rather than the
+	// compositor's, so setxkbmap is only asked on X11.
+	if !isWayland() {
+		if out, err := exec.Command("setxkbmap", "-query").Output(); err == nil {
+			return xkbField(string(out), "layout:"), xkbField(string(out), "variant:")
+		}
+	}
+	if out, err := exec.Command("localectl", "status").Output(); err == nil {
+		return xkbField(string(out), "X11 Layout:"), xkbField(string(out), "X11 Variant:")
+	}
+	return "", ""
+}
+
+// xkbField returns the value after key in the output of setxkbmap -query
+// or localectl status.
+func xkbField(out, key string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), key); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// usLayout reports whether the keyboard layout is known to be plain US,
+// the only one usKeymap is right for. A list of layouts such as "us,de"
+// does not count, since any of them may be active.
+func usLayout() bool {
+	layout, variant := keyboardLayout()
+	return layout == "us" && (variant == "" || variant == "basic")
+}
+
+// typeText types text with the virtual keyboard. Unless the layout is
+// known to be US, or if it cannot type the text, such as accented
+// letters, the text is pasted from the clipboard instead, which does not
+// depend on the keyboard layout.
+func (u uinput) typeText(text string) error {
+	strokes, ok := keystrokes(text)
+	if !ok || !usLayout() {
+		return u.paste(text)
+	}
+	uinputMu.Lock()
+	defer uinputMu.Unlock()
+	dev, err := virtualKeyboard()
+	if err != nil {
+		return err
+	}
+	return tap(dev, nil, strokes...)
+}
+
+// paste places text on the clipboard and presses the paste chord with the
+// virtual keyboard.
+func (u uinput) paste(text string) error {
+	return pasteFromClipboard(text, u.cp, func() error {
+		uinputMu.Lock()
+		defer uinputMu.Unlock()
+		dev, err := virtualKeyboard()
+		if err != nil {
+			return err
+		}
+		mods, key := chordCodes(u.cp.chord)
+		return tap(dev, mods, keystroke{code: key})
+	})
+}
+
+// Backspace presses BackSpace n times with the virtual keyboard.
+func (uinput) Backspace(n int) error {
+	uinputMu.Lock()
+	defer uinputMu.Unlock()
+	dev, err := virtualKeyboard()
+	if err != nil {
+		return err
+	}
+	strokes := make([]keystroke, n)
+	for i := range strokes {
+		strokes[i] = keystroke{code: evdev.KEY_BACKSPACE}
+	}
+	return tap(dev, nil, strokes...)
+}
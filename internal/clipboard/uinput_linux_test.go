@@ -0,0 +1,123 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/holoplot/go-evdev"
+)
+
+// recordingKeyboard records key events as "+KEY_A" (press) and "-KEY_A"
+// (release), skipping sync reports.
+type recordingKeyboard struct {
+	events []string
+	syncs  int
+}
+
+func (r *recordingKeyboard) WriteOne(ev *evdev.InputEvent) error {
+	if ev.Type == evdev.EV_SYN {
+		r.syncs++
+		return nil
+	}
+	sign := "-"
+	if ev.Value == 1 {
+		sign = "+"
+	}
+	r.events = append(r.events, sign+evdev.KEYNames[ev.Code])
+	return nil
+}
+
+func TestKeystrokes(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{text: "Hi!", want: "+KEY_LEFTSHIFT +KEY_H -KEY_H -KEY_LEFTSHIFT +KEY_I -KEY_I +KEY_LEFTSHIFT +KEY_1 -KEY_1 -KEY_LEFTSHIFT", ok: true},
+		{text: "a b\n", want: "+KEY_A -KEY_A +KEY_SPACE -KEY_SPACE +KEY_B -KEY_B +KEY_ENTER -KEY_ENTER", ok: true},
+		{text: `"x"`, want: "+KEY_LEFTSHIFT +KEY_APOSTROPHE -KEY_APOSTROPHE -KEY_LEFTSHIFT +KEY_X -KEY_X +KEY_LEFTSHIFT +KEY_APOSTROPHE -KEY_APOSTROPHE -KEY_LEFTSHIFT", ok: true},
+		{text: "café", ok: false},
+		{text: "„quote“", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			strokes, ok := keystrokes(tt.text)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			var kb recordingKeyboard
+			if err := tap(&kb, nil, strokes...); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(kb.events, " "); got != tt.want {
+				t.Errorf("expected %s\ngot      %s", tt.want, got)
+			}
+			if kb.syncs != len(kb.events) {
+				t.Errorf("expected a sync report after each of %d events, got %d", len(kb.events), kb.syncs)
+			}
+		})
+	}
+}
+
+func TestTapHoldsModifier(t *testing.T) {
+	var kb recordingKeyboard
+	if err := tap(&kb, []evdev.EvCode{evdev.KEY_LEFTCTRL, evdev.KEY_LEFTSHIFT}, keystroke{code: evdev.KEY_V}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(kb.events, " "); got != "+KEY_LEFTCTRL +KEY_LEFTSHIFT +KEY_V -KEY_V -KEY_LEFTSHIFT -KEY_LEFTCTRL" {
+		t.Errorf("unexpected events: %s", got)
+	}
+}
+
+func TestKeymapCoversPrintableASCII(t *testing.T) {
+	for r := rune(' '); r <= '~'; r++ {
+		if _, ok := usKeymap[r]; !ok {
+			t.Errorf("no key for %q", r)
+		}
+	}
+}
+
+func TestXkbField(t *testing.T) {
+	setxkbmap := "rules:      evdev\nmodel:      pc105\nlayout:     de\nvariant:    nodeadkeys\n"
+	localectl := "   System Locale: LANG=en_US.UTF-8\n       VC Keymap: us\n      X11 Layout: us\n       X11 Model: pc105\n"
+	tests := []struct {
+		out, key, want string
+	}{
+		{out: setxkbmap, key: "layout:", want: "de"},
+		{out: setxkbmap, key: "variant:", want: "nodeadkeys"},
+		{out: localectl, key: "X11 Layout:", want: "us"},
+		{out: localectl, key: "X11 Variant:", want: ""},
+	}
+	for _, tt := range tests {
+		if got := xkbField(tt.out, tt.key); got != tt.want {
+			t.Errorf("xkbField(%q): expected %q, got %q", tt.key, tt.want, got)
+		}
+	}
+}
+
+func TestUSLayout(t *testing.T) {
+	tests := []struct {
+		layout, variant string
+		want            bool
+	}{
+		{layout: "us", want: true},
+		{layout: "us", variant: "basic", want: true},
+		{layout: "us", variant: "dvorak", want: false},
+		{layout: "de", want: false},
+		{layout: "us,de", want: false},
+		{layout: "", want: false},
+	}
+	saved := keyboardLayout
+	defer func() { keyboardLayout = saved }()
+	for _, tt := range tests {
+		keyboardLayout = func() (string, string) { return tt.layout, tt.variant }
+		if got := usLayout(); got != tt.want {
+			t.Errorf("usLayout() with %q/%q: expected %v, got %v", tt.layout, tt.variant, tt.want, got)
+		}
+	}
+}
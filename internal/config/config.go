@@ -11,21 +11,77 @@ import (
 
 // HotkeyConfig holds hotkey-related settings.
 type HotkeyConfig struct {
+	Key             string `toml:"key"`
+	Device          string `toml:"device"`            // Linux: event node or by-id link; empty = every device with the hotkey's keys
+	Mode            string `toml:"mode"`              // "hold", "toggle", or "hybrid"
+	HoldThresholdMs int    `toml:"hold_threshold_ms"` // hybrid: presses shorter than this latch recording
+	UndoKey         string `toml:"undo_key"`          // deletes the last paste; empty = no undo hotkey
+	// Bindings are extra keys, each triggering one action.
+	Bindings []HotkeyBinding `toml:"binding"`
+}
+
+// HotkeyBinding is one [[hotkey.binding]] entry. Key uses the same names
+// as hotkey.key.
+type HotkeyBinding struct {
 	Key    string `toml:"key"`
-	Device string `toml:"device"`
+	Action string `toml:"action"` // record, record_raw, record_copy, toggle, undo, or cycle_tone
+	Tone   string `toml:"tone"`   // record, record_copy, toggle: post-processing tone for these recordings
 }
 
 // AudioConfig holds audio capture settings.
 type AudioConfig struct {
-	TargetSampleRate int    `toml:"target_sample_rate"`
-	MaxDurationSec   int    `toml:"max_duration_sec"`
-	ChimeStart       string `toml:"chime_start"`
-	ChimeStop        string `toml:"chime_stop"`
-	ChimeEnabled     bool   `toml:"chime_enabled"`
+	TargetSampleRate int     `toml:"target_sample_rate"`
+	MaxDurationSec   int     `toml:"max_duration_sec"` // 0 = unlimited
+	ChimeStart       string  `toml:"chime_start"`
+	ChimeStop        string  `toml:"chime_stop"`
+	ChimeEnabled     bool    `toml:"chime_enabled"`
+	VADEnabled       bool    `toml:"vad_enabled"`       // trim silence and drop recordings without speech
+	VADThreshold     float64 `toml:"vad_threshold"`     // RMS level (0.0–1.0) that counts as speech
+	VADPaddingMs     int     `toml:"vad_padding_ms"`    // audio kept around detected speech
+	VADMinSpeechMs   int     `toml:"vad_min_speech_ms"` // speech required to keep a recording
+	// Trigger selects what starts a recording: "hotkey" or "vad" (hands-free).
+	Trigger           string  `toml:"trigger"`
+	TriggerThreshold  float64 `toml:"trigger_threshold"`   // RMS level (0.0–1.0) that starts a segment
+	TriggerStartMs    int     `toml:"trigger_start_ms"`    // voice required before a segment starts
+	TriggerHangoverMs int     `toml:"trigger_hangover_ms"` // silence that ends a segment
+	TriggerPreRollMs  int     `toml:"trigger_pre_roll_ms"` // audio kept from before the segment started
 }
 
 // TranscriptionConfig holds transcription provider settings.
 type TranscriptionConfig struct {
+	Provider       string `toml:"provider"`
+	BaseURL        string `toml:"base_url"`
+	Model          string `toml:"model"`
+	TimeoutSec     int    `toml:"timeout_sec"`
+	Command        string `toml:"command"`
+	TLSSkipVerify  bool   `toml:"tls_skip_verify"`
+	Streaming      bool   `toml:"streaming"`        // transcribe chunks while recording
+	StreamChunkSec int    `toml:"stream_chunk_sec"` // seconds of audio per streamed chunk
+	// Language, Prompt, and Temperature are sent with each request to
+	// openai backends. An empty Language or "auto" lets the server detect
+	// it; Languages lists the choices the TUI cycles through.
+	Language    string   `toml:"language"`
+	Languages   []string `toml:"languages"`
+	Prompt      string   `toml:"prompt"`
+	Temperature *float64 `toml:"temperature"` // nil = server default
+	// Long recordings are split into chunks of at most ChunkSec seconds
+	// (0 disables splitting) and transcribed ChunkParallelism at a time.
+	ChunkSec         int `toml:"chunk_sec"`
+	ChunkOverlapMs   int `toml:"chunk_overlap_ms"`  // audio repeated across cuts made mid-speech
+	ChunkParallelism int `toml:"chunk_parallelism"` // concurrent chunk requests
+	// Detailed requests verbose_json (segments, language, confidence) from
+	// openai backends. Segments whose average log-probability is below
+	// LowConfidenceLogprob are flagged in the TUI.
+	Detailed             bool    `toml:"detailed"`
+	LowConfidenceLogprob float64 `toml:"low_confidence_logprob"`
+	// Backends, if set, are tried in order instead of the provider above.
+	Backends []TranscriptionBackend `toml:"backends"`
+}
+
+// TranscriptionBackend is one [[transcription.backends]] entry. Provider,
+// model, and timeout default to the [transcription] values.
+type TranscriptionBackend struct {
+	Name          string `toml:"name"` // shown in logs and history; default: base_url or provider
 	Provider      string `toml:"provider"`
 	BaseURL       string `toml:"base_url"`
 	Model         string `toml:"model"`
@@ -36,8 +92,14 @@ type TranscriptionConfig struct {
 
 // PasteConfig holds clipboard paste settings.
 type PasteConfig struct {
-	DelayMs int    `toml:"delay_ms"`
-	Mode    string `toml:"mode"` // "type" (direct typing) or "clipboard" (Ctrl+V)
+	DelayMs          int    `toml:"delay_ms"`
+	Mode             string `toml:"mode"`              // "type" (direct typing) or "clipboard" (Ctrl+V)
+	Chord            string `toml:"chord"`             // clipboard mode: paste shortcut, e.g. "ctrl+shift+v" for terminals
+	RestoreClipboard bool   `toml:"restore_clipboard"` // clipboard mode: put back the previous contents instead of clearing
+	Backend          string `toml:"backend"`           // "auto", or a backend name such as "wtype" or "tmux"
+	TmuxTarget       string `toml:"tmux_target"`       // tmux backend: pane to type into; empty = the current pane
+	File             string `toml:"file"`              // file backend: transcripts are appended here
+	Command          string `toml:"command"`           // command backend: run with the transcript on stdin
 }
 
 // ServerConfig holds managed backend server settings.
@@ -56,12 +118,70 @@ type PostProcessingConfig struct {
 	TimeoutSec int    `toml:"timeout_sec"`
 }
 
+// HistoryConfig holds transcription history settings.
+type HistoryConfig struct {
+	Enabled    bool   `toml:"enabled"`
+	Path       string `toml:"path"`        // empty = <data dir>/history.jsonl
+	MaxEntries int    `toml:"max_entries"` // oldest entries are dropped beyond this (0 = unlimited)
+}
+
+// ControlConfig holds local control API settings.
+type ControlConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Socket  string `toml:"socket"` // empty = $XDG_RUNTIME_DIR/palaver.sock
+}
+
+// VocabularyConfig holds terms the backend should recognize and
+// replacements applied to every transcript before post-processing.
+type VocabularyConfig struct {
+	Terms   []string      `toml:"terms"` // sent in the transcription prompt; spelling is restored if only the case differs
+	Replace []Replacement `toml:"replace"`
+}
+
+// Replacement is one [[vocabulary.replace]] rule. From matches
+// case-insensitively as whole words unless Regex is set, in which case it
+// is a regular expression and To may refer to its groups as $1.
+type Replacement struct {
+	From  string `toml:"from"`
+	To    string `toml:"to"`
+	Regex bool   `toml:"regex"`
+}
+
+// CommandsConfig holds spoken command settings.
+type CommandsConfig struct {
+	Enabled bool     `toml:"enabled"`
+	Locale  string   `toml:"locale"` // command language; empty = follow the transcription language
+	Phrases []Phrase `toml:"phrase"`
+}
+
+// Phrase is one [[commands.phrase]] entry: when Say is spoken, Text is
+// inserted in its place.
+type Phrase struct {
+	Say  string `toml:"say"`
+	Text string `toml:"text"`
+}
+
 // CustomTone defines a user-provided tone preset for post-processing.
 type CustomTone struct {
 	Name   string `toml:"name"`
 	Prompt string `toml:"prompt"`
 }
 
+// Profile is one [[profile]] entry: settings used while the focused window
+// matches. Class, Title, and Process are regular expressions matched
+// case-insensitively; all that are set must match, and the first matching
+// profile wins. Empty overrides keep the global setting.
+type Profile struct {
+	Name         string `toml:"name"`
+	Class        string `toml:"class"`          // window class or Wayland app id, e.g. "slack"
+	Title        string `toml:"title"`          // window title
+	Process      string `toml:"process"`        // name of the window's process, e.g. "thunderbird"
+	Tone         string `toml:"tone"`           // post-processing tone, e.g. "formal" or "off"
+	PasteMode    string `toml:"paste_mode"`     // "type" or "clipboard"
+	PasteDelayMs int    `toml:"paste_delay_ms"` // 0 = keep paste.delay_ms
+	Language     string `toml:"language"`       // transcription language, e.g. "de" or "auto"
+}
+
 // CustomTheme defines a user-provided color theme.
 type CustomTheme struct {
 	Name       string `toml:"name"`
@@ -87,7 +207,12 @@ type Config struct {
 	Paste          PasteConfig          `toml:"paste"`
 	Server         ServerConfig         `toml:"server"`
 	PostProcessing PostProcessingConfig `toml:"post_processing"`
+	History        HistoryConfig        `toml:"history"`
+	Control        ControlConfig        `toml:"control"`
+	Vocabulary     VocabularyConfig     `toml:"vocabulary"`
+	Commands       CommandsConfig       `toml:"commands"`
 	CustomTones    []CustomTone         `toml:"custom_tone"`
+	Profiles       []Profile            `toml:"profile"`
 }
 
 // Default returns a Config populated with all default values.
@@ -95,26 +220,47 @@ func Default() *Config {
 	return &Config{
 		Theme: "synthwave",
 		Hotkey: HotkeyConfig{
-			Key:    defaultHotkeyKey,
-			Device: "",
+			Key:             defaultHotkeyKey,
+			Device:          "",
+			Mode:            "hold",
+			HoldThresholdMs: 300,
 		},
 		Audio: AudioConfig{
-			TargetSampleRate: 16000,
-			MaxDurationSec:   60,
-			ChimeStart:       "",
-			ChimeStop:        "",
-			ChimeEnabled:     true,
+			TargetSampleRate:  16000,
+			MaxDurationSec:    600,
+			ChimeStart:        "",
+			ChimeStop:         "",
+			ChimeEnabled:      true,
+			VADEnabled:        false,
+			VADThreshold:      0.01,
+			VADPaddingMs:      250,
+			VADMinSpeechMs:    100,
+			Trigger:           "hotkey",
+			TriggerThreshold:  0.02,
+			TriggerStartMs:    150,
+			TriggerHangoverMs: 1000,
+			TriggerPreRollMs:  300,
 		},
 		Transcription: TranscriptionConfig{
-			Provider:   "openai",
-			BaseURL:    "http://localhost:5092",
-			Model:      "whisper-1",
-			TimeoutSec: 30,
-			Command:    "",
+			Provider:             "openai",
+			BaseURL:              "http://localhost:5092",
+			Model:                "whisper-1",
+			TimeoutSec:           30,
+			Command:              "",
+			Streaming:            false,
+			StreamChunkSec:       3,
+			Languages:            []string{"en", "de", "es", "fr"},
+			ChunkSec:             60,
+			ChunkOverlapMs:       1000,
+			ChunkParallelism:     2,
+			LowConfidenceLogprob: -1.0,
 		},
 		Paste: PasteConfig{
-			DelayMs: 50,
-			Mode:    defaultPasteMode,
+			DelayMs:          50,
+			Mode:             defaultPasteMode,
+			Backend:          "auto",
+			Chord:            defaultPasteChord,
+			RestoreClipboard: true,
 		},
 		Server: ServerConfig{
 			AutoStart: true,
@@ -128,6 +274,15 @@ func Default() *Config {
 			BaseURL:    "http://localhost:11434/v1",
 			TimeoutSec: 10,
 		},
+		History: HistoryConfig{
+			Enabled:    true,
+			Path:       "",
+			MaxEntries: 1000,
+		},
+		Control: ControlConfig{
+			Enabled: true,
+			Socket:  "",
+		},
 	}
 }
 
@@ -15,15 +15,60 @@ func TestDefaultValues(t *testing.T) {
 	if cfg.Hotkey.Device != "" {
 		t.Errorf("expected empty device, got %s", cfg.Hotkey.Device)
 	}
+	if cfg.Hotkey.Mode != "hold" {
+		t.Errorf("expected hotkey mode hold, got %s", cfg.Hotkey.Mode)
+	}
+	if cfg.Hotkey.HoldThresholdMs != 300 {
+		t.Errorf("expected hold threshold 300, got %d", cfg.Hotkey.HoldThresholdMs)
+	}
 	if cfg.Audio.TargetSampleRate != 16000 {
 		t.Errorf("expected sample rate 16000, got %d", cfg.Audio.TargetSampleRate)
 	}
-	if cfg.Audio.MaxDurationSec != 60 {
-		t.Errorf("expected max duration 60, got %d", cfg.Audio.MaxDurationSec)
+	if cfg.Audio.MaxDurationSec != 600 {
+		t.Errorf("expected max duration 600, got %d", cfg.Audio.MaxDurationSec)
 	}
 	if !cfg.Audio.ChimeEnabled {
 		t.Error("expected chime enabled by default")
 	}
+	if cfg.Audio.VADEnabled {
+		t.Error("expected VAD disabled by default")
+	}
+	if cfg.Audio.VADThreshold != 0.01 {
+		t.Errorf("expected VAD threshold 0.01, got %f", cfg.Audio.VADThreshold)
+	}
+	if cfg.Audio.VADPaddingMs != 250 {
+		t.Errorf("expected VAD padding 250, got %d", cfg.Audio.VADPaddingMs)
+	}
+	if cfg.Audio.VADMinSpeechMs != 100 {
+		t.Errorf("expected VAD min speech 100, got %d", cfg.Audio.VADMinSpeechMs)
+	}
+	if cfg.Audio.Trigger != "hotkey" {
+		t.Errorf("expected trigger 'hotkey', got %q", cfg.Audio.Trigger)
+	}
+	if cfg.Audio.TriggerHangoverMs != 1000 {
+		t.Errorf("expected trigger hangover 1000, got %d", cfg.Audio.TriggerHangoverMs)
+	}
+	if cfg.Transcription.ChunkSec != 60 {
+		t.Errorf("expected chunk_sec 60, got %d", cfg.Transcription.ChunkSec)
+	}
+	if cfg.Transcription.ChunkParallelism != 2 {
+		t.Errorf("expected chunk_parallelism 2, got %d", cfg.Transcription.ChunkParallelism)
+	}
+	if cfg.Transcription.Detailed {
+		t.Error("expected detailed transcription to be off by default")
+	}
+	if cfg.Transcription.LowConfidenceLogprob != -1.0 {
+		t.Errorf("expected low_confidence_logprob -1.0, got %v", cfg.Transcription.LowConfidenceLogprob)
+	}
+	if !cfg.History.Enabled {
+		t.Error("expected history enabled by default")
+	}
+	if cfg.History.MaxEntries != 1000 {
+		t.Errorf("expected history max entries 1000, got %d", cfg.History.MaxEntries)
+	}
+	if !cfg.Control.Enabled {
+		t.Error("expected control API enabled by default")
+	}
 	if cfg.Transcription.Provider != "openai" {
 		t.Errorf("expected provider openai, got %s", cfg.Transcription.Provider)
 	}
@@ -36,9 +81,21 @@ func TestDefaultValues(t *testing.T) {
 	if cfg.Transcription.TimeoutSec != 30 {
 		t.Errorf("expected timeout 30, got %d", cfg.Transcription.TimeoutSec)
 	}
+	if cfg.Transcription.Streaming {
+		t.Error("expected streaming disabled by default")
+	}
+	if cfg.Transcription.StreamChunkSec != 3 {
+		t.Errorf("expected stream chunk 3s, got %d", cfg.Transcription.StreamChunkSec)
+	}
 	if cfg.Paste.DelayMs != 50 {
 		t.Errorf("expected paste delay 50, got %d", cfg.Paste.DelayMs)
 	}
+	if cfg.Paste.Backend != "auto" {
+		t.Errorf("expected paste backend auto, got %s", cfg.Paste.Backend)
+	}
+	if cfg.Paste.Chord != defaultPasteChord || !cfg.Paste.RestoreClipboard {
+		t.Errorf("expected chord %s with clipboard restore, got %s, %v", defaultPasteChord, cfg.Paste.Chord, cfg.Paste.RestoreClipboard)
+	}
 }
 
 func TestDefaultPostProcessingValues(t *testing.T) {
@@ -143,6 +200,192 @@ prompt = "Rewrite as a pirate would say it."
 	}
 }
 
+func TestLoadTranscriptionBackends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[transcription]
+timeout_sec = 20
+
+[[transcription.backends]]
+name = "parakeet"
+base_url = "http://localhost:5092"
+timeout_sec = 5
+
+[[transcription.backends]]
+provider = "command"
+command = "whisper-cpp -f {input}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Transcription.Backends) != 2 {
+		t.Fatalf("expected 2 backends, got %d", len(cfg.Transcription.Backends))
+	}
+	if b := cfg.Transcription.Backends[0]; b.Name != "parakeet" || b.BaseURL != "http://localhost:5092" || b.TimeoutSec != 5 {
+		t.Errorf("unexpected first backend: %+v", b)
+	}
+	if b := cfg.Transcription.Backends[1]; b.Provider != "command" || b.Command != "whisper-cpp -f {input}" {
+		t.Errorf("unexpected second backend: %+v", b)
+	}
+	if cfg.Transcription.TimeoutSec != 20 {
+		t.Errorf("expected timeout_sec 20, got %d", cfg.Transcription.TimeoutSec)
+	}
+}
+
+func TestLoadTranscriptionRequestOptions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[transcription]
+language = "de"
+languages = ["de", "en"]
+prompt = "Palaver, Kubernetes, Grafana"
+temperature = 0.2
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tc := cfg.Transcription
+	if tc.Language != "de" || tc.Prompt != "Palaver, Kubernetes, Grafana" {
+		t.Errorf("unexpected request options: language=%q prompt=%q", tc.Language, tc.Prompt)
+	}
+	if tc.Temperature == nil || *tc.Temperature != 0.2 {
+		t.Errorf("expected temperature 0.2, got %v", tc.Temperature)
+	}
+	if Default().Transcription.Temperature != nil {
+		t.Error("expected temperature to be unset by default")
+	}
+	if len(tc.Languages) != 2 || tc.Languages[0] != "de" || tc.Languages[1] != "en" {
+		t.Errorf("expected languages [de en], got %v", tc.Languages)
+	}
+}
+
+func TestLoadCommands(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[commands]
+enabled = true
+locale = "de"
+
+[[commands.phrase]]
+say = "smiley face"
+text = ":)"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Commands.Enabled || cfg.Commands.Locale != "de" {
+		t.Errorf("unexpected commands config: %+v", cfg.Commands)
+	}
+	if len(cfg.Commands.Phrases) != 1 || cfg.Commands.Phrases[0] != (Phrase{Say: "smiley face", Text: ":)"}) {
+		t.Errorf("unexpected phrases: %+v", cfg.Commands.Phrases)
+	}
+	if Default().Commands.Enabled {
+		t.Error("expected spoken commands disabled by default")
+	}
+}
+
+func TestLoadHotkeyBindings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[hotkey]
+key = "KEY_RIGHTCTRL"
+
+[[hotkey.binding]]
+key = "KEY_F8"
+action = "record"
+tone = "formal"
+
+[[hotkey.binding]]
+key = "KEY_F9"
+action = "undo"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []HotkeyBinding{
+		{Key: "KEY_F8", Action: "record", Tone: "formal"},
+		{Key: "KEY_F9", Action: "undo"},
+	}
+	if len(cfg.Hotkey.Bindings) != len(want) {
+		t.Fatalf("expected %d bindings, got %+v", len(want), cfg.Hotkey.Bindings)
+	}
+	for i := range want {
+		if cfg.Hotkey.Bindings[i] != want[i] {
+			t.Errorf("binding %d = %+v, want %+v", i, cfg.Hotkey.Bindings[i], want[i])
+		}
+	}
+	if cfg.Hotkey.Key != "KEY_RIGHTCTRL" || cfg.Hotkey.Mode != "hold" {
+		t.Errorf("expected the main hotkey unchanged, got %+v", cfg.Hotkey)
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+
+	content := `
+[[profile]]
+name = "slack"
+class = "slack"
+paste_mode = "clipboard"
+paste_delay_ms = 150
+
+[[profile]]
+name = "agent"
+title = "claude|codex"
+tone = "token-efficient"
+language = "en"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Profile{
+		{Name: "slack", Class: "slack", PasteMode: "clipboard", PasteDelayMs: 150},
+		{Name: "agent", Title: "claude|codex", Tone: "token-efficient", Language: "en"},
+	}
+	if len(cfg.Profiles) != len(want) {
+		t.Fatalf("expected %d profiles, got %+v", len(want), cfg.Profiles)
+	}
+	for i := range want {
+		if cfg.Profiles[i] != want[i] {
+			t.Errorf("profile %d = %+v, want %+v", i, cfg.Profiles[i], want[i])
+		}
+	}
+}
+
 func TestSaveRoundTripWithPostProcessing(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.toml")
@@ -199,6 +442,8 @@ func TestLoadOverrides(t *testing.T) {
 [hotkey]
 key = "KEY_F12"
 device = "/dev/input/event5"
+mode = "hybrid"
+hold_threshold_ms = 250
 
 [audio]
 target_sample_rate = 48000
@@ -230,6 +475,12 @@ delay_ms = 100
 	if cfg.Hotkey.Device != "/dev/input/event5" {
 		t.Errorf("expected /dev/input/event5, got %s", cfg.Hotkey.Device)
 	}
+	if cfg.Hotkey.Mode != "hybrid" {
+		t.Errorf("expected hybrid, got %s", cfg.Hotkey.Mode)
+	}
+	if cfg.Hotkey.HoldThresholdMs != 250 {
+		t.Errorf("expected 250, got %d", cfg.Hotkey.HoldThresholdMs)
+	}
 	if cfg.Audio.TargetSampleRate != 48000 {
 		t.Errorf("expected 48000, got %d", cfg.Audio.TargetSampleRate)
 	}
@@ -4,3 +4,4 @@ package config
 
 const defaultHotkeyKey = "Cmd+Option"
 const defaultPasteMode = "clipboard"
+const defaultPasteChord = "cmd+v"
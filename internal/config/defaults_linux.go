@@ -4,3 +4,4 @@ package config
 
 const defaultHotkeyKey = "KEY_RIGHTCTRL"
 const defaultPasteMode = "type"
+const defaultPasteChord = "ctrl+v"
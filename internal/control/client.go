@@ -0,0 +1,156 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+// ErrNotRunning is returned by Client methods when nothing is listening on
+// the socket.
+var ErrNotRunning = errors.New("palaver is not running")
+
+// APIError is returned by Client methods when the instance rejects a
+// request.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// Client talks to a running instance over its control socket.
+type Client struct {
+	path string
+	http *http.Client
+}
+
+// NewClient returns a client for the socket at path.
+func NewClient(path string) *Client {
+	return &Client{
+		path: path,
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the instance's current status.
+func (c *Client) Status(ctx context.Context) (pipeline.Status, error) {
+	var st pipeline.Status
+	err := c.do(ctx, http.MethodGet, "/status", nil, &st)
+	return st, err
+}
+
+// Start starts recording and returns the resulting status.
+func (c *Client) Start(ctx context.Context) (pipeline.Status, error) {
+	return c.post(ctx, "/start", nil)
+}
+
+// Stop stops recording and returns the resulting status.
+func (c *Client) Stop(ctx context.Context) (pipeline.Status, error) {
+	return c.post(ctx, "/stop", nil)
+}
+
+// Toggle starts or stops recording and returns the resulting status.
+func (c *Client) Toggle(ctx context.Context) (pipeline.Status, error) {
+	return c.post(ctx, "/toggle", nil)
+}
+
+// SetTone switches the post-processing tone.
+func (c *Client) SetTone(ctx context.Context, name string) (pipeline.Status, error) {
+	return c.post(ctx, "/tone", nameRequest{Name: name})
+}
+
+// SetModel switches the post-processing model.
+func (c *Client) SetModel(ctx context.Context, name string) (pipeline.Status, error) {
+	return c.post(ctx, "/model", nameRequest{Name: name})
+}
+
+// SetTheme switches the TUI theme.
+func (c *Client) SetTheme(ctx context.Context, name string) (pipeline.Status, error) {
+	return c.post(ctx, "/theme", nameRequest{Name: name})
+}
+
+// Repaste pastes the last transcript again after delayMs.
+func (c *Client) Repaste(ctx context.Context, delayMs int) (pipeline.Status, error) {
+	return c.post(ctx, "/repaste", RepasteRequest{DelayMs: delayMs})
+}
+
+// Undo deletes the last paste. It returns an *APIError with status 404 if
+// there is nothing to undo and 409 if focus has changed since the paste.
+func (c *Client) Undo(ctx context.Context) (pipeline.Status, error) {
+	return c.post(ctx, "/undo", nil)
+}
+
+// LastTranscript returns the most recent transcript. It returns an
+// *APIError with status 404 if nothing was transcribed yet.
+func (c *Client) LastTranscript(ctx context.Context) (string, error) {
+	var resp TranscriptResponse
+	err := c.do(ctx, http.MethodGet, "/last-transcript", nil, &resp)
+	return resp.Text, err
+}
+
+func (c *Client) post(ctx context.Context, path string, body any) (pipeline.Status, error) {
+	var st pipeline.Status
+	err := c.do(ctx, http.MethodPost, path, body, &st)
+	return st, err
+}
+
+// do sends a request with body encoded as JSON (if non-nil) and decodes a
+// successful response into out.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	// The host is ignored; requests always go to the socket.
+	req, err := http.NewRequestWithContext(ctx, method, "http://palaver"+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return fmt.Errorf("%w (no socket at %s)", ErrNotRunning, c.path)
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxRequestBytes)).Decode(&e); err != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
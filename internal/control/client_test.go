@@ -0,0 +1,86 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+func TestClient(t *testing.T) {
+	ctrl := newFakeController()
+	ctrl.status = pipeline.Status{State: pipeline.StateRecording, Tone: "formal"}
+	srv, _ := startServer(t, ctrl)
+	c := NewClient(srv.Path())
+	ctx := context.Background()
+
+	st, err := c.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st.State != pipeline.StateRecording || st.Tone != "formal" {
+		t.Errorf("unexpected status: %+v", st)
+	}
+
+	if _, err := c.Toggle(ctx); err != nil {
+		t.Errorf("Toggle: %v", err)
+	}
+	if _, err := c.SetTone(ctx, "direct"); err != nil {
+		t.Errorf("SetTone: %v", err)
+	}
+	if _, err := c.SetTheme(ctx, "gruvbox"); err != nil {
+		t.Errorf("SetTheme: %v", err)
+	}
+
+	var apiErr *APIError
+	if _, err := c.SetTone(ctx, "shouty"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a 400 APIError for an unknown tone, got %v", err)
+	} else if !strings.Contains(apiErr.Message, "shouty") {
+		t.Errorf("expected the server's message, got %q", apiErr.Message)
+	}
+
+	if _, err := c.LastTranscript(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 APIError before any transcript, got %v", err)
+	}
+	if _, err := c.Repaste(ctx, 0); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 APIError for repaste before any transcript, got %v", err)
+	}
+
+	ctrl.mu.Lock()
+	ctrl.status.LastTranscript = "hello world"
+	ctrl.mu.Unlock()
+	if text, err := c.LastTranscript(ctx); err != nil || text != "hello world" {
+		t.Errorf("LastTranscript = %q, %v", text, err)
+	}
+	if _, err := c.Repaste(ctx, 1500); err != nil {
+		t.Errorf("Repaste: %v", err)
+	}
+	if _, err := c.Undo(ctx); err != nil {
+		t.Errorf("Undo: %v", err)
+	}
+
+	if got := strings.Join(ctrl.calls, ","); got != "toggle,tone direct,theme gruvbox,repaste 1500,undo" {
+		t.Errorf("unexpected calls: %s", got)
+	}
+}
+
+func TestClientNotRunning(t *testing.T) {
+	c := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+	if _, err := c.Status(context.Background()); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestRepasteRejectsNegativeDelay(t *testing.T) {
+	ctrl := newFakeController()
+	ctrl.status.LastTranscript = "hello"
+	srv, _ := startServer(t, ctrl)
+	var apiErr *APIError
+	if _, err := NewClient(srv.Path()).Repaste(context.Background(), -5); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative delay, got %v", err)
+	}
+}
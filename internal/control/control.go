@@ -0,0 +1,339 @@
+// Package control serves a small HTTP API on a Unix domain socket so
+// window-manager keybindings, status bars, and scripts can drive a running
+// Palaver instance.
+//
+// Endpoints:
+//
+//	GET  /status           current pipeline.Status
+//	POST /start            start recording (or arm hands-free listening)
+//	POST /stop             stop recording (or pause listening)
+//	POST /toggle           start or stop
+//	POST /tone             {"name": "formal"} switch post-processing tone
+//	POST /model            {"name": "llama3.2"} switch post-processing model
+//	POST /theme            {"name": "gruvbox"} switch the TUI theme
+//	GET  /last-transcript  {"text": "..."}; 404 if nothing was transcribed yet
+//	POST /repaste          {"delay_ms": 2000} paste the last transcript again
+//	POST /undo             delete the last paste; 409 if focus has changed
+//	GET  /events           newline-delimited JSON pipeline.Event stream
+//
+// Errors are returned as {"error": "..."} with a 4xx or 5xx status.
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+// Controller is the running instance the API drives.
+type Controller interface {
+	Start() error
+	Stop() error
+	Toggle() error
+	SetTone(name string) error
+	SetModel(name string) error
+	SetTheme(name string) error
+	Repaste(delayMs int) error
+	Undo() error
+	Status() pipeline.Status
+	Subscribe() (<-chan pipeline.Event, func())
+}
+
+// ErrInvalid marks errors caused by a bad request, such as an unknown tone.
+// Controllers wrap it so the API can answer 400 instead of 500.
+var ErrInvalid = errors.New("invalid request")
+
+// ErrNotFound marks requests for something that does not exist yet, such
+// as a re-paste before anything was transcribed.
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyRunning is returned by Listen when another instance is serving
+// on the socket.
+var ErrAlreadyRunning = errors.New("another palaver instance is running")
+
+// DefaultSocketPath returns $XDG_RUNTIME_DIR/palaver.sock, or the socket
+// in a per-user directory in the temp directory where XDG_RUNTIME_DIR is
+// unset (e.g. macOS).
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "palaver.sock")
+	}
+	return filepath.Join(tempSocketDir(), "palaver.sock")
+}
+
+// tempSocketDir is the per-user directory for the socket when
+// XDG_RUNTIME_DIR is unset.
+func tempSocketDir() string {
+	return filepath.Join(os.TempDir(), "palaver-"+strconv.Itoa(os.Getuid()))
+}
+
+// privateDir creates dir with mode 0700 if it does not exist, and checks
+// that it is a directory only the current user can use, so that another
+// user cannot have created it in the shared temp directory first.
+func privateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create socket directory: %w", err)
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("socket directory: %w", err)
+	}
+	if !info.IsDir() || !ownedBy(info, os.Getuid()) || info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("socket directory %s must be a directory owned by you with mode 0700", dir)
+	}
+	return nil
+}
+
+// ownedBy reports whether the file described by info belongs to uid.
+func ownedBy(info os.FileInfo, uid int) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == uid
+}
+
+// SocketPath returns the configured socket path, or DefaultSocketPath if
+// it is empty.
+func SocketPath(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	return DefaultSocketPath()
+}
+
+// Server serves the control API on a Unix socket.
+type Server struct {
+	path   string
+	ln     net.Listener
+	http   *http.Server
+	logger *log.Logger
+}
+
+// Listen creates the socket at path (mode 0600) and returns a Server ready
+// to Serve. A stale socket left by a crashed instance is replaced, but
+// only if it belongs to the current user.
+func Listen(path string, ctrl Controller, logger *log.Logger) (*Server, error) {
+	if dir := filepath.Dir(path); dir == tempSocketDir() {
+		if err := privateDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	if info, err := os.Lstat(path); err == nil && !ownedBy(info, os.Getuid()) {
+		return nil, fmt.Errorf("socket %s belongs to another user", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w (socket %s)", ErrAlreadyRunning, path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	// The default directories, $XDG_RUNTIME_DIR and the private temp
+	// directory, are 0700, so nobody else can connect before the chmod.
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+	return &Server{
+		path:   path,
+		ln:     ln,
+		http:   &http.Server{Handler: NewHandler(ctrl, logger), ReadHeaderTimeout: 5 * time.Second},
+		logger: logger,
+	}, nil
+}
+
+// Path returns the socket path.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Serve handles requests until Close is called.
+func (s *Server) Serve() error {
+	s.logger.Printf("control: listening on %s", s.path)
+	if err := s.http.Serve(s.ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close stops the server, ending any event streams, and removes the socket.
+func (s *Server) Close() error {
+	err := s.http.Close()
+	_ = os.Remove(s.path)
+	return err
+}
+
+// NewHandler returns the API's HTTP handler.
+func NewHandler(ctrl Controller, logger *log.Logger) http.Handler {
+	h := &handler{ctrl: ctrl, logger: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", h.status)
+	mux.HandleFunc("POST /start", h.action(ctrl.Start))
+	mux.HandleFunc("POST /stop", h.action(ctrl.Stop))
+	mux.HandleFunc("POST /toggle", h.action(ctrl.Toggle))
+	mux.HandleFunc("POST /tone", h.setting(ctrl.SetTone))
+	mux.HandleFunc("POST /model", h.setting(ctrl.SetModel))
+	mux.HandleFunc("POST /theme", h.setting(ctrl.SetTheme))
+	mux.HandleFunc("GET /last-transcript", h.lastTranscript)
+	mux.HandleFunc("POST /repaste", h.repaste)
+	mux.HandleFunc("POST /undo", h.action(ctrl.Undo))
+	mux.HandleFunc("GET /events", h.events)
+	return mux
+}
+
+type handler struct {
+	ctrl   Controller
+	logger *log.Logger
+}
+
+// nameRequest is the body of POST /tone and POST /model.
+type nameRequest struct {
+	Name string `json:"name"`
+}
+
+// RepasteRequest is the optional body of POST /repaste.
+type RepasteRequest struct {
+	DelayMs int `json:"delay_ms,omitempty"` // wait before pasting, at least paste.delay_ms
+}
+
+// TranscriptResponse is the body of GET /last-transcript.
+type TranscriptResponse struct {
+	Text string `json:"text"`
+}
+
+// ErrorResponse is the body of every failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// maxRequestBytes bounds request bodies; the API only takes short names.
+const maxRequestBytes = 4096
+
+func (h *handler) status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, h.ctrl.Status())
+}
+
+// action runs fn and replies with the resulting status.
+func (h *handler) action(fn func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, h.ctrl.Status())
+	}
+}
+
+// setting decodes a {"name": ...} body, passes the name to fn, and replies
+// with the resulting status.
+func (h *handler) setting(fn func(string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req nameRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			h.fail(w, r, fmt.Errorf("%w: decode body: %v", ErrInvalid, err))
+			return
+		}
+		if req.Name == "" {
+			h.fail(w, r, fmt.Errorf("%w: name is required", ErrInvalid))
+			return
+		}
+		if err := fn(req.Name); err != nil {
+			h.fail(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, h.ctrl.Status())
+	}
+}
+
+func (h *handler) lastTranscript(w http.ResponseWriter, _ *http.Request) {
+	text := h.ctrl.Status().LastTranscript
+	if text == "" {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "no transcript yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, TranscriptResponse{Text: text})
+}
+
+func (h *handler) repaste(w http.ResponseWriter, r *http.Request) {
+	var req RepasteRequest
+	err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		h.fail(w, r, fmt.Errorf("%w: decode body: %v", ErrInvalid, err))
+		return
+	}
+	if req.DelayMs < 0 {
+		h.fail(w, r, fmt.Errorf("%w: delay_ms must not be negative", ErrInvalid))
+		return
+	}
+	if err := h.ctrl.Repaste(req.DelayMs); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.ctrl.Status())
+}
+
+// events streams pipeline events as JSON lines until the client goes away
+// or the server closes.
+func (h *handler) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "streaming not supported"})
+		return
+	}
+	events, unsubscribe := h.ctrl.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// fail replies with err, choosing the status from its kind.
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalid), errors.Is(err, pipeline.ErrUnknownTone):
+		code = http.StatusBadRequest
+	case errors.Is(err, ErrNotFound), errors.Is(err, pipeline.ErrNothingToUndo):
+		code = http.StatusNotFound
+	case errors.Is(err, pipeline.ErrBusy), errors.Is(err, pipeline.ErrFocusChanged):
+		code = http.StatusConflict
+	}
+	h.logger.Printf("control: %s %s: %v", r.Method, r.URL.Path, err)
+	writeJSON(w, code, ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
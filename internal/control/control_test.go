@@ -0,0 +1,350 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Danondso/palaver/internal/pipeline"
+)
+
+// fakeController records calls and serves a fixed status.
+type fakeController struct {
+	mu       sync.Mutex
+	status   pipeline.Status
+	calls    []string
+	err      error
+	events   chan pipeline.Event
+	unsubbed chan struct{}
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{
+		events:   make(chan pipeline.Event, 8),
+		unsubbed: make(chan struct{}),
+	}
+}
+
+func (f *fakeController) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	return f.err
+}
+
+func (f *fakeController) Start() error  { return f.record("start") }
+func (f *fakeController) Stop() error   { return f.record("stop") }
+func (f *fakeController) Toggle() error { return f.record("toggle") }
+func (f *fakeController) Undo() error   { return f.record("undo") }
+
+func (f *fakeController) SetTone(name string) error {
+	if name == "shouty" {
+		return fmt.Errorf("%w: %s", pipeline.ErrUnknownTone, name)
+	}
+	return f.record("tone " + name)
+}
+
+func (f *fakeController) SetModel(name string) error { return f.record("model " + name) }
+func (f *fakeController) SetTheme(name string) error { return f.record("theme " + name) }
+
+func (f *fakeController) Repaste(delayMs int) error {
+	if f.Status().LastTranscript == "" {
+		return fmt.Errorf("%w: no transcript yet", ErrNotFound)
+	}
+	return f.record(fmt.Sprintf("repaste %d", delayMs))
+}
+
+func (f *fakeController) Status() pipeline.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.status
+}
+
+func (f *fakeController) Subscribe() (<-chan pipeline.Event, func()) {
+	var once sync.Once
+	return f.events, func() { once.Do(func() { close(f.unsubbed) }) }
+}
+
+// startServer serves ctrl on a socket in a temp dir and returns a client
+// that dials it.
+func startServer(t *testing.T, ctrl Controller) (*Server, *http.Client) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "palaver.sock")
+	srv, err := Listen(path, ctrl, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go func() { _ = srv.Serve() }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	return srv, client
+}
+
+func TestDefaultSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got := DefaultSocketPath(); got != "/run/user/1000/palaver.sock" {
+		t.Errorf("expected XDG_RUNTIME_DIR socket, got %s", got)
+	}
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if got := DefaultSocketPath(); filepath.Dir(got) != tempSocketDir() || !strings.HasPrefix(got, os.TempDir()) {
+		t.Errorf("expected fallback in a per-user directory under %s, got %s", os.TempDir(), got)
+	}
+	if got := SocketPath("/tmp/custom.sock"); got != "/tmp/custom.sock" {
+		t.Errorf("expected configured path, got %s", got)
+	}
+}
+
+func TestListenSocketPermissions(t *testing.T) {
+	srv, _ := startServer(t, newFakeController())
+	info, err := os.Stat(srv.Path())
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected socket mode 0600, got %o", perm)
+	}
+}
+
+func TestPrivateDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "palaver-test")
+	if err := privateDir(dir); err != nil {
+		t.Fatalf("privateDir: %v", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("expected directory mode 0700, got %o", perm)
+	}
+
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := privateDir(dir); err == nil {
+		t.Error("expected a directory others can read to be refused")
+	}
+}
+
+func TestOwnedBy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "palaver.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ownedBy(info, os.Getuid()) {
+		t.Error("expected the file to belong to the current user")
+	}
+	if ownedBy(info, os.Getuid()+1) {
+		t.Error("expected the file not to belong to another user")
+	}
+}
+
+func TestListenRefusesSecondInstance(t *testing.T) {
+	srv, _ := startServer(t, newFakeController())
+	if _, err := Listen(srv.Path(), newFakeController(), log.New(io.Discard, "", 0)); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("expected ErrAlreadyRunning, got %v", err)
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "palaver.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := Listen(path, newFakeController(), log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatalf("Listen over stale socket: %v", err)
+	}
+	_ = srv.Close()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected socket removed on Close, got %v", err)
+	}
+}
+
+func TestActions(t *testing.T) {
+	ctrl := newFakeController()
+	ctrl.status = pipeline.Status{State: pipeline.StateRecording}
+	_, client := startServer(t, ctrl)
+
+	for _, path := range []string{"/start", "/stop", "/toggle", "/undo"} {
+		resp, err := client.Post("http://palaver"+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		var st map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&st)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("POST %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if st["state"] != "recording" {
+			t.Errorf("POST %s: expected status in reply, got %v", path, st)
+		}
+	}
+	if got := strings.Join(ctrl.calls, ","); got != "start,stop,toggle,undo" {
+		t.Errorf("unexpected calls: %s", got)
+	}
+
+	resp, err := client.Get("http://palaver/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /start, got %d", resp.StatusCode)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	ctrl := newFakeController()
+	_, client := startServer(t, ctrl)
+
+	tests := []struct {
+		path string
+		body string
+		code int
+	}{
+		{"/tone", `{"name":"formal"}`, http.StatusOK},
+		{"/model", `{"name":"mistral"}`, http.StatusOK},
+		{"/theme", `{"name":"gruvbox"}`, http.StatusOK},
+		{"/tone", `{"name":"shouty"}`, http.StatusBadRequest},
+		{"/tone", `{}`, http.StatusBadRequest},
+		{"/model", `not json`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := client.Post("http://palaver"+tt.path, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("POST %s: %v", tt.path, err)
+		}
+		var errResp ErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("POST %s %s: expected %d, got %d", tt.path, tt.body, tt.code, resp.StatusCode)
+		}
+		if tt.code != http.StatusOK && errResp.Error == "" {
+			t.Errorf("POST %s %s: expected an error message", tt.path, tt.body)
+		}
+	}
+	if got := strings.Join(ctrl.calls, ","); got != "tone formal,model mistral,theme gruvbox" {
+		t.Errorf("unexpected calls: %s", got)
+	}
+}
+
+func TestErrorStatusCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{pipeline.ErrBusy, http.StatusConflict},
+		{pipeline.ErrFocusChanged, http.StatusConflict},
+		{pipeline.ErrNothingToUndo, http.StatusNotFound},
+		{fmt.Errorf("%w: no transcript yet", ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: hands-free", ErrInvalid), http.StatusBadRequest},
+		{errors.New("no microphone"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		ctrl := newFakeController()
+		ctrl.err = tt.err
+		_, client := startServer(t, ctrl)
+		resp, err := client.Post("http://palaver/start", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%v: expected %d, got %d", tt.err, tt.code, resp.StatusCode)
+		}
+	}
+}
+
+func TestLastTranscript(t *testing.T) {
+	ctrl := newFakeController()
+	_, client := startServer(t, ctrl)
+
+	resp, err := client.Get("http://palaver/last-transcript")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 before any transcript, got %d", resp.StatusCode)
+	}
+
+	ctrl.mu.Lock()
+	ctrl.status.LastTranscript = "hello world"
+	ctrl.mu.Unlock()
+	resp, err = client.Get("http://palaver/last-transcript")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tr TranscriptResponse
+	_ = json.NewDecoder(resp.Body).Decode(&tr)
+	_ = resp.Body.Close()
+	if tr.Text != "hello world" {
+		t.Errorf("expected 'hello world', got %q", tr.Text)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	ctrl := newFakeController()
+	_, client := startServer(t, ctrl)
+
+	resp, err := client.Get("http://palaver/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected ndjson content type, got %s", ct)
+	}
+
+	ctrl.events <- pipeline.Event{Kind: pipeline.EventRecordingStarted, Status: pipeline.Status{State: pipeline.StateRecording}}
+	ctrl.events <- pipeline.Event{Kind: pipeline.EventRecordingStopped, Status: pipeline.Status{State: pipeline.StateTranscribing}}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var states []string
+	for len(states) < 2 && scanner.Scan() {
+		var ev struct {
+			Kind   string `json:"kind"`
+			Status struct {
+				State string `json:"state"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("decode event line %q: %v", scanner.Text(), err)
+		}
+		states = append(states, ev.Kind+":"+ev.Status.State)
+	}
+	if got := strings.Join(states, ","); got != "recording_started:recording,recording_stopped:transcribing" {
+		t.Errorf("unexpected events: %s", got)
+	}
+
+	_ = resp.Body.Close()
+	select {
+	case <-ctrl.unsubbed:
+	case <-time.After(2 * time.Second):
+		t.Error("expected the stream to unsubscribe after the client disconnects")
+	}
+}
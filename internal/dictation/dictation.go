@@ -0,0 +1,302 @@
+// Package dictation interprets spoken formatting and editing commands,
+// such as "comma", "new line", and "scratch that", in transcripts.
+package dictation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// action is what a spoken command does.
+type action int
+
+const (
+	insertWord  action = iota // text spaced like a word
+	insertLeft                // punctuation joined to the previous word, e.g. ","
+	insertRight               // an opening mark joined to the next word, e.g. "("
+	insertBreak               // a line break; no spaces around it
+	scratch                   // delete what was dictated before
+	capNext                   // capitalize the next word
+	upperNext                 // upper-case the next word
+	lowerNext                 // lower-case the next word
+)
+
+// command is a spoken phrase, stored as normalized words, and its action.
+type command struct {
+	words    []string
+	action   action
+	text     string
+	boundary bool // only a command at the end of a phrase
+}
+
+// Result is an interpreted transcript.
+type Result struct {
+	Text string
+	// Attach is set when Text starts with punctuation or a line break that
+	// joins the previous paste without a space.
+	Attach bool
+	// ScratchPrevious is set when "scratch that" was said with nothing
+	// before it, asking to delete the previously pasted text.
+	ScratchPrevious bool
+}
+
+// Interpreter applies the command table of a locale to transcripts. A nil
+// *Interpreter leaves transcripts unchanged.
+type Interpreter struct {
+	locale   string // fixed locale; empty follows the transcription language
+	commands map[string][]command
+}
+
+// DefaultLocale is used when the transcription language has no table.
+const DefaultLocale = "en"
+
+// New creates an Interpreter from the [commands] config. It returns nil
+// when commands are disabled.
+func New(cfg *config.CommandsConfig) (*Interpreter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	locale := normalizeLocale(cfg.Locale)
+	if locale != "" {
+		if _, ok := locales[locale]; !ok {
+			return nil, fmt.Errorf("unknown commands locale %q (available: %s)", cfg.Locale, strings.Join(Locales(), ", "))
+		}
+	}
+	var custom []command
+	for i, p := range cfg.Phrases {
+		words := phraseWords(p.Say)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("commands phrase %d: say is empty", i+1)
+		}
+		custom = append(custom, command{words: words, action: insertWord, text: p.Text})
+	}
+
+	in := &Interpreter{locale: locale, commands: make(map[string][]command, len(locales))}
+	for name, table := range locales {
+		cmds := slices.Clone(custom)
+		for _, e := range table {
+			for _, say := range e.say {
+				cmds = append(cmds, command{words: phraseWords(say), action: e.action, text: e.text, boundary: e.boundary})
+			}
+		}
+		// Longest phrases first, so "new paragraph" is not read as "new"
+		// followed by something else; custom phrases win ties.
+		slices.SortStableFunc(cmds, func(a, b command) int { return len(b.words) - len(a.words) })
+		in.commands[name] = cmds
+	}
+	return in, nil
+}
+
+// Locales returns the locales with built-in command tables.
+func Locales() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Interpret applies the commands for lang, the transcription language
+// (e.g. "de" or "auto"), unless a locale is configured.
+func (in *Interpreter) Interpret(text, lang string) Result {
+	if in == nil {
+		return Result{Text: text}
+	}
+	locale := in.locale
+	if locale == "" {
+		locale = normalizeLocale(lang)
+	}
+	cmds, ok := in.commands[locale]
+	if !ok {
+		cmds = in.commands[DefaultLocale]
+	}
+
+	var res Result
+	var b builder
+	tokens := strings.Fields(text)
+	for i := 0; i < len(tokens); {
+		c := match(cmds, tokens[i:])
+		if c != nil && c.boundary && !atBoundary(cmds, tokens[i:], len(c.words)) {
+			c = nil // an ordinary word, as in "the grace period ends"
+		}
+		if c == nil {
+			b.word(tokens[i])
+			i++
+			continue
+		}
+		said := strings.Join(tokens[i:i+len(c.words)], " ")
+		i += len(c.words)
+		if c.action != insertWord {
+			b.flushCase()
+		}
+		switch c.action {
+		case insertWord:
+			b.word(c.text)
+		case insertLeft:
+			b.left(c.text)
+		case insertRight:
+			b.right(c.text)
+		case insertBreak:
+			b.lineBreak(c.text)
+		case scratch:
+			if b.Len() == 0 {
+				res.ScratchPrevious = true
+			}
+			b.Reset()
+			b.attach, b.attachPrev, b.caseNext, b.caseSaid = false, false, 0, ""
+		case capNext, upperNext, lowerNext:
+			b.caseNext, b.caseSaid = c.action, said
+		}
+	}
+	b.flushCase()
+	res.Text = b.String()
+	res.Attach = b.attachPrev
+	return res
+}
+
+// atBoundary reports whether a command of n words at the start of tokens
+// ends a phrase: it is the last thing said, the backend put punctuation
+// after it for a pause, or another command follows it, as in "Punkt neue
+// Zeile".
+func atBoundary(cmds []command, tokens []string, n int) bool {
+	if n == len(tokens) {
+		return true
+	}
+	last := tokens[n-1]
+	if r, _ := utf8.DecodeLastRuneInString(last); unicode.IsPunct(r) {
+		return true
+	}
+	return match(cmds, tokens[n:]) != nil
+}
+
+// match returns the command that tokens start with, or nil.
+func match(cmds []command, tokens []string) *command {
+	for i := range cmds {
+		c := &cmds[i]
+		if len(c.words) > len(tokens) {
+			continue
+		}
+		ok := true
+		for j, w := range c.words {
+			if normalizeWord(tokens[j]) != w {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// builder assembles the output, tracking how the next piece is spaced.
+type builder struct {
+	strings.Builder
+	attach     bool   // the next word joins without a space
+	attachPrev bool   // the output starts by joining the previous paste
+	caseNext   action // capitalization for the next word, or 0
+	caseSaid   string // the words that set caseNext
+}
+
+func (b *builder) word(w string) {
+	switch b.caseNext {
+	case capNext:
+		r, size := utf8.DecodeRuneInString(w)
+		w = string(unicode.ToUpper(r)) + w[size:]
+	case upperNext:
+		w = strings.ToUpper(w)
+	case lowerNext:
+		w = strings.ToLower(w)
+	}
+	b.caseNext, b.caseSaid = 0, ""
+	b.space()
+	b.WriteString(w)
+	b.attach = false
+}
+
+// flushCase writes a capitalization command that no word followed as the
+// words that were said, so nothing is lost.
+func (b *builder) flushCase() {
+	if b.caseNext == 0 {
+		return
+	}
+	said := b.caseSaid
+	b.caseNext, b.caseSaid = 0, ""
+	b.word(said)
+}
+
+// space separates the next word from the previous one, if needed.
+func (b *builder) space() {
+	if b.Len() > 0 && !b.attach && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteByte(' ')
+	}
+}
+
+func (b *builder) left(mark string) {
+	// Drop punctuation the backend already put there, as in "world, period".
+	b.trimEnd(func(r rune) bool { return r == ' ' || strings.ContainsRune(".,;:!?", r) })
+	if b.Len() == 0 {
+		b.attachPrev = true
+	}
+	b.WriteString(mark)
+	b.attach = false
+}
+
+func (b *builder) right(mark string) {
+	b.space()
+	b.WriteString(mark)
+	b.attach = true
+}
+
+func (b *builder) lineBreak(s string) {
+	b.trimEnd(func(r rune) bool { return r == ' ' })
+	if b.Len() == 0 {
+		b.attachPrev = true
+	}
+	b.WriteString(s)
+	b.attach = true
+}
+
+func (b *builder) trimEnd(f func(rune) bool) {
+	s := b.String()
+	trimmed := strings.TrimRightFunc(s, f)
+	if len(trimmed) != len(s) {
+		b.Reset()
+		b.WriteString(trimmed)
+	}
+}
+
+// phraseWords splits a spoken phrase into normalized words.
+func phraseWords(say string) []string {
+	fields := strings.Fields(say)
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if w := normalizeWord(f); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// normalizeWord lower-cases w and strips the punctuation backends put
+// around words, so "Period." matches "period".
+func normalizeWord(w string) string {
+	w = strings.ReplaceAll(w, "’", "'")
+	return strings.ToLower(strings.TrimFunc(w, unicode.IsPunct))
+}
+
+// normalizeLocale reduces a language tag such as "de-DE" to "de".
+func normalizeLocale(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
@@ -0,0 +1,215 @@
+package dictation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+func TestInterpret(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.CommandsConfig
+		lang    string
+		in      string
+		want    string
+		attach  bool
+		scratch bool
+	}{
+		{
+			name: "plain text is unchanged",
+			in:   "Ship it on Tuesday.",
+			want: "Ship it on Tuesday.",
+		},
+		{
+			name: "punctuation joins the previous word",
+			in:   "Hello comma world period",
+			want: "Hello, world.",
+		},
+		{
+			name: "backend punctuation around commands is dropped",
+			in:   "Hello, comma, world. Question mark.",
+			want: "Hello, world?",
+		},
+		{
+			name: "line breaks",
+			in:   "Dear team, new paragraph. Thanks. New line. Sam",
+			want: "Dear team,\n\nThanks.\nSam",
+		},
+		{
+			name: "quotes and parentheses",
+			in:   "she said open quote hi close quote open paren twice close paren",
+			want: `she said "hi" (twice)`,
+		},
+		{
+			name: "scratch that drops the words before it",
+			in:   "meet at noon scratch that meet at one",
+			want: "meet at one",
+		},
+		{
+			name:    "scratch that alone deletes the previous paste",
+			in:      "Scratch that.",
+			want:    "",
+			scratch: true,
+		},
+		{
+			name:    "scratch that first, then new text",
+			in:      "scratch that, send it tomorrow",
+			want:    "send it tomorrow",
+			scratch: true,
+		},
+		{
+			name: "capitalization",
+			in:   "cap next palaver uses all caps json and no caps Go",
+			want: "Palaver uses JSON and go",
+		},
+		{
+			name: "capitalization with no word after it is kept",
+			in:   "I said all caps",
+			want: "I said all caps",
+		},
+		{
+			name: "capitalization before a command is kept",
+			in:   "no caps comma then",
+			want: "no caps, then",
+		},
+		{
+			name: "ordinary words in english prose",
+			in:   "the grace period ends at the colon cap",
+			want: "the grace period ends at the colon cap",
+		},
+		{
+			name: "period after a pause is a command",
+			in:   "the grace period. ends",
+			want: "the grace. ends",
+		},
+		{
+			name: "period before another command is a command",
+			in:   "done period new line next",
+			want: "done.\nnext",
+		},
+		{
+			name: "ordinary words in german prose",
+			lang: "de",
+			in:   "Das Haus ist groß und der Punkt ist klein",
+			want: "Das Haus ist groß und der Punkt ist klein",
+		},
+		{
+			name: "german capitalization commands",
+			lang: "de",
+			in:   "nächstes groß haus und alles groß",
+			want: "Haus und alles groß",
+		},
+		{
+			name: "ordinary words in french prose",
+			lang: "fr",
+			in:   "mon point de vue est minuscule",
+			want: "mon point de vue est minuscule",
+		},
+		{
+			name: "ordinary words in spanish prose",
+			lang: "es",
+			in:   "el punto de partida es un coma profundo",
+			want: "el punto de partida es un coma profundo",
+		},
+		{
+			name: "spanish period at the end",
+			lang: "es",
+			in:   "hasta mañana punto",
+			want: "hasta mañana.",
+		},
+		{
+			name:   "leading punctuation attaches to the previous paste",
+			in:     "comma and then some",
+			want:   ", and then some",
+			attach: true,
+		},
+		{
+			name:   "leading line break attaches to the previous paste",
+			in:     "new line next item",
+			want:   "\nnext item",
+			attach: true,
+		},
+		{
+			name: "locale follows the transcription language",
+			lang: "de",
+			in:   "Hallo Komma Welt Punkt neue Zeile tschüss Ausrufezeichen",
+			want: "Hallo, Welt.\ntschüss!",
+		},
+		{
+			name: "english commands are plain words in german",
+			lang: "de",
+			in:   "comma period",
+			want: "comma period",
+		},
+		{
+			name: "unknown language falls back to english",
+			lang: "auto",
+			in:   "yes comma please",
+			want: "yes, please",
+		},
+		{
+			name: "configured locale wins over the transcription language",
+			cfg:  config.CommandsConfig{Locale: "fr"},
+			lang: "en",
+			in:   "Bonjour virgule ça va point d'interrogation",
+			want: "Bonjour, ça va?",
+		},
+		{
+			name: "custom phrases",
+			cfg:  config.CommandsConfig{Phrases: []config.Phrase{{Say: "smiley face", Text: ":)"}, {Say: "comma", Text: "COMMA"}}},
+			in:   "done comma smiley face",
+			want: "done COMMA :)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Enabled = true
+			in, err := New(&cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := in.Interpret(tt.in, tt.lang)
+			if got.Text != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got.Text)
+			}
+			if got.Attach != tt.attach {
+				t.Errorf("expected attach=%v, got %v", tt.attach, got.Attach)
+			}
+			if got.ScratchPrevious != tt.scratch {
+				t.Errorf("expected scratch=%v, got %v", tt.scratch, got.ScratchPrevious)
+			}
+		})
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	in, err := New(&config.CommandsConfig{})
+	if err != nil || in != nil {
+		t.Fatalf("expected no interpreter when disabled, got %v, %v", in, err)
+	}
+	if got := in.Interpret("hello comma world", "en"); got.Text != "hello comma world" {
+		t.Errorf("expected a nil interpreter to leave text unchanged, got %q", got.Text)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.CommandsConfig
+		wantErr string
+	}{
+		{name: "unknown locale", cfg: config.CommandsConfig{Enabled: true, Locale: "xx"}, wantErr: "available: de, en, es, fr"},
+		{name: "empty phrase", cfg: config.CommandsConfig{Enabled: true, Phrases: []config.Phrase{{Text: "x"}}}, wantErr: "phrase 1: say is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package dictation
+
+// entry maps spoken phrases to an action.
+type entry struct {
+	say    []string
+	action action
+	text   string
+	// boundary marks phrases that are also ordinary words, such as
+	// "period" or "Punkt". They are commands only at the end of a phrase:
+	// see atBoundary.
+	boundary bool
+}
+
+// locales holds the built-in command tables, keyed by ISO-639-1 code.
+var locales = map[string][]entry{
+	"en": {
+		{say: []string{"new line"}, action: insertBreak, text: "\n"},
+		{say: []string{"new paragraph"}, action: insertBreak, text: "\n\n"},
+		{say: []string{"period", "full stop"}, action: insertLeft, text: ".", boundary: true},
+		{say: []string{"comma"}, action: insertLeft, text: ","},
+		{say: []string{"question mark"}, action: insertLeft, text: "?"},
+		{say: []string{"exclamation mark", "exclamation point"}, action: insertLeft, text: "!"},
+		{say: []string{"colon"}, action: insertLeft, text: ":", boundary: true},
+		{say: []string{"semicolon"}, action: insertLeft, text: ";"},
+		{say: []string{"open quote"}, action: insertRight, text: `"`},
+		{say: []string{"close quote", "end quote"}, action: insertLeft, text: `"`},
+		{say: []string{"open paren", "open parenthesis"}, action: insertRight, text: "("},
+		{say: []string{"close paren", "close parenthesis"}, action: insertLeft, text: ")"},
+		{say: []string{"scratch that"}, action: scratch},
+		{say: []string{"cap next"}, action: capNext},
+		{say: []string{"all caps"}, action: upperNext},
+		{say: []string{"no caps"}, action: lowerNext},
+	},
+	"de": {
+		{say: []string{"neue zeile"}, action: insertBreak, text: "\n"},
+		{say: []string{"neuer absatz"}, action: insertBreak, text: "\n\n"},
+		{say: []string{"punkt"}, action: insertLeft, text: ".", boundary: true},
+		{say: []string{"komma"}, action: insertLeft, text: ","},
+		{say: []string{"fragezeichen"}, action: insertLeft, text: "?"},
+		{say: []string{"ausrufezeichen"}, action: insertLeft, text: "!"},
+		{say: []string{"doppelpunkt"}, action: insertLeft, text: ":"},
+		{say: []string{"semikolon"}, action: insertLeft, text: ";"},
+		{say: []string{"anführungszeichen auf"}, action: insertRight, text: "„"},
+		{say: []string{"anführungszeichen zu"}, action: insertLeft, text: "“"},
+		{say: []string{"klammer auf"}, action: insertRight, text: "("},
+		{say: []string{"klammer zu"}, action: insertLeft, text: ")"},
+		{say: []string{"streich das", "lösch das"}, action: scratch},
+		{say: []string{"nächstes groß"}, action: capNext},
+		{say: []string{"alles groß"}, action: upperNext},
+		{say: []string{"nächstes klein"}, action: lowerNext},
+	},
+	"es": {
+		{say: []string{"nueva línea"}, action: insertBreak, text: "\n"},
+		{say: []string{"nuevo párrafo"}, action: insertBreak, text: "\n\n"},
+		{say: []string{"punto"}, action: insertLeft, text: ".", boundary: true},
+		{say: []string{"coma"}, action: insertLeft, text: ",", boundary: true},
+		{say: []string{"signo de interrogación"}, action: insertLeft, text: "?"},
+		{say: []string{"signo de exclamación"}, action: insertLeft, text: "!"},
+		{say: []string{"dos puntos"}, action: insertLeft, text: ":"},
+		{say: []string{"punto y coma"}, action: insertLeft, text: ";"},
+		{say: []string{"abrir comillas"}, action: insertRight, text: `"`},
+		{say: []string{"cerrar comillas"}, action: insertLeft, text: `"`},
+		{say: []string{"abrir paréntesis"}, action: insertRight, text: "("},
+		{say: []string{"cerrar paréntesis"}, action: insertLeft, text: ")"},
+		{say: []string{"borra eso"}, action: scratch},
+		{say: []string{"siguiente mayúscula"}, action: capNext},
+		{say: []string{"todo mayúsculas"}, action: upperNext},
+		{say: []string{"siguiente minúscula"}, action: lowerNext},
+	},
+	"fr": {
+		{say: []string{"à la ligne", "nouvelle ligne"}, action: insertBreak, text: "\n"},
+		{say: []string{"nouveau paragraphe"}, action: insertBreak, text: "\n\n"},
+		{say: []string{"point"}, action: insertLeft, text: ".", boundary: true},
+		{say: []string{"virgule"}, action: insertLeft, text: ","},
+		{say: []string{"point d'interrogation"}, action: insertLeft, text: "?"},
+		{say: []string{"point d'exclamation"}, action: insertLeft, text: "!"},
+		{say: []string{"deux points"}, action: insertLeft, text: ":"},
+		{say: []string{"point virgule"}, action: insertLeft, text: ";"},
+		{say: []string{"ouvrez les guillemets"}, action: insertRight, text: "« "},
+		{say: []string{"fermez les guillemets"}, action: insertLeft, text: " »"},
+		{say: []string{"ouvrez la parenthèse"}, action: insertRight, text: "("},
+		{say: []string{"fermez la parenthèse"}, action: insertLeft, text: ")"},
+		{say: []string{"efface ça", "annule ça"}, action: scratch},
+		{say: []string{"majuscule suivante"}, action: capNext},
+		{say: []string{"tout en majuscules"}, action: upperNext},
+		{say: []string{"minuscule suivante"}, action: lowerNext},
+	},
+}
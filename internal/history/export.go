@@ -0,0 +1,184 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats accepted by Export.
+const (
+	FormatText     = "text"
+	FormatJSONL    = "jsonl"
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+)
+
+// Filter selects entries by time range, tone, and text.
+type Filter struct {
+	Since    time.Time // zero = no lower bound (inclusive)
+	Until    time.Time // zero = no upper bound (exclusive)
+	Tone     string    // case-insensitive exact match; empty = any
+	Contains string    // case-insensitive substring of raw or pasted text
+}
+
+// Apply returns the entries that match every set field of f, preserving order.
+func (f Filter) Apply(entries []Entry) []Entry {
+	var out []Entry
+	for _, e := range Search(entries, f.Contains) {
+		if !f.Since.IsZero() && e.Time.Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+			continue
+		}
+		if f.Tone != "" && !strings.EqualFold(e.Tone, f.Tone) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ParseTime parses a filter bound relative to now. It accepts "today",
+// "yesterday", a date (2006-01-02, local time), an RFC 3339 timestamp, or a
+// duration ago such as "90m", "12h", or "7d".
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(s) {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use today, yesterday, YYYY-MM-DD, RFC 3339, or a duration like 12h or 7d)", s)
+}
+
+// ParseFormat returns the export format named by s, ignoring case. "md"
+// is short for markdown.
+func ParseFormat(s string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(s))
+	if format == "md" {
+		format = FormatMarkdown
+	}
+	if err := ValidateFormat(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// ValidateFormat returns an error if format is not a known export format.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSONL, FormatMarkdown, FormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s (valid: text, jsonl, markdown, csv)", format)
+	}
+}
+
+// Export writes entries to w in the given format. Times are rendered in
+// the local time zone except in JSON Lines, which keeps the stored value.
+func Export(w io.Writer, entries []Entry, format string) error {
+	switch format {
+	case FormatText:
+		return exportText(w, entries)
+	case FormatJSONL:
+		var buf bytes.Buffer
+		if err := encodeEntries(&buf, entries); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	case FormatMarkdown:
+		return exportMarkdown(w, entries)
+	case FormatCSV:
+		return exportCSV(w, entries)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// exportText writes one line per entry: timestamp, then the pasted text.
+func exportText(w io.Writer, entries []Entry) error {
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", e.Time.Local().Format("2006-01-02 15:04"), oneLine(e.Text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportMarkdown groups entries under a heading per day as a bullet list.
+func exportMarkdown(w io.Writer, entries []Entry) error {
+	day := ""
+	for _, e := range entries {
+		t := e.Time.Local()
+		if d := t.Format("2006-01-02"); d != day {
+			if day != "" {
+				if _, err := fmt.Fprintln(w); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "## %s\n\n", d); err != nil {
+				return err
+			}
+			day = d
+		}
+		if _, err := fmt.Fprintf(w, "- **%s** %s\n", t.Format("15:04"), oneLine(e.Text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	header := []string{"time", "text", "raw", "tone", "model", "post_model", "latency_ms", "paste_mode", "paste_error", "backend"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		row := []string{
+			e.Time.Local().Format(time.RFC3339),
+			e.Text,
+			e.Raw,
+			e.Tone,
+			e.Model,
+			e.PostModel,
+			strconv.FormatInt(e.LatencyMs, 10),
+			e.PasteMode,
+			e.PasteError,
+			e.Backend,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// oneLine collapses all whitespace runs (including newlines) to single spaces.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
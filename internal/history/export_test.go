@@ -0,0 +1,174 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestEntries() []Entry {
+	day1 := time.Date(2026, 3, 1, 9, 15, 0, 0, time.Local)
+	day2 := time.Date(2026, 3, 2, 14, 5, 0, 0, time.Local)
+	return []Entry{
+		{Time: day1, Raw: "fixed the login bug", Text: "Fixed the login bug.", Tone: "formal", LatencyMs: 300, PasteMode: "type", Backend: "parakeet"},
+		{Time: day1.Add(time.Hour), Raw: "lunch", Text: "Lunch,\nthen review", Tone: "off"},
+		{Time: day2, Raw: "standup notes", Text: "Standup notes.", Tone: "formal"},
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	entries := exportTestEntries()
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"empty filter", Filter{}, 3},
+		{"since", Filter{Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)}, 1},
+		{"until exclusive", Filter{Until: entries[1].Time}, 1},
+		{"tone", Filter{Tone: "FORMAL"}, 2},
+		{"contains", Filter{Contains: "login"}, 1},
+		{"combined", Filter{Tone: "formal", Since: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Apply(entries); len(got) != tt.want {
+				t.Errorf("got %d entries, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	midnight := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"today", midnight, false},
+		{"Yesterday", midnight.AddDate(0, 0, -1), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"2026-03-01T10:00:00Z", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"last week", time.Time{}, true},
+		{"-3h", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.input, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTime(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExportText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestEntries(), FormatText); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != "2026-03-01 09:15  Fixed the login bug." {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[1] != "2026-03-01 10:15  Lunch, then review" {
+		t.Errorf("expected newlines collapsed, got %q", lines[1])
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestEntries(), FormatMarkdown); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := "## 2026-03-01\n\n" +
+		"- **09:15** Fixed the login bug.\n" +
+		"- **10:15** Lunch, then review\n" +
+		"\n## 2026-03-02\n\n" +
+		"- **14:05** Standup notes.\n"
+	if buf.String() != want {
+		t.Errorf("unexpected markdown:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestEntries(), FormatCSV); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header + 3 rows, got %d", len(records))
+	}
+	if records[0][0] != "time" || records[0][1] != "text" {
+		t.Errorf("unexpected header: %v", records[0])
+	}
+	if records[2][1] != "Lunch,\nthen review" {
+		t.Errorf("expected text with comma and newline preserved, got %q", records[2][1])
+	}
+	if records[1][6] != "300" {
+		t.Errorf("expected latency 300, got %q", records[1][6])
+	}
+	if records[1][9] != "parakeet" {
+		t.Errorf("expected backend parakeet, got %q", records[1][9])
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, exportTestEntries(), FormatJSONL); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 3 {
+		t.Errorf("expected 3 JSON lines, got %d", n)
+	}
+	if !strings.Contains(buf.String(), `"raw":"fixed the login bug"`) {
+		t.Errorf("expected raw text in JSON output, got %s", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"text", FormatText, false},
+		{"JSONL", FormatJSONL, false},
+		{"md", FormatMarkdown, false},
+		{"MD", FormatMarkdown, false},
+		{" Markdown ", FormatMarkdown, false},
+		{"csv", FormatCSV, false},
+		{"xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, nil, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
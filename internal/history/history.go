@@ -0,0 +1,225 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// Entry is one transcription as it was delivered to the user.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Raw        string    `json:"raw"`                   // transcript as returned by the backend
+	Text       string    `json:"text"`                  // text that was pasted (after post-processing)
+	Tone       string    `json:"tone,omitempty"`        // post-processing tone, "off" if disabled
+	Model      string    `json:"model,omitempty"`       // transcription model
+	Backend    string    `json:"backend,omitempty"`     // transcription backend, with fallback backends
+	PostModel  string    `json:"post_model,omitempty"`  // post-processing model, if used
+	LatencyMs  int64     `json:"latency_ms"`            // end of recording to paste finished
+	PasteMode  string    `json:"paste_mode"`            // "type" or "clipboard"
+	PasteError string    `json:"paste_error,omitempty"` // set if the paste failed
+	Language   string    `json:"language,omitempty"`    // detected language, with detailed transcription
+	Profile    string    `json:"profile,omitempty"`     // [[profile]] matched for the focused window
+	Segments   []Segment `json:"segments,omitempty"`    // transcript timing, with detailed transcription
+}
+
+// Segment is a timed stretch of the raw transcript. Start and End are
+// seconds from the beginning of the recording.
+type Segment struct {
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Text       string  `json:"text"`
+	AvgLogprob float64 `json:"avg_logprob"`
+}
+
+// Store is an append-only JSON Lines file of entries, oldest first.
+type Store struct {
+	Path       string
+	MaxEntries int // 0 = unlimited
+
+	mu      sync.Mutex
+	count   int  // entries in the file, once counted
+	counted bool // set by the first prune
+}
+
+// New creates a Store with its path resolved from the config.
+func New(cfg *config.HistoryConfig) *Store {
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &Store{Path: path, MaxEntries: cfg.MaxEntries}
+}
+
+// DefaultPath returns the default history file (<data dir>/history.jsonl).
+func DefaultPath() string {
+	return filepath.Join(config.DefaultDataDir(), "history.jsonl")
+}
+
+// Append adds an entry to the end of the history file, creating it if
+// needed. If MaxEntries is set, the oldest entries beyond it are dropped:
+// on the first Append, then in batches once the file holds a tenth more,
+// so the file is not rewritten for every entry.
+func (s *Store) Append(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var line bytes.Buffer
+	if err := encodeEntries(&line, []Entry{e}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err := f.Write(line.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close history: %w", err)
+	}
+
+	if s.MaxEntries <= 0 {
+		return nil
+	}
+	if !s.counted {
+		return s.prune()
+	}
+	s.count++
+	if s.count > s.MaxEntries+pruneSlack(s.MaxEntries) {
+		return s.prune()
+	}
+	return nil
+}
+
+// pruneSlack is how many entries beyond maxEntries the file may hold
+// before it is pruned.
+func pruneSlack(maxEntries int) int {
+	return max(1, maxEntries/10)
+}
+
+// Load returns all entries, oldest first, or the newest MaxEntries if it is
+// set. A missing file yields no entries. Lines that cannot be parsed are
+// skipped.
+func (s *Store) Load() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if s.MaxEntries > 0 && len(entries) > s.MaxEntries {
+		entries = entries[len(entries)-s.MaxEntries:]
+	}
+	return entries, nil
+}
+
+func (s *Store) load() ([]Entry, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan history: %w", err)
+	}
+	return entries, nil
+}
+
+// prune rewrites the file keeping only the newest MaxEntries entries.
+// Must be called with s.mu held.
+func (s *Store) prune() error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	s.count, s.counted = len(entries), true
+	if len(entries) <= s.MaxEntries {
+		return nil
+	}
+	entries = entries[len(entries)-s.MaxEntries:]
+
+	var buf bytes.Buffer
+	if err := encodeEntries(&buf, entries); err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so a crash cannot truncate history.
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".history-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.Path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	s.count = len(entries)
+	return nil
+}
+
+// encodeEntries writes entries to buf as JSON Lines.
+func encodeEntries(buf *bytes.Buffer, entries []Entry) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("marshal history entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Search returns the entries whose raw or pasted text contains query,
+// case-insensitively, preserving order. An empty query matches everything.
+func Search(entries []Entry, query string) []Entry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return entries
+	}
+	var out []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Text), query) || strings.Contains(strings.ToLower(e.Raw), query) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
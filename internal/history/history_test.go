@@ -0,0 +1,209 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+func newTestStore(t *testing.T, maxEntries int) *Store {
+	t.Helper()
+	return New(&config.HistoryConfig{
+		Path:       filepath.Join(t.TempDir(), "sub", "history.jsonl"),
+		MaxEntries: maxEntries,
+	})
+}
+
+func TestNewDefaultPath(t *testing.T) {
+	s := New(&config.HistoryConfig{})
+	if s.Path != DefaultPath() {
+		t.Errorf("expected default path %q, got %q", DefaultPath(), s.Path)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	s := newTestStore(t, 0)
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	s := newTestStore(t, 0)
+	when := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	want := Entry{
+		Time:      when,
+		Raw:       "hello world",
+		Text:      "Hello, world.",
+		Tone:      "formal",
+		Model:     "whisper-1",
+		PostModel: "llama3.2",
+		LatencyMs: 420,
+		PasteMode: "type",
+		Language:  "english",
+		Segments:  []Segment{{Start: 0, End: 1.2, Text: "hello world", AvgLogprob: -0.3}},
+	}
+	if err := s.Append(want); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := s.Append(Entry{Time: when.Add(time.Minute), Raw: "second", Text: "second"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	got := entries[0]
+	if !got.Time.Equal(want.Time) || got.Raw != want.Raw || got.Text != want.Text ||
+		got.Tone != want.Tone || got.Model != want.Model || got.PostModel != want.PostModel ||
+		got.LatencyMs != want.LatencyMs || got.PasteMode != want.PasteMode ||
+		got.Language != want.Language || len(got.Segments) != 1 || got.Segments[0] != want.Segments[0] {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+	if entries[1].Raw != "second" {
+		t.Errorf("expected entries oldest first, got %q last", entries[1].Raw)
+	}
+
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected history file mode 0600, got %o", perm)
+	}
+}
+
+func TestAppendPrunesOldest(t *testing.T) {
+	s := newTestStore(t, 3)
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		if err := s.Append(Entry{Raw: text, Text: text}); err != nil {
+			t.Fatalf("Append(%q): %v", text, err)
+		}
+	}
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries after pruning, got %d", len(entries))
+	}
+	if entries[0].Raw != "three" || entries[2].Raw != "five" {
+		t.Errorf("expected newest three entries, got %q..%q", entries[0].Raw, entries[2].Raw)
+	}
+}
+
+func TestAppendPrunesInBatches(t *testing.T) {
+	s := newTestStore(t, 20)
+	lines := func() int {
+		data, err := os.ReadFile(s.Path)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return strings.Count(string(data), "\n")
+	}
+	for i := range 22 {
+		if err := s.Append(Entry{Raw: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	// Two entries beyond the limit are kept until the next append.
+	if n := lines(); n != 22 {
+		t.Errorf("file has %d entries, want 22 before pruning", n)
+	}
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 20 || entries[0].Raw != "2" {
+		t.Errorf("Load returned %d entries from %q, want the newest 20", len(entries), entries[0].Raw)
+	}
+
+	if err := s.Append(Entry{Raw: "22"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if n := lines(); n != 20 {
+		t.Errorf("file has %d entries, want 20 after pruning", n)
+	}
+}
+
+func TestAppendPrunesOnFirstAppend(t *testing.T) {
+	s := newTestStore(t, 0)
+	for i := range 5 {
+		if err := s.Append(Entry{Raw: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// A later run with a lower limit prunes what is already there.
+	s = &Store{Path: s.Path, MaxEntries: 2}
+	if err := s.Append(Entry{Raw: "5"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("file has %d entries, want 2", n)
+	}
+}
+
+func TestLoadSkipsMalformedLines(t *testing.T) {
+	s := newTestStore(t, 0)
+	if err := s.Append(Entry{Raw: "good"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.WriteString("{not json\n\n")
+	_ = f.Close()
+	if err := s.Append(Entry{Raw: "also good"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	entries, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 valid entries, got %d", len(entries))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := []Entry{
+		{Raw: "send the report", Text: "Send the report."},
+		{Raw: "lunch at noon", Text: "Lunch at noon."},
+		{Raw: "um the quarterly numbers", Text: "The Quarterly Report numbers."},
+	}
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"report", 2},
+		{"REPORT", 2},
+		{"um the", 1},
+		{"dinner", 0},
+	}
+	for _, tt := range tests {
+		if got := Search(entries, tt.query); len(got) != tt.want {
+			t.Errorf("Search(%q) returned %d entries, want %d", tt.query, len(got), tt.want)
+		}
+	}
+}
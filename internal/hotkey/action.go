@@ -0,0 +1,36 @@
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Actions accepted by [[hotkey.binding]] entries.
+const (
+	ActionRecord     = "record"      // record in hotkey.mode, with the binding's tone if set
+	ActionRecordRaw  = "record_raw"  // record without post-processing
+	ActionRecordCopy = "record_copy" // record and copy the result instead of pasting it
+	ActionToggle     = "toggle"      // tap to start, tap again to stop, whatever hotkey.mode is
+	ActionUndo       = "undo"        // delete the last paste
+	ActionCycleTone  = "cycle_tone"  // switch to the next post-processing tone
+)
+
+// ValidateAction returns an error if action is not a known binding action.
+func ValidateAction(action string) error {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case ActionRecord, ActionRecordRaw, ActionRecordCopy, ActionToggle, ActionUndo, ActionCycleTone:
+		return nil
+	default:
+		return fmt.Errorf("unknown hotkey action: %q (valid: record, record_raw, record_copy, toggle, undo, cycle_tone)", action)
+	}
+}
+
+// TakesTone reports whether a binding's tone applies to action: those
+// that record, except record_raw.
+func TakesTone(action string) bool {
+	switch strings.ToLower(strings.TrimSpace(action)) {
+	case ActionRecord, ActionRecordCopy, ActionToggle:
+		return true
+	}
+	return false
+}
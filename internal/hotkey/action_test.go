@@ -0,0 +1,29 @@
+package hotkey
+
+import "testing"
+
+func TestValidateAction(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantErr   bool
+		takesTone bool
+	}{
+		{"record", false, true},
+		{"Record_Copy", false, true},
+		{"record_raw", false, false},
+		{"toggle", false, true},
+		{"undo", false, false},
+		{"cycle_tone", false, false},
+		{"", true, false},
+		{"dictate", true, false},
+	}
+	for _, tt := range tests {
+		err := ValidateAction(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateAction(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got := TakesTone(tt.input); got != tt.takesTone {
+			t.Errorf("TakesTone(%q) = %v, want %v", tt.input, got, tt.takesTone)
+		}
+	}
+}
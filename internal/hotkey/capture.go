@@ -0,0 +1,28 @@
+package hotkey
+
+import "context"
+
+// KeyPress is a key or button seen by a Capture.
+type KeyPress struct {
+	Name   string // evdev name, e.g. "KEY_RIGHTCTRL" or "BTN_SIDE"; "" if it has none
+	Code   uint16
+	Device string // the device it was pressed on
+	// Combo is the hotkey for the keys held on the device, in the order
+	// they were pressed, e.g. "Ctrl+KEY_SPACE".
+	Combo string
+	// Done is set when the last key held is released. Combo is then the
+	// keys held when the last of them was pressed.
+	Done bool
+}
+
+// Capture reports the keys pressed on input devices, to choose a hotkey by
+// pressing it.
+type Capture interface {
+	// Start blocks and calls onKey for each key pressed, and when the keys
+	// held on a device are all released, until the context is cancelled
+	// or Stop is called.
+	Start(ctx context.Context, onKey func(KeyPress)) error
+	Stop()
+	// Devices returns the names of the devices being read.
+	Devices() []string
+}
@@ -0,0 +1,106 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+// linuxCapture reads every input device with keys or buttons via evdev.
+type linuxCapture struct {
+	*inputDevices
+}
+
+// NewCapture returns a Capture that reads every keyboard, mouse, pedal, and
+// other device with keys or buttons, including those plugged in later.
+func NewCapture() (Capture, error) {
+	devs, err := openInputDevices("", func(dev *evdev.InputDevice) bool {
+		return len(dev.CapableEvents(evdev.EV_KEY)) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(devs.Devices()) == 0 {
+		return nil, fmt.Errorf("no input device found in %s/event* (is your user in the input group?)", inputDir)
+	}
+	return &linuxCapture{inputDevices: devs}, nil
+}
+
+// Start blocks and reports the keys pressed on every device until the
+// context is cancelled or Stop is called.
+func (c *linuxCapture) Start(ctx context.Context, onKey func(KeyPress)) error {
+	return c.run(ctx, func(d *inputDevice) deviceReader {
+		return &captureReader{device: d.name, onKey: onKey}
+	})
+}
+
+// captureReader tracks the keys held on one device.
+type captureReader struct {
+	device string
+	onKey  func(KeyPress)
+	held   []evdev.EvCode // in the order they were pressed
+	combo  []evdev.EvCode // held when the last key was pressed
+}
+
+func (r *captureReader) key(code evdev.EvCode, value int32) {
+	name := KeyNameFromCode(code)
+	if ignoredInCapture(name) {
+		return
+	}
+	press := KeyPress{Name: name, Code: uint16(code), Device: r.device}
+	switch value {
+	case 1:
+		if name == "" {
+			// It cannot be configured, but show that it was seen.
+			r.onKey(press)
+			return
+		}
+		if !slices.Contains(r.held, code) {
+			r.held = append(r.held, code)
+		}
+		r.combo = slices.Clone(r.held)
+		press.Combo = comboName(r.combo)
+		r.onKey(press)
+	case 0:
+		i := slices.Index(r.held, code)
+		if i < 0 {
+			return
+		}
+		r.held = slices.Delete(r.held, i, i+1)
+		if len(r.held) == 0 {
+			press.Combo, press.Done = comboName(r.combo), true
+			r.combo = nil
+			r.onKey(press)
+		}
+	}
+}
+
+func (r *captureReader) gone() {
+	r.held, r.combo = nil, nil
+}
+
+// ignoredInCapture reports whether a capture skips a button: touchpad and
+// pen contacts, which fire constantly, and the left and right mouse
+// buttons, which are clicked to focus windows rather than to be bound.
+func ignoredInCapture(name string) bool {
+	return name == "BTN_LEFT" || name == "BTN_RIGHT" || name == "BTN_TOUCH" || strings.HasPrefix(name, "BTN_TOOL_")
+}
+
+// comboName returns the hotkey for keys pressed in order: a single key by
+// its evdev name, or modifiers by their names followed by the last key,
+// such as "Ctrl+Alt+KEY_SPACE".
+func comboName(codes []evdev.EvCode) string {
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = KeyNameFromCode(code)
+		if name := modifierName(code); name != "" && i < len(codes)-1 {
+			parts[i] = name
+		}
+	}
+	return strings.Join(parts, "+")
+}
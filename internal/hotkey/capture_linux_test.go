@@ -0,0 +1,54 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCaptureReader(t *testing.T) {
+	const (
+		leftCtrl  = 29
+		rightCtrl = 97
+		leftAlt   = 56
+		space     = 57
+		a         = 30
+		btnLeft   = 0x110
+		btnSide   = 0x113
+	)
+	tests := []struct {
+		name   string
+		events []keyEvent
+		want   []string // the Combo of each KeyPress, with "!" when Done
+	}{
+		{"single key", []keyEvent{{rightCtrl, 1}, {rightCtrl, 2}, {rightCtrl, 0}}, []string{"KEY_RIGHTCTRL", "KEY_RIGHTCTRL!"}},
+		{"combo", []keyEvent{{leftCtrl, 1}, {leftAlt, 1}, {space, 1}, {space, 0}, {leftAlt, 0}, {leftCtrl, 0}},
+			[]string{"KEY_LEFTCTRL", "Ctrl+KEY_LEFTALT", "Ctrl+Alt+KEY_SPACE", "Ctrl+Alt+KEY_SPACE!"}},
+		{"last combo wins", []keyEvent{{leftCtrl, 1}, {a, 1}, {a, 0}, {space, 1}, {space, 0}, {leftCtrl, 0}},
+			[]string{"KEY_LEFTCTRL", "Ctrl+KEY_A", "Ctrl+KEY_SPACE", "Ctrl+KEY_SPACE!"}},
+		{"mouse button", []keyEvent{{btnSide, 1}, {btnSide, 0}}, []string{"BTN_SIDE", "BTN_SIDE!"}},
+		{"clicks ignored", []keyEvent{{btnLeft, 1}, {btnLeft, 0}}, nil},
+		{"unnamed code", []keyEvent{{0x2f0, 1}, {0x2f0, 0}}, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			r := &captureReader{device: "test", onKey: func(p KeyPress) {
+				if p.Done {
+					if _, err := ParseChord(p.Combo); err != nil {
+						t.Errorf("captured combo does not parse: %v", err)
+					}
+					p.Combo += "!"
+				}
+				got = append(got, p.Combo)
+			}}
+			for _, ev := range tt.events {
+				r.key(ev.code, ev.value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
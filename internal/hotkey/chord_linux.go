@@ -0,0 +1,204 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+// Modifier key codes. A modifier name such as "Ctrl" accepts either side.
+var (
+	ctrlCodes  = []evdev.EvCode{29, 97}   // KEY_LEFTCTRL, KEY_RIGHTCTRL
+	shiftCodes = []evdev.EvCode{42, 54}   // KEY_LEFTSHIFT, KEY_RIGHTSHIFT
+	altCodes   = []evdev.EvCode{56, 100}  // KEY_LEFTALT, KEY_RIGHTALT
+	metaCodes  = []evdev.EvCode{125, 126} // KEY_LEFTMETA, KEY_RIGHTMETA
+)
+
+// modifierGroups maps the modifier names accepted in combos to their key
+// codes. They match the macOS names: Option is Alt and Cmd is Super.
+var modifierGroups = map[string][]evdev.EvCode{
+	"CTRL":    ctrlCodes,
+	"CONTROL": ctrlCodes,
+	"SHIFT":   shiftCodes,
+	"ALT":     altCodes,
+	"OPTION":  altCodes,
+	"CMD":     metaCodes,
+	"COMMAND": metaCodes,
+	"SUPER":   metaCodes,
+	"META":    metaCodes,
+}
+
+// shortKeyNames maps macOS-style key names whose evdev name differs.
+var shortKeyNames = map[string]string{
+	"RETURN": "KEY_ENTER",
+	"ESCAPE": "KEY_ESC",
+}
+
+// isModifier reports whether code is one of the modifier keys.
+func isModifier(code evdev.EvCode) bool {
+	for _, codes := range [][]evdev.EvCode{ctrlCodes, shiftCodes, altCodes, metaCodes} {
+		if slices.Contains(codes, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// modifierName returns the combo name of a modifier key, or "" if code is
+// not one.
+func modifierName(code evdev.EvCode) string {
+	switch {
+	case slices.Contains(ctrlCodes, code):
+		return "Ctrl"
+	case slices.Contains(shiftCodes, code):
+		return "Shift"
+	case slices.Contains(altCodes, code):
+		return "Alt"
+	case slices.Contains(metaCodes, code):
+		return "Super"
+	}
+	return ""
+}
+
+// Chord is a parsed hotkey: keys that must be held together, the last of
+// which triggers it.
+type Chord struct {
+	// groups are the parts of the combo; each is satisfied by any one of
+	// its codes, e.g. left or right Ctrl.
+	groups [][]evdev.EvCode
+	name   string
+}
+
+// ParseChord parses a hotkey such as "KEY_RIGHTCTRL", "Ctrl+Alt+Space", or
+// "KEY_LEFTMETA+KEY_V". Parts are joined by "+" and are modifier names
+// (Ctrl, Shift, Alt or Option, Cmd or Super), evdev KEY_ and BTN_ names, or
+// short names such as "Space" and "F5".
+func ParseChord(combo string) (Chord, error) {
+	combo = strings.TrimSpace(combo)
+	if combo == "" {
+		return Chord{}, fmt.Errorf("empty hotkey")
+	}
+	c := Chord{name: combo}
+	for _, part := range strings.Split(combo, "+") {
+		codes, err := chordPart(part)
+		if err != nil {
+			return Chord{}, fmt.Errorf("hotkey %q: %w", combo, err)
+		}
+		c.groups = append(c.groups, codes)
+	}
+	return c, nil
+}
+
+// chordPart returns the key codes one part of a combo stands for.
+func chordPart(part string) ([]evdev.EvCode, error) {
+	name := strings.ToUpper(strings.TrimSpace(part))
+	if codes, ok := modifierGroups[name]; ok {
+		return codes, nil
+	}
+	if !strings.HasPrefix(name, "KEY_") && !strings.HasPrefix(name, "BTN_") {
+		name = "KEY_" + name
+	}
+	if alias, ok := shortKeyNames[strings.TrimPrefix(name, "KEY_")]; ok {
+		name = alias
+	}
+	code, err := KeyCodeFromName(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown key %q (valid: modifiers Ctrl, Shift, Alt, Cmd, or a key such as Space, F5, KEY_RIGHTCTRL, or BTN_SIDE)", strings.TrimSpace(part))
+	}
+	return []evdev.EvCode{code}, nil
+}
+
+// String returns the combo as configured.
+func (c Chord) String() string {
+	return c.name
+}
+
+// modifierOnly reports whether every part of the chord is a modifier, so
+// it can be pressed in any order.
+func (c Chord) modifierOnly() bool {
+	for _, g := range c.groups {
+		if !slices.ContainsFunc(g, isModifier) {
+			return false
+		}
+	}
+	return true
+}
+
+// chordState tracks the keys held on one device and whether its chord is
+// down.
+type chordState struct {
+	chord   Chord
+	pressed map[evdev.EvCode]bool
+	active  bool
+}
+
+func newChordState(c Chord) *chordState {
+	return &chordState{chord: c, pressed: make(map[evdev.EvCode]bool)}
+}
+
+// handle records a key event (value 1 = press, 0 = release, 2 = repeat)
+// and reports whether the chord went down or up.
+//
+// The chord goes down when its last key is pressed while the others are
+// held, or, for modifier-only chords, when the last of them is pressed.
+// Chords with several parts also require that no other modifier is held,
+// so Ctrl+Space does not fire for Ctrl+Shift+Space. The chord goes up when
+// any of its keys is released.
+func (s *chordState) handle(code evdev.EvCode, value int32) (down, up bool) {
+	switch value {
+	case 1:
+		s.pressed[code] = true
+		if s.active || !s.complete() {
+			return false, false
+		}
+		last := s.chord.groups[len(s.chord.groups)-1]
+		if !slices.Contains(last, code) && !(s.chord.modifierOnly() && s.inChord(code)) {
+			return false, false
+		}
+		if len(s.chord.groups) > 1 && s.extraModifier() {
+			return false, false
+		}
+		s.active = true
+		return true, false
+	case 0:
+		delete(s.pressed, code)
+		if s.active && !s.complete() {
+			s.active = false
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// complete reports whether every part of the chord is held.
+func (s *chordState) complete() bool {
+	for _, g := range s.chord.groups {
+		if !slices.ContainsFunc(g, func(code evdev.EvCode) bool { return s.pressed[code] }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *chordState) inChord(code evdev.EvCode) bool {
+	for _, g := range s.chord.groups {
+		if slices.Contains(g, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraModifier reports whether a modifier outside the chord is held.
+func (s *chordState) extraModifier() bool {
+	for code := range s.pressed {
+		if isModifier(code) && !s.inChord(code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"reflect"
+	"testing"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    [][]evdev.EvCode
+		wantErr bool
+	}{
+		{"KEY_RIGHTCTRL", [][]evdev.EvCode{{97}}, false},
+		{"Ctrl+Alt+Space", [][]evdev.EvCode{ctrlCodes, altCodes, {57}}, false},
+		{"KEY_LEFTMETA+KEY_V", [][]evdev.EvCode{{125}, {47}}, false},
+		{"option+f5", [][]evdev.EvCode{altCodes, {63}}, false},
+		{"Cmd+Option", [][]evdev.EvCode{metaCodes, altCodes}, false},
+		{"Super + Return", [][]evdev.EvCode{metaCodes, {28}}, false},
+		{"Escape", [][]evdev.EvCode{{1}}, false},
+		{"BTN_SIDE", [][]evdev.EvCode{{0x113}}, false},
+		{"Ctrl+BTN_EXTRA", [][]evdev.EvCode{ctrlCodes, {0x114}}, false},
+		{"PlayPause", [][]evdev.EvCode{{164}}, false},
+		{"", nil, true},
+		{"Ctrl+", nil, true},
+		{"Hyper+Space", nil, true},
+		{"Ctrl+KEY_NONEXISTENT", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := ParseChord(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q, got %v", tt.input, c.groups)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(c.groups, tt.want) {
+				t.Errorf("ParseChord(%q) = %v, want %v", tt.input, c.groups, tt.want)
+			}
+		})
+	}
+}
+
+// keyEvent is a key press (1), release (0), or repeat (2).
+type keyEvent struct {
+	code  evdev.EvCode
+	value int32
+}
+
+func TestChordState(t *testing.T) {
+	const (
+		leftCtrl  = 29
+		rightCtrl = 97
+		leftShift = 42
+		leftAlt   = 56
+		space     = 57
+		v         = 47
+		leftMeta  = 125
+	)
+	tests := []struct {
+		name   string
+		combo  string
+		events []keyEvent
+		want   string // "d" for each down, "u" for each up
+	}{
+		{"single key", "KEY_RIGHTCTRL", []keyEvent{{rightCtrl, 1}, {rightCtrl, 2}, {rightCtrl, 0}}, "du"},
+		{"single key ignores modifiers", "KEY_F12", []keyEvent{{leftShift, 1}, {88, 1}, {88, 0}}, "du"},
+		{"other keys ignored", "KEY_RIGHTCTRL", []keyEvent{{leftCtrl, 1}, {leftCtrl, 0}}, ""},
+		{"chord", "Ctrl+Alt+Space", []keyEvent{{leftCtrl, 1}, {leftAlt, 1}, {space, 1}, {space, 0}, {leftAlt, 0}, {leftCtrl, 0}}, "du"},
+		{"either side", "Ctrl+Space", []keyEvent{{rightCtrl, 1}, {space, 1}, {rightCtrl, 0}, {space, 0}}, "du"},
+		{"trigger must be last", "Ctrl+Space", []keyEvent{{space, 1}, {leftCtrl, 1}, {leftCtrl, 0}, {space, 0}}, ""},
+		{"extra modifier", "Ctrl+Space", []keyEvent{{leftCtrl, 1}, {leftShift, 1}, {space, 1}, {space, 0}}, ""},
+		{"missing modifier", "Ctrl+Alt+Space", []keyEvent{{leftCtrl, 1}, {space, 1}, {space, 0}}, ""},
+		{"evdev names", "KEY_LEFTMETA+KEY_V", []keyEvent{{leftMeta, 1}, {v, 1}, {leftMeta, 0}, {v, 0}}, "du"},
+		{"modifier only, any order", "Cmd+Option", []keyEvent{{leftAlt, 1}, {leftMeta, 1}, {leftMeta, 0}, {leftAlt, 0}}, "du"},
+		{"press again", "Ctrl+Space", []keyEvent{{leftCtrl, 1}, {space, 1}, {space, 0}, {space, 1}, {space, 0}}, "dudu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseChord(tt.combo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := newChordState(c)
+			var got string
+			for _, ev := range tt.events {
+				down, up := s.handle(ev.code, ev.value)
+				if down {
+					got += "d"
+				}
+				if up {
+					got += "u"
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+// inputDir holds the evdev device nodes.
+const inputDir = "/dev/input"
+
+// inputDevice is an open device read by inputDevices.
+type inputDevice struct {
+	dev  *evdev.InputDevice
+	name string // e.g. "Keychron K2 (/dev/input/event5)"
+}
+
+// deviceReader handles the key events of one device.
+type deviceReader interface {
+	// key is called for each press (value 1), release (0), or repeat (2).
+	key(code evdev.EvCode, value int32)
+	// gone is called when the device is unplugged.
+	gone()
+}
+
+// inputDevices reads key events from one configured device, or from every
+// device accept approves. It watches /dev/input so that devices plugged in
+// later are read too and unplugged ones are dropped.
+type inputDevices struct {
+	devicePath string // "" = every device accept approves
+	accept     func(dev *evdev.InputDevice) bool
+	onDevices  func(devices []string)
+
+	mu      sync.Mutex
+	devices map[string]*inputDevice // by event node path
+	watch   *os.File                // inotify on /dev/input; nil if unavailable
+	closed  bool
+	changed chan struct{} // signals a change of devices to onDevices
+	done    chan struct{} // closed by Stop
+
+	readMu sync.Mutex // deliver calls readers one at a time
+}
+
+// openInputDevices opens the device at devicePath, or every device accept
+// approves if devicePath is empty. Only a configured device that cannot be
+// opened is an error; devices that appear later are picked up by run.
+func openInputDevices(devicePath string, accept func(dev *evdev.InputDevice) bool) (*inputDevices, error) {
+	s := &inputDevices{
+		devicePath: devicePath,
+		accept:     accept,
+		devices:    make(map[string]*inputDevice),
+		changed:    make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	if devicePath != "" {
+		path, err := filepath.EvalSymlinks(devicePath)
+		if err != nil {
+			return nil, fmt.Errorf("open device %s: %w", devicePath, err)
+		}
+		dev, err := evdev.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open device %s: %w", devicePath, err)
+		}
+		s.add(path, dev)
+		return s, nil
+	}
+	s.scan()
+	return s, nil
+}
+
+// NotifyDevices calls fn with the devices being read whenever they change.
+// It must be called before Start.
+func (s *inputDevices) NotifyDevices(fn func(devices []string)) {
+	s.onDevices = fn
+}
+
+// Devices returns the names of the devices being read, sorted.
+func (s *inputDevices) Devices() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.devices))
+	for _, d := range s.devices {
+		names = append(names, d.name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// run reads every device, passing its events to the reader newReader
+// returns for it, until the context is cancelled or Stop is called.
+// Readers are called one at a time.
+func (s *inputDevices) run(ctx context.Context, newReader func(d *inputDevice) deviceReader) error {
+	s.mu.Lock()
+	for path, d := range s.devices {
+		go s.read(path, d, newReader(d))
+	}
+	s.mu.Unlock()
+
+	if s.onDevices != nil {
+		go func() {
+			for {
+				select {
+				case <-s.changed:
+					s.onDevices(s.Devices())
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	}
+
+	// Without inotify, the devices open now are read until they go away.
+	// A configured device may be a symlink such as /dev/input/by-id/...,
+	// which is replaced when the device reconnects.
+	dirs := []string{inputDir}
+	if dir := filepath.Dir(s.devicePath); s.devicePath != "" && dir != inputDir {
+		dirs = append(dirs, dir)
+	}
+	if watch, err := watchDirs(dirs); err == nil {
+		s.mu.Lock()
+		if s.closed {
+			_ = watch.Close()
+		} else {
+			s.watch = watch
+			go s.follow(watch, newReader)
+		}
+		s.mu.Unlock()
+	}
+
+	select {
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	case <-s.done:
+		return nil
+	}
+}
+
+// read delivers a device's key events until it fails, typically because it
+// was unplugged or reading stopped.
+func (s *inputDevices) read(path string, d *inputDevice, r deviceReader) {
+	for {
+		ev, err := d.dev.ReadOne()
+		if err != nil {
+			s.remove(path, d)
+			s.deliver(r.gone)
+			return
+		}
+		if ev.Type != evdev.EV_KEY {
+			continue
+		}
+		s.deliver(func() { r.key(ev.Code, ev.Value) })
+	}
+}
+
+// deliver calls f unless reading has stopped.
+func (s *inputDevices) deliver(f func()) {
+	s.readMu.Lock()
+	defer s.readMu.Unlock()
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if !closed {
+		f()
+	}
+}
+
+// follow rescans the devices whenever /dev/input changes, and starts
+// reading new ones.
+func (s *inputDevices) follow(watch *os.File, newReader func(d *inputDevice) deviceReader) {
+	buf := make([]byte, 4096)
+	for {
+		if _, err := watch.Read(buf); err != nil {
+			return
+		}
+		for path, d := range s.scan() {
+			go s.read(path, d, newReader(d))
+		}
+	}
+}
+
+// scan opens the devices that are not open yet, and returns them.
+func (s *inputDevices) scan() map[string]*inputDevice {
+	added := make(map[string]*inputDevice)
+	for _, path := range s.candidates() {
+		s.mu.Lock()
+		_, open := s.devices[path]
+		s.mu.Unlock()
+		if open {
+			continue
+		}
+		// New nodes can be unreadable until udev has set their group.
+		// inotify reports that change too, and the open is retried.
+		dev, err := evdev.Open(path)
+		if err != nil {
+			continue
+		}
+		if s.devicePath == "" && !s.accept(dev) {
+			_ = dev.Close()
+			continue
+		}
+		if d := s.add(path, dev); d != nil {
+			added[path] = d
+		}
+	}
+	return added
+}
+
+// candidates returns the device nodes scan may open: the configured
+// device, following symlinks such as /dev/input/by-id/..., or every
+// event node.
+func (s *inputDevices) candidates() []string {
+	if s.devicePath != "" {
+		path, err := filepath.EvalSymlinks(s.devicePath)
+		if err != nil {
+			return nil
+		}
+		return []string{path}
+	}
+	return eventNodes()
+}
+
+// add records an opened device, or closes it if reading has stopped.
+func (s *inputDevices) add(path string, dev *evdev.InputDevice) *inputDevice {
+	name, err := dev.Name()
+	if err != nil || name == "" {
+		name = "unknown device"
+	}
+	d := &inputDevice{dev: dev, name: fmt.Sprintf("%s (%s)", name, path)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		_ = dev.Close()
+		return nil
+	}
+	s.devices[path] = d
+	s.notifyLocked()
+	return d
+}
+
+// remove forgets a device that failed and closes it.
+func (s *inputDevices) remove(path string, d *inputDevice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = d.dev.Close()
+	if s.devices[path] != d {
+		return
+	}
+	delete(s.devices, path)
+	if !s.closed {
+		s.notifyLocked()
+	}
+}
+
+// notifyLocked signals a change of devices. Changes made while onDevices
+// is busy are coalesced, and it is always passed the current devices.
+func (s *inputDevices) notifyLocked() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Stop closes the devices and the /dev/input watch, and makes run return.
+func (s *inputDevices) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, d := range s.devices {
+		_ = d.dev.Close()
+	}
+	if s.watch != nil {
+		_ = s.watch.Close()
+	}
+	close(s.done)
+}
+
+// watchDirs returns an inotify file that becomes readable when entries
+// are added to or removed from dirs, or change permissions. It is
+// non-blocking, so closing it stops a pending Read. Only the first
+// directory must exist.
+func watchDirs(dirs []string) (*os.File, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	for i, dir := range dirs {
+		if _, err := syscall.InotifyAddWatch(fd, dir, syscall.IN_CREATE|syscall.IN_DELETE|syscall.IN_ATTRIB); err != nil && i == 0 {
+			_ = syscall.Close(fd)
+			return nil, fmt.Errorf("watch %s: %w", dir, err)
+		}
+	}
+	f := os.NewFile(uintptr(fd), "inotify")
+	if f == nil {
+		return nil, errors.New("inotify: invalid file descriptor")
+	}
+	return f, nil
+}
+
+// eventNodes returns /dev/input/event*, sorted numerically so event7 comes
+// before event10.
+func eventNodes() []string {
+	matches, _ := filepath.Glob(inputDir + "/event*")
+	sort.Slice(matches, func(i, j int) bool {
+		ni, _ := strconv.Atoi(strings.TrimPrefix(matches[i], inputDir+"/event"))
+		nj, _ := strconv.Atoi(strings.TrimPrefix(matches[j], inputDir+"/event"))
+		return ni < nj
+	})
+	return matches
+}
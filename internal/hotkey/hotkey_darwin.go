@@ -105,13 +105,27 @@ const (
 	KeyNone   Key = 0xFFFF // sentinel for modifier-only hotkeys
 )
 
-// modifierMap maps modifier name strings to Modifier values.
+// modifierMap maps modifier name strings to Modifier values. The evdev
+// names of modifier keys are accepted too, so combos written for Linux
+// such as "KEY_LEFTMETA+KEY_V" work unchanged.
 var modifierMap = map[string]Modifier{
-	"OPTION": ModOption,
-	"ALT":    ModOption,
-	"CTRL":   ModCtrl,
-	"SHIFT":  ModShift,
-	"CMD":    ModCmd,
+	"OPTION":         ModOption,
+	"ALT":            ModOption,
+	"CTRL":           ModCtrl,
+	"CONTROL":        ModCtrl,
+	"SHIFT":          ModShift,
+	"CMD":            ModCmd,
+	"COMMAND":        ModCmd,
+	"SUPER":          ModCmd,
+	"META":           ModCmd,
+	"KEY_LEFTALT":    ModOption,
+	"KEY_RIGHTALT":   ModOption,
+	"KEY_LEFTCTRL":   ModCtrl,
+	"KEY_RIGHTCTRL":  ModCtrl,
+	"KEY_LEFTSHIFT":  ModShift,
+	"KEY_RIGHTSHIFT": ModShift,
+	"KEY_LEFTMETA":   ModCmd,
+	"KEY_RIGHTMETA":  ModCmd,
 }
 
 // keyMap maps key name strings to Key values.
@@ -256,6 +270,8 @@ var evdevKeyMap = map[string]Key{
 // ParseHotkeyCombo parses a hotkey combo string like "Option+Space" or "Ctrl+F5"
 // into modifiers, a key, and a display name. Also handles evdev-style "KEY_F12"
 // for cross-platform config compatibility (mapped as bare key with no modifiers).
+// Combos may use evdev names for their parts too, as on Linux: "KEY_LEFTMETA+KEY_V"
+// is Cmd+V.
 func ParseHotkeyCombo(combo string) ([]Modifier, Key, string, error) {
 	combo = strings.TrimSpace(combo)
 	if combo == "" {
@@ -265,7 +281,7 @@ func ParseHotkeyCombo(combo string) ([]Modifier, Key, string, error) {
 	upper := strings.ToUpper(combo)
 
 	// Handle evdev-style KEY_ names (bare key, no modifiers — use Option as default modifier)
-	if strings.HasPrefix(upper, "KEY_") {
+	if strings.HasPrefix(upper, "KEY_") && !strings.Contains(upper, "+") {
 		key, ok := evdevKeyMap[upper]
 		if !ok {
 			return nil, 0, "", fmt.Errorf("unknown evdev key: %s (on macOS, use modifier+key combos like Option+Space)", combo)
@@ -287,7 +303,7 @@ func ParseHotkeyCombo(combo string) ([]Modifier, Key, string, error) {
 			part = strings.TrimSpace(part)
 			mod, ok := modifierMap[strings.ToUpper(part)]
 			if !ok {
-				return nil, 0, "", fmt.Errorf("unknown modifier: %s (valid: Option, Alt, Ctrl, Shift, Cmd)", part)
+				return nil, 0, "", fmt.Errorf("unknown modifier: %s (valid: Option, Alt, Ctrl, Shift, Cmd, Super)", part)
 			}
 			mods = append(mods, mod)
 		}
@@ -300,12 +316,15 @@ func ParseHotkeyCombo(combo string) ([]Modifier, Key, string, error) {
 		part = strings.TrimSpace(part)
 		mod, ok := modifierMap[strings.ToUpper(part)]
 		if !ok {
-			return nil, 0, "", fmt.Errorf("unknown modifier: %s (valid: Option, Alt, Ctrl, Shift, Cmd)", part)
+			return nil, 0, "", fmt.Errorf("unknown modifier: %s (valid: Option, Alt, Ctrl, Shift, Cmd, Super)", part)
 		}
 		mods = append(mods, mod)
 	}
 
 	key, ok := keyMap[strings.ToUpper(lastPart)]
+	if !ok {
+		key, ok = evdevKeyMap[strings.ToUpper(lastPart)]
+	}
 	if !ok {
 		return nil, 0, "", fmt.Errorf("unknown key: %s", lastPart)
 	}
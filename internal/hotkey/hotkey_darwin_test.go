@@ -26,7 +26,10 @@ func TestParseHotkeyCombo(t *testing.T) {
 		{"evdev space", "KEY_SPACE", []Modifier{ModOption}, KeySpace, false},
 		{"empty", "", nil, 0, true},
 		{"no modifier", "Space", nil, 0, true},
-		{"unknown modifier", "Super+Space", nil, 0, true},
+		{"super is cmd", "Super+Space", []Modifier{ModCmd}, KeySpace, false},
+		{"evdev combo", "KEY_LEFTMETA+KEY_V", []Modifier{ModCmd}, KeyV, false},
+		{"evdev modifiers", "KEY_LEFTCTRL+KEY_LEFTALT", []Modifier{ModCtrl, ModOption}, KeyNone, false},
+		{"unknown modifier", "Hyper+Space", nil, 0, true},
 		{"unknown key", "Option+Unknown", nil, 0, true},
 		{"unknown evdev", "KEY_NONEXISTENT", nil, 0, true},
 	}
@@ -21,6 +21,11 @@ func TestKeyCodeFromName(t *testing.T) {
 		{"left alt", "KEY_LEFTALT", 56, false},
 		{"case insensitive", "key_rightctrl", 97, false},
 		{"with whitespace", "  KEY_F12  ", 88, false},
+		{"media key", "KEY_PLAYPAUSE", 164, false},
+		{"keypad", "KEY_KPENTER", 96, false},
+		{"compose", "KEY_COMPOSE", 127, false},
+		{"mouse side button", "BTN_SIDE", 0x113, false},
+		{"alias", "BTN_LEFT", 0x110, false},
 		{"unknown key", "KEY_NONEXISTENT", 0, true},
 		{"empty string", "", 0, true},
 	}
@@ -44,3 +49,28 @@ func TestKeyCodeFromName(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyNameFromCode(t *testing.T) {
+	tests := []struct {
+		code evdev.EvCode
+		want string
+	}{
+		{97, "KEY_RIGHTCTRL"},
+		{164, "KEY_PLAYPAUSE"},
+		{0x110, "BTN_LEFT"},
+		{0x113, "BTN_SIDE"},
+		{0x2f0, ""},
+	}
+	for _, tt := range tests {
+		if got := KeyNameFromCode(tt.code); got != tt.want {
+			t.Errorf("KeyNameFromCode(%d) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+	// Every name must map back to its code.
+	for code := range evdev.KEYToString {
+		name := KeyNameFromCode(code)
+		if got, err := KeyCodeFromName(name); err != nil || got != code {
+			t.Errorf("KeyCodeFromName(%q) = %d, %v, want %d", name, got, err, code)
+		}
+	}
+}
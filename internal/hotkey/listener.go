@@ -8,3 +8,13 @@ type Listener interface {
 	Stop()
 	KeyName() string
 }
+
+// DeviceWatcher is implemented by listeners that read several input
+// devices and follow them being plugged in and out.
+type DeviceWatcher interface {
+	// Devices returns the names of the devices being read.
+	Devices() []string
+	// NotifyDevices calls fn with the devices whenever they change. It
+	// must be called before Start.
+	NotifyDevices(fn func(devices []string))
+}
@@ -0,0 +1,202 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+// listenerGroup reads the input devices once for several chords, so that
+// hotkey.key and every binding share the open devices and the /dev/input
+// watch. Keyboards, mice, and pedals plugged in later are read too.
+type listenerGroup struct {
+	*inputDevices
+	chords []Chord
+
+	mu       sync.Mutex
+	handlers []chordHandlers // by chord; set while its listener runs
+	started  bool
+	running  int // listeners started and not stopped
+}
+
+// chordHandlers are the callbacks of a started listener.
+type chordHandlers struct {
+	onDown func()
+	onUp   func()
+}
+
+// linuxListener listens for one chord of a listenerGroup via evdev.
+type linuxListener struct {
+	*listenerGroup
+	index int
+
+	once sync.Once
+	done chan struct{} // closed by Stop
+}
+
+// NewListener creates a Listener for chord on the device at devicePath, or
+// on every device that can press it if devicePath is empty. It fails if no
+// such device can be opened now.
+func NewListener(devicePath string, chord Chord) (Listener, error) {
+	ls, err := NewListeners(devicePath, []Chord{chord})
+	if err != nil {
+		return nil, err
+	}
+	return ls[0], nil
+}
+
+// NewListeners creates a Listener for each chord, in order. The devices
+// are opened once and their events are passed to every chord: the device
+// at devicePath, or every device that can press one of the chords if
+// devicePath is empty. It fails if a chord has no such device now.
+//
+// The devices are read from the first Start until every started listener
+// has returned, and are then closed.
+func NewListeners(devicePath string, chords []Chord) ([]Listener, error) {
+	devs, err := openInputDevices(devicePath, func(dev *evdev.InputDevice) bool {
+		return slices.ContainsFunc(chords, func(c Chord) bool { return hasKeys(dev, c.groups) })
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chords {
+		if !devs.canPress(c) {
+			devs.Stop()
+			return nil, fmt.Errorf("no input device with the keys of %s found in %s/event* (is your user in the input group?)", c, inputDir)
+		}
+	}
+
+	g := &listenerGroup{inputDevices: devs, chords: chords, handlers: make([]chordHandlers, len(chords))}
+	ls := make([]Listener, len(chords))
+	for i := range chords {
+		ls[i] = &linuxListener{listenerGroup: g, index: i, done: make(chan struct{})}
+	}
+	return ls, nil
+}
+
+// canPress reports whether an open device can press c. A configured
+// device is used whatever its keys.
+func (s *inputDevices) canPress(c Chord) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.devicePath != "" {
+		return len(s.devices) > 0
+	}
+	for _, d := range s.devices {
+		if hasKeys(d.dev, c.groups) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start blocks and calls onDown when the chord is pressed and onUp when it
+// is released. Each device tracks its own keys, so a chord must be pressed
+// on a single device. Start returns when the context is cancelled or Stop
+// is called.
+func (l *linuxListener) Start(ctx context.Context, onDown func(), onUp func()) error {
+	g := l.listenerGroup
+	g.mu.Lock()
+	g.handlers[l.index] = chordHandlers{onDown: onDown, onUp: onUp}
+	g.running++
+	first := !g.started
+	g.started = true
+	g.mu.Unlock()
+
+	if first {
+		go func() { _ = g.run(context.Background(), g.newReader) }()
+	}
+
+	defer l.release()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.done:
+		return nil
+	case <-g.inputDevices.done:
+		return nil
+	}
+}
+
+// Stop makes Start return. The devices are closed once no listener of the
+// group is running.
+func (l *linuxListener) Stop() {
+	l.once.Do(func() { close(l.done) })
+}
+
+// release drops the listener's handlers, and closes the devices if it was
+// the last one running.
+func (l *linuxListener) release() {
+	g := l.listenerGroup
+	g.mu.Lock()
+	g.handlers[l.index] = chordHandlers{}
+	g.running--
+	last := g.running == 0
+	g.mu.Unlock()
+	if last {
+		g.inputDevices.Stop()
+	}
+}
+
+// KeyName returns the configured hotkey string.
+func (l *linuxListener) KeyName() string {
+	return l.chords[l.index].String()
+}
+
+// newReader returns a reader that tracks every chord of the group on one
+// device.
+func (g *listenerGroup) newReader(*inputDevice) deviceReader {
+	r := &chordReader{group: g, states: make([]*chordState, len(g.chords))}
+	for i, c := range g.chords {
+		r.states[i] = newChordState(c)
+	}
+	return r
+}
+
+// handler returns the callbacks of chord i, which are nil until its
+// listener is started.
+func (g *listenerGroup) handler(i int) chordHandlers {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.handlers[i]
+}
+
+// chordReader turns one device's key events into presses of the group's
+// chords.
+type chordReader struct {
+	group  *listenerGroup
+	states []*chordState // by chord
+}
+
+func (r *chordReader) key(code evdev.EvCode, value int32) {
+	for i, s := range r.states {
+		switch down, up := s.handle(code, value); {
+		case down:
+			if h := r.group.handler(i); h.onDown != nil {
+				h.onDown()
+			}
+		case up:
+			if h := r.group.handler(i); h.onUp != nil {
+				h.onUp()
+			}
+		}
+	}
+}
+
+// gone releases the chords held on the device when it was unplugged.
+func (r *chordReader) gone() {
+	for i, s := range r.states {
+		if !s.active {
+			continue
+		}
+		s.active = false
+		if h := r.group.handler(i); h.onUp != nil {
+			h.onUp()
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"slices"
+	"testing"
+
+	evdev "github.com/holoplot/go-evdev"
+)
+
+func TestNewListenerMissingDevice(t *testing.T) {
+	c, err := ParseChord("KEY_RIGHTCTRL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewListener("/dev/input/by-id/no-such-keyboard", c); err == nil {
+		t.Error("expected an error for a missing device")
+	}
+}
+
+func TestChordReaderSharesDevice(t *testing.T) {
+	var chords []Chord
+	for _, key := range []string{"KEY_RIGHTCTRL", "Ctrl+KEY_Z"} {
+		c, err := ParseChord(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chords = append(chords, c)
+	}
+	g := &listenerGroup{chords: chords, handlers: make([]chordHandlers, len(chords))}
+	var events []string
+	for i, name := range []string{"ctrl", "undo"} {
+		g.handlers[i] = chordHandlers{
+			onDown: func() { events = append(events, name+" down") },
+			onUp:   func() { events = append(events, name+" up") },
+		}
+	}
+
+	r := g.newReader(nil)
+	r.key(evdev.KEY_RIGHTCTRL, 1)
+	r.key(evdev.KEY_RIGHTCTRL, 0)
+	r.key(evdev.KEY_LEFTCTRL, 1)
+	r.key(evdev.KEY_Z, 1)
+	r.gone()
+
+	want := []string{"ctrl down", "ctrl up", "undo down", "undo up"}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
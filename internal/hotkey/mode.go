@@ -0,0 +1,199 @@
+package hotkey
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recording modes accepted by the hotkey.mode config option.
+const (
+	ModeHold   = "hold"   // push-to-talk: record while the key is held
+	ModeToggle = "toggle" // tap to start, tap again to stop
+	ModeHybrid = "hybrid" // short tap latches recording, long hold is push-to-talk
+)
+
+// ValidateMode returns an error if mode is not a known recording mode.
+// An empty mode is treated as ModeHold.
+func ValidateMode(mode string) error {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", ModeHold, ModeToggle, ModeHybrid:
+		return nil
+	default:
+		return fmt.Errorf("unknown hotkey mode: %s (valid: hold, toggle, hybrid)", mode)
+	}
+}
+
+// Gate sits between a Listener and the recorder. It turns raw key down/up
+// events into start/stop calls according to the configured recording mode.
+// All methods are safe to call from the listener goroutine.
+type Gate struct {
+	mode          string
+	holdThreshold time.Duration
+	onStart       func() error
+	onStop        func()
+	onLatch       func()
+	now           func() time.Time
+
+	mu        sync.Mutex
+	active    bool      // a recording was started and not yet stopped
+	latched   bool      // hybrid mode: a short tap latched the recording on
+	pressedAt time.Time // when the key went down for the current press
+	stopPress bool      // the current press stopped a latched recording
+}
+
+// NewGate creates a Gate for the given mode. holdThreshold is only used in
+// hybrid mode: presses shorter than it latch recording on, longer presses
+// behave like push-to-talk. onStart is called to begin recording; if it
+// returns an error the gate stays idle. onLatch may be nil.
+func NewGate(mode string, holdThreshold time.Duration, onStart func() error, onStop func(), onLatch func()) (*Gate, error) {
+	if err := ValidateMode(mode); err != nil {
+		return nil, err
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = ModeHold
+	}
+	return &Gate{
+		mode:          mode,
+		holdThreshold: holdThreshold,
+		onStart:       onStart,
+		onStop:        onStop,
+		onLatch:       onLatch,
+		now:           time.Now,
+	}, nil
+}
+
+// Mode returns the normalized recording mode.
+func (g *Gate) Mode() string {
+	return g.mode
+}
+
+// Down handles a hotkey press.
+func (g *Gate) Down() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.mode {
+	case ModeToggle:
+		if g.active {
+			g.stop()
+			return
+		}
+		g.start()
+	case ModeHybrid:
+		if g.active && g.latched {
+			// Tap while latched: stop now and swallow the matching release.
+			g.stopPress = true
+			g.stop()
+			return
+		}
+		g.pressedAt = g.now()
+		g.start()
+	default:
+		g.start()
+	}
+}
+
+// Up handles a hotkey release.
+func (g *Gate) Up() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.mode {
+	case ModeToggle:
+		// Releases are ignored; the next press stops recording.
+	case ModeHybrid:
+		if g.stopPress {
+			g.stopPress = false
+			return
+		}
+		if !g.active {
+			return
+		}
+		if g.now().Sub(g.pressedAt) < g.holdThreshold {
+			g.latched = true
+			if g.onLatch != nil {
+				g.onLatch()
+			}
+			return
+		}
+		g.stop()
+	default:
+		if g.active {
+			g.stop()
+		}
+	}
+}
+
+// Start begins recording without a key press, e.g. from the control API.
+// The recording behaves as if latched: it continues until Stop, Toggle, or
+// the next hotkey tap. Starting while already recording does nothing.
+func (g *Gate) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.active {
+		return nil
+	}
+	if g.onStart != nil {
+		if err := g.onStart(); err != nil {
+			return err
+		}
+	}
+	g.active = true
+	g.latched = true
+	if g.onLatch != nil {
+		g.onLatch()
+	}
+	return nil
+}
+
+// Stop ends the current recording, if any, and reports whether one was
+// active.
+func (g *Gate) Stop() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.active {
+		return false
+	}
+	g.stop()
+	return true
+}
+
+// Toggle stops an active recording or starts a new one.
+func (g *Gate) Toggle() error {
+	if g.Stop() {
+		return nil
+	}
+	return g.Start()
+}
+
+// Active reports whether a recording is in progress.
+func (g *Gate) Active() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active
+}
+
+// start calls onStart and marks the gate active on success.
+// Must be called with g.mu held.
+func (g *Gate) start() {
+	if g.active {
+		return
+	}
+	if g.onStart != nil && g.onStart() != nil {
+		return
+	}
+	g.active = true
+	g.latched = false
+}
+
+// stop calls onStop and resets the gate. Must be called with g.mu held.
+func (g *Gate) stop() {
+	g.active = false
+	g.latched = false
+	if g.onStop != nil {
+		g.onStop()
+	}
+}
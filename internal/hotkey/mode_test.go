@@ -0,0 +1,207 @@
+package hotkey
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// gateRecorder counts the callbacks a Gate makes.
+type gateRecorder struct {
+	starts   int
+	stops    int
+	latches  int
+	startErr error
+}
+
+func newTestGate(t *testing.T, mode string, rec *gateRecorder) (*Gate, *time.Time) {
+	t.Helper()
+	g, err := NewGate(mode, 300*time.Millisecond,
+		func() error {
+			if rec.startErr != nil {
+				return rec.startErr
+			}
+			rec.starts++
+			return nil
+		},
+		func() { rec.stops++ },
+		func() { rec.latches++ },
+	)
+	if err != nil {
+		t.Fatalf("NewGate(%q): %v", mode, err)
+	}
+	now := time.Unix(0, 0)
+	g.now = func() time.Time { return now }
+	return g, &now
+}
+
+func TestValidateMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"", false},
+		{"hold", false},
+		{"toggle", false},
+		{"hybrid", false},
+		{"Toggle", false},
+		{"latch", true},
+	}
+	for _, tt := range tests {
+		err := ValidateMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewGateDefaultsToHold(t *testing.T) {
+	g, err := NewGate("", 0, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Mode() != ModeHold {
+		t.Errorf("expected mode %q, got %q", ModeHold, g.Mode())
+	}
+}
+
+func TestGateHold(t *testing.T) {
+	rec := &gateRecorder{}
+	g, _ := newTestGate(t, ModeHold, rec)
+
+	g.Down()
+	if rec.starts != 1 {
+		t.Fatalf("expected 1 start after press, got %d", rec.starts)
+	}
+	g.Up()
+	if rec.stops != 1 {
+		t.Fatalf("expected 1 stop after release, got %d", rec.stops)
+	}
+}
+
+func TestGateToggle(t *testing.T) {
+	rec := &gateRecorder{}
+	g, _ := newTestGate(t, ModeToggle, rec)
+
+	g.Down()
+	g.Up()
+	if rec.starts != 1 || rec.stops != 0 {
+		t.Fatalf("after first tap: starts=%d stops=%d, want 1/0", rec.starts, rec.stops)
+	}
+	g.Down()
+	g.Up()
+	if rec.starts != 1 || rec.stops != 1 {
+		t.Fatalf("after second tap: starts=%d stops=%d, want 1/1", rec.starts, rec.stops)
+	}
+}
+
+func TestGateToggleStartFailureStaysIdle(t *testing.T) {
+	rec := &gateRecorder{startErr: errors.New("no mic")}
+	g, _ := newTestGate(t, ModeToggle, rec)
+
+	g.Down()
+	g.Up()
+	rec.startErr = nil
+	g.Down()
+	if rec.stops != 0 {
+		t.Errorf("expected no stop after failed start, got %d", rec.stops)
+	}
+	if rec.starts != 1 {
+		t.Errorf("expected the next tap to start recording, got %d starts", rec.starts)
+	}
+}
+
+func TestGateHybridShortTapLatches(t *testing.T) {
+	rec := &gateRecorder{}
+	g, now := newTestGate(t, ModeHybrid, rec)
+
+	g.Down()
+	*now = now.Add(100 * time.Millisecond)
+	g.Up()
+	if rec.stops != 0 {
+		t.Fatalf("expected short tap to latch, got %d stops", rec.stops)
+	}
+	if rec.latches != 1 {
+		t.Fatalf("expected 1 latch, got %d", rec.latches)
+	}
+
+	// Tap again to stop; the release must not start anything.
+	*now = now.Add(5 * time.Second)
+	g.Down()
+	g.Up()
+	if rec.stops != 1 {
+		t.Errorf("expected 1 stop after second tap, got %d", rec.stops)
+	}
+	if rec.starts != 1 {
+		t.Errorf("expected exactly 1 start, got %d", rec.starts)
+	}
+}
+
+func TestGateHybridLongHoldIsPushToTalk(t *testing.T) {
+	rec := &gateRecorder{}
+	g, now := newTestGate(t, ModeHybrid, rec)
+
+	g.Down()
+	*now = now.Add(2 * time.Second)
+	g.Up()
+	if rec.stops != 1 {
+		t.Errorf("expected long hold to stop on release, got %d stops", rec.stops)
+	}
+	if rec.latches != 0 {
+		t.Errorf("expected no latch on long hold, got %d", rec.latches)
+	}
+}
+
+func TestGateRemoteStartStop(t *testing.T) {
+	for _, mode := range []string{ModeHold, ModeToggle, ModeHybrid} {
+		rec := &gateRecorder{}
+		g, _ := newTestGate(t, mode, rec)
+
+		if err := g.Toggle(); err != nil {
+			t.Fatalf("%s: Toggle: %v", mode, err)
+		}
+		if rec.starts != 1 || rec.latches != 1 || !g.Active() {
+			t.Fatalf("%s: expected a latched start, got %+v", mode, rec)
+		}
+		if err := g.Start(); err != nil || rec.starts != 1 {
+			t.Errorf("%s: expected Start while active to do nothing", mode)
+		}
+		if err := g.Toggle(); err != nil {
+			t.Fatalf("%s: Toggle: %v", mode, err)
+		}
+		if rec.stops != 1 || g.Active() {
+			t.Errorf("%s: expected second Toggle to stop, got %+v", mode, rec)
+		}
+		if g.Stop() {
+			t.Errorf("%s: expected Stop while idle to report false", mode)
+		}
+	}
+}
+
+func TestGateRemoteStartStoppedByHybridTap(t *testing.T) {
+	rec := &gateRecorder{}
+	g, _ := newTestGate(t, ModeHybrid, rec)
+
+	if err := g.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	g.Down()
+	g.Up()
+	if rec.stops != 1 || g.Active() {
+		t.Errorf("expected a tap to stop a remotely started recording, got %+v", rec)
+	}
+	if rec.starts != 1 {
+		t.Errorf("expected the release to be swallowed, got %d starts", rec.starts)
+	}
+}
+
+func TestGateRemoteStartFailure(t *testing.T) {
+	rec := &gateRecorder{startErr: errors.New("no mic")}
+	g, _ := newTestGate(t, ModeToggle, rec)
+	if err := g.Start(); err == nil {
+		t.Fatal("expected start error")
+	}
+	if g.Active() || rec.latches != 0 {
+		t.Errorf("expected gate idle after failed start, got %+v", rec)
+	}
+}
@@ -0,0 +1,952 @@
+// Package pipeline implements the record → transcribe → rewrite → paste
+// state machine shared by the TUI and the headless daemon. Inputs (hotkey,
+// recorder, streaming transcriber) call its methods; front ends observe it
+// through Subscribe.
+package pipeline
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Danondso/palaver/internal/chime"
+	"github.com/Danondso/palaver/internal/clipboard"
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/dictation"
+	"github.com/Danondso/palaver/internal/history"
+	"github.com/Danondso/palaver/internal/postprocess"
+	"github.com/Danondso/palaver/internal/profile"
+	"github.com/Danondso/palaver/internal/transcriber"
+	"github.com/Danondso/palaver/internal/vocabulary"
+)
+
+// State is the pipeline's current stage.
+type State int
+
+const (
+	StateIdle State = iota
+	StateRecording
+	StateTranscribing
+	StatePostProcessing
+	StatePasting
+	StateError
+)
+
+var stateNames = [...]string{"idle", "recording", "transcribing", "post_processing", "pasting", "error"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+	return stateNames[s]
+}
+
+// MarshalText encodes the state by name so JSON logs and status files are
+// readable.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a state name written by MarshalText.
+func (s *State) UnmarshalText(text []byte) error {
+	for i, name := range stateNames {
+		if name == string(text) {
+			*s = State(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown state %q", text)
+}
+
+// ErrBusy is returned by Repaste when the pipeline is not idle.
+var ErrBusy = errors.New("pipeline busy")
+
+// ErrNoPaster is returned when pasting before SetPaster has been called.
+var ErrNoPaster = errors.New("no paste backend set")
+
+// ErrNothingToUndo is returned by Undo when there is no paste to undo.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrFocusChanged is returned by Undo when another window has been focused
+// since the paste, so backspaces would delete the wrong text.
+var ErrFocusChanged = errors.New("focus changed since the paste")
+
+// ErrUnknownTone is returned by SetPostProcessing for unregistered tones.
+var ErrUnknownTone = errors.New("unknown tone")
+
+// errorTimeout is how long the error state is shown before returning to idle.
+const errorTimeout = 5 * time.Second
+
+// Status is a snapshot of the pipeline.
+type Status struct {
+	State          State  `json:"state"`
+	Latched        bool   `json:"latched"` // hybrid mode: recording continues after release
+	Armed          bool   `json:"armed"`   // hands-free listening is active
+	LastTranscript string `json:"last_transcript,omitempty"`
+	Partial        string `json:"partial,omitempty"` // live text while streaming
+	LastError      string `json:"last_error,omitempty"`
+	Tone           string `json:"tone"`                 // post-processing tone, "off" if disabled
+	PostModel      string `json:"post_model,omitempty"` // post-processing model
+	Backend        string `json:"backend,omitempty"`    // backend that served the last transcript, with fallback backends
+	Language       string `json:"language"`             // language requested from the backend, "auto" to detect
+	Profile        string `json:"profile,omitempty"`    // profile matched when the last recording started
+	// Uncertain holds the low-confidence segments of LastTranscript, with
+	// detailed transcription.
+	Uncertain []string `json:"uncertain,omitempty"`
+}
+
+// EventKind names what happened in an Event.
+type EventKind string
+
+const (
+	EventRecordingStarted   EventKind = "recording_started"
+	EventRecordingLatched   EventKind = "recording_latched"
+	EventRecordingStopped   EventKind = "recording_stopped"
+	EventRecordingDiscarded EventKind = "recording_discarded"
+	EventListening          EventKind = "listening"
+	EventPartial            EventKind = "partial"
+	EventTranscribed        EventKind = "transcribed"
+	EventRewritten          EventKind = "rewritten"
+	EventPasting            EventKind = "pasting"
+	EventPasted             EventKind = "pasted"
+	EventError              EventKind = "error"
+	EventIdle               EventKind = "idle"
+	EventSettings           EventKind = "settings"
+)
+
+// Event reports a pipeline transition along with the status after it.
+type Event struct {
+	Kind   EventKind      `json:"kind"`
+	Time   time.Time      `json:"time"`
+	Text   string         `json:"text,omitempty"`  // transcript, rewrite, partial, or discard reason
+	Err    string         `json:"error,omitempty"` // set for EventError
+	Entry  *history.Entry `json:"entry,omitempty"` // set for EventPasted after a new transcription
+	Status Status         `json:"status"`
+}
+
+// Override changes how a single recording is processed, e.g. for a hotkey
+// binding.
+type Override struct {
+	Tone string // post-processing tone, "off" for none; "" keeps the selected tone or the profile's
+	Copy bool   // copy the result to the clipboard instead of pasting it
+}
+
+// copyMode is the paste mode of Override.Copy, as recorded in history.
+const copyMode = "copy"
+
+// recording is what a recording is processed with. It is captured when
+// the recording stops and travels with it, since the next recording can
+// start before its transcript arrives.
+type recording struct {
+	profile  *config.Profile // matched when the recording started, or nil
+	override Override
+}
+
+// language returns the profile's language, if it sets one, or lang.
+func (r recording) language(lang string) string {
+	if r.profile != nil && r.profile.Language != "" {
+		return r.profile.Language
+	}
+	return lang
+}
+
+// profileName returns the name of the matched profile, or "".
+func (r recording) profileName() string {
+	if r.profile == nil {
+		return ""
+	}
+	return r.profile.Name
+}
+
+// segment is a hands-free segment waiting for earlier ones to finish.
+type segment struct {
+	wavData []byte
+	rec     recording
+}
+
+// subscriberBuffer is the number of events queued per subscriber. Events
+// for a subscriber that falls further behind are dropped; each carries a
+// full Status, so a slow observer only misses intermediate steps.
+const subscriberBuffer = 64
+
+// Pipeline turns finished recordings into pasted text. All methods are
+// safe for concurrent use.
+type Pipeline struct {
+	trans        transcriber.Transcriber
+	chime        *chime.Player
+	history      *history.Store
+	logger       *log.Logger
+	ppCfg        config.PostProcessingConfig
+	pasteMode    string
+	pasteDelayMs int
+	paste        func(text string, delayMs int, mode string) error
+	lines        bool // the paster puts each transcript on its own line
+	errorTimeout time.Duration
+	detailed     bool    // request segments and confidence from the transcriber
+	lowLogprob   float64 // segments below this average log-probability are uncertain
+	vocab        *vocabulary.Vocabulary
+	commands     *dictation.Interpreter
+	backspace    func(n int) error
+	copy         func(text string) error
+	focus        func() (string, error) // identifies the focused window; "" if unknown
+	activate     func(window string) error
+	profiles     *profile.Matcher
+	window       func() (profile.Window, error) // describes the focused window, for profiles
+
+	mu        sync.Mutex
+	status    Status
+	pp        postprocess.PostProcessor
+	ppEnabled bool
+	modelName string        // transcription model recorded in history
+	stoppedAt time.Time     // when the last recording stopped, for latency
+	pending   history.Entry // entry being built for the current transcription
+	pasted    string        // text typed by the last paste, for undo and "scratch that"
+	pastedIn  string        // window the last paste went to; "" if unknown
+	current   recording     // the recording in progress, or the last one
+	streamed  recording     // the last recording transcribed by streaming
+	listened  recording     // the hands-free segment in progress
+	gen       int           // bumped on every error; guards the error timeout
+	queued    []segment     // hands-free segments waiting for earlier ones to finish
+	subs      map[int]chan Event
+	nextSub   int
+}
+
+// New creates an idle Pipeline. c and store may be nil to disable chimes
+// and history.
+func New(cfg *config.Config, t transcriber.Transcriber, pp postprocess.PostProcessor, c *chime.Player, store *history.Store, logger *log.Logger) *Pipeline {
+	p := &Pipeline{
+		trans:        t,
+		chime:        c,
+		history:      store,
+		logger:       logger,
+		ppCfg:        cfg.PostProcessing,
+		pasteMode:    cfg.Paste.Mode,
+		pasteDelayMs: cfg.Paste.DelayMs,
+		paste:        func(string, int, string) error { return ErrNoPaster },
+		backspace:    func(int) error { return ErrNoPaster },
+		copy:         clipboard.CopyText,
+		focus:        clipboard.FocusedWindow,
+		activate:     clipboard.ActivateWindow,
+		errorTimeout: errorTimeout,
+		detailed:     cfg.Transcription.Detailed,
+		lowLogprob:   cfg.Transcription.LowConfidenceLogprob,
+		subs:         make(map[int]chan Event),
+	}
+	tone := cfg.PostProcessing.Tone
+	if !cfg.PostProcessing.Enabled {
+		tone = "off"
+	}
+	p.setPostProcessorLocked(pp, tone, cfg.PostProcessing.Model)
+	p.status.Language = cmp.Or(cfg.Transcription.Language, "auto")
+	if cm, ok := t.(transcriber.ConfiguredModeler); ok {
+		p.modelName = cm.ConfiguredModel()
+	}
+	return p
+}
+
+// Subscribe returns a channel of events and a function that closes it.
+func (p *Pipeline) Subscribe() (<-chan Event, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := p.nextSub
+	p.nextSub++
+	ch := make(chan Event, subscriberBuffer)
+	p.subs[id] = ch
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			delete(p.subs, id)
+			close(ch)
+		})
+	}
+}
+
+// Status returns a snapshot of the pipeline.
+func (p *Pipeline) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// SetPostProcessor replaces the post-processor used for new transcriptions.
+// A tone of "off" disables post-processing.
+func (p *Pipeline) SetPostProcessor(pp postprocess.PostProcessor, tone, model string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setPostProcessorLocked(pp, tone, model)
+	p.emitLocked(Event{Kind: EventSettings})
+}
+
+// SetPostProcessing switches the tone and model, building a post-processor
+// from the post_processing config. A tone of "off" disables post-processing.
+func (p *Pipeline) SetPostProcessing(tone, model string) error {
+	if !postprocess.HasTone(tone) {
+		return fmt.Errorf("%w: %s", ErrUnknownTone, tone)
+	}
+	tone = strings.ToLower(tone)
+	cfg := p.ppCfg
+	cfg.Enabled = true
+	cfg.Tone = tone
+	cfg.Model = model
+	p.SetPostProcessor(postprocess.New(&cfg, nil, p.logger), tone, model)
+	return nil
+}
+
+// SetLanguage switches the language requested from the backend for new
+// transcriptions. An empty lang or "auto" lets the backend detect it.
+func (p *Pipeline) SetLanguage(lang string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Language = cmp.Or(strings.ToLower(lang), "auto")
+	p.emitLocked(Event{Kind: EventSettings})
+}
+
+// PostProcessor returns the post-processor used for new transcriptions.
+func (p *Pipeline) PostProcessor() postprocess.PostProcessor {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pp
+}
+
+func (p *Pipeline) setPostProcessorLocked(pp postprocess.PostProcessor, tone, model string) {
+	if tone == "" {
+		tone = "off"
+	}
+	p.pp = pp
+	p.ppEnabled = strings.ToLower(tone) != "off"
+	p.status.Tone = tone
+	p.status.PostModel = model
+}
+
+// SetVocabulary sets the replacements applied to every transcript before
+// post-processing. It must be called before use.
+func (p *Pipeline) SetVocabulary(v *vocabulary.Vocabulary) {
+	p.vocab = v
+}
+
+// SetCommands sets the interpreter for spoken commands such as "comma"
+// and "scratch that". It must be called before use.
+func (p *Pipeline) SetCommands(in *dictation.Interpreter) {
+	p.commands = in
+}
+
+// SetBackspaceFunc replaces how pasted text is deleted. It is meant for
+// tests and must be called before use.
+func (p *Pipeline) SetBackspaceFunc(backspace func(n int) error) {
+	p.backspace = backspace
+}
+
+// SetCopyFunc replaces how Override.Copy recordings are copied,
+// clipboard.CopyText by default. It is meant for tests and must be called
+// before use.
+func (p *Pipeline) SetCopyFunc(copyText func(text string) error) {
+	p.copy = copyText
+}
+
+// SetFocusFuncs replaces how the focused window is detected and
+// activated, clipboard.FocusedWindow and clipboard.ActivateWindow by
+// default. It is meant for tests and must be called before use.
+func (p *Pipeline) SetFocusFuncs(focus func() (string, error), activate func(window string) error) {
+	p.focus = focus
+	p.activate = activate
+}
+
+// SetPaster delivers text and undoes pastes with ps, the backend chosen by
+// clipboard.New from the paste config. Transcripts for a
+// clipboard.LineWriter are not separated by a space. It must be called
+// before use; until then pasting fails with ErrNoPaster.
+func (p *Pipeline) SetPaster(ps clipboard.Paster) {
+	lw, ok := ps.(clipboard.LineWriter)
+	p.lines = ok && lw.WritesLines()
+	p.paste = func(text string, delayMs int, mode string) error {
+		if delayMs > 0 {
+			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		}
+		return ps.Paste(text, mode)
+	}
+	p.backspace = ps.Backspace
+}
+
+// SetProfiles applies the first of profiles that matches the focused
+// window, described by window when each recording starts. It must be
+// called before use.
+func (p *Pipeline) SetProfiles(profiles *profile.Matcher, window func() (profile.Window, error)) {
+	p.profiles = profiles
+	p.window = window
+}
+
+// SetPasteFunc replaces how text is delivered. It is meant for tests and
+// must be called before use.
+func (p *Pipeline) SetPasteFunc(paste func(text string, delayMs int, mode string) error) {
+	p.paste = paste
+}
+
+// SetModelName sets the transcription model name recorded in history, e.g.
+// once the backend has reported which model it serves.
+func (p *Pipeline) SetModelName(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.modelName = name
+}
+
+// RecordingStarted reports that the microphone is recording. It matches
+// the focused window against the profiles, if any.
+func (p *Pipeline) RecordingStarted() {
+	p.RecordingStartedWith(Override{})
+}
+
+// RecordingStartedWith is RecordingStarted for a recording processed with
+// o, which takes precedence over the profile.
+func (p *Pipeline) RecordingStartedWith(o Override) {
+	rec := recording{profile: p.matchProfile(), override: o}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordingStartedLocked(rec)
+}
+
+func (p *Pipeline) recordingStartedLocked(rec recording) {
+	p.current = rec
+	p.status.Profile = rec.profileName()
+	p.status.State = StateRecording
+	p.status.LastError = ""
+	p.status.Latched = false
+	p.status.Partial = ""
+	if p.chimeLocked() {
+		p.chime.PlayStart()
+	}
+	p.emitLocked(Event{Kind: EventRecordingStarted})
+}
+
+// matchProfile returns the profile for the focused window, or nil. It runs
+// outside the lock; detection runs xprop, swaymsg, or osascript.
+func (p *Pipeline) matchProfile() *config.Profile {
+	if p.profiles.Empty() {
+		return nil
+	}
+	w, err := p.window()
+	if err != nil {
+		p.logger.Printf("profile: %v", err)
+		return nil
+	}
+	prof := p.profiles.Match(w)
+	if prof != nil {
+		p.logger.Printf("profile: %s (%v)", prof.Name, w)
+	}
+	return prof
+}
+
+// RecordingLatched reports that a hybrid-mode tap latched recording on.
+func (p *Pipeline) RecordingLatched() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status.State != StateRecording {
+		return
+	}
+	p.status.Latched = true
+	p.emitLocked(Event{Kind: EventRecordingLatched})
+}
+
+// RecordingStopped reports a finished recording and starts transcribing
+// it. When streamed is true the audio is already being transcribed
+// incrementally and the final text arrives via TranscriptionResult.
+func (p *Pipeline) RecordingStopped(wavData []byte, streamed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recordingStoppedLocked(wavData, streamed, p.current)
+}
+
+// recordingStoppedLocked starts transcribing a recording processed with
+// rec, or keeps rec for the streamed result.
+func (p *Pipeline) recordingStoppedLocked(wavData []byte, streamed bool, rec recording) {
+	p.status.State = StateTranscribing
+	p.status.Latched = false
+	p.stoppedAt = time.Now()
+	if p.chimeLocked() {
+		p.chime.PlayStop()
+	}
+	p.emitLocked(Event{Kind: EventRecordingStopped})
+	if streamed {
+		p.streamed = rec
+		return
+	}
+	go p.transcribe(wavData, rec)
+}
+
+// RecordingDiscarded reports that a recording was dropped without
+// transcription, e.g. because it contained no speech. It is ignored unless
+// the pipeline is recording, so a discarded hands-free segment does not
+// interrupt an earlier one being transcribed.
+func (p *Pipeline) RecordingDiscarded(reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger.Printf("recording discarded: %s", reason)
+	if p.status.State != StateRecording {
+		return
+	}
+	p.status.State = StateIdle
+	p.status.Latched = false
+	p.status.Partial = ""
+	if p.chimeLocked() {
+		p.chime.PlayStop()
+	}
+	p.emitLocked(Event{Kind: EventRecordingDiscarded, Text: reason})
+}
+
+// SegmentStarted reports that hands-free listening heard speech. It is
+// RecordingStarted, unless an earlier segment is still being transcribed
+// or pasted; the status then stays as it is, and the new segment is
+// queued when it ends. Either way, the focused window is matched against
+// the profiles now.
+func (p *Pipeline) SegmentStarted() {
+	rec := recording{profile: p.matchProfile()}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listened = rec
+	if !p.busyLocked() {
+		p.recordingStartedLocked(rec)
+	}
+}
+
+// SegmentStopped reports a finished hands-free segment. It is transcribed
+// at once, or after the segments before it if the pipeline is busy with
+// them.
+func (p *Pipeline) SegmentStopped(wavData []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.busyLocked() {
+		p.queued = append(p.queued, segment{wavData: wavData, rec: p.listened})
+		p.logger.Printf("recording listening: segment queued behind %d", len(p.queued)-1)
+		return
+	}
+	p.recordingStoppedLocked(wavData, false, p.listened)
+}
+
+// busyLocked reports whether an earlier recording is still being
+// transcribed, rewritten, or pasted, or hands-free segments are waiting
+// for their turn.
+func (p *Pipeline) busyLocked() bool {
+	switch p.status.State {
+	case StateTranscribing, StatePostProcessing, StatePasting:
+		return true
+	}
+	return len(p.queued) > 0
+}
+
+// nextSegmentLocked starts transcribing the oldest queued hands-free
+// segment, if any.
+func (p *Pipeline) nextSegmentLocked() {
+	if len(p.queued) == 0 {
+		return
+	}
+	next := p.queued[0]
+	p.queued = p.queued[1:]
+	p.recordingStoppedLocked(next.wavData, false, next.rec)
+}
+
+// chimeLocked reports whether chimes should play. They are silent while
+// hands-free listening is armed, since the open mic would pick them up.
+func (p *Pipeline) chimeLocked() bool {
+	return p.chime != nil && !p.status.Armed
+}
+
+// Listening reports that hands-free listening was armed or paused.
+func (p *Pipeline) Listening(armed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Armed = armed
+	if armed {
+		p.logger.Printf("recording listening: armed")
+	} else {
+		p.logger.Printf("recording listening: paused")
+	}
+	p.emitLocked(Event{Kind: EventListening})
+}
+
+// Partial reports in-progress text from a streaming transcription. It is
+// ignored once the transcription has finished.
+func (p *Pipeline) Partial(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status.State != StateRecording && p.status.State != StateTranscribing {
+		return
+	}
+	p.status.Partial = text
+	p.emitLocked(Event{Kind: EventPartial, Text: text})
+}
+
+// TranscriptionResult delivers the final transcript of the last streamed
+// recording and moves on to post-processing or pasting. Empty transcripts
+// are dropped.
+func (p *Pipeline) TranscriptionResult(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transcriptionResultLocked(transcriber.Result{Text: text}, "", p.streamed)
+}
+
+// transcriptionResult is TranscriptionResult for a detailed result of a
+// recording processed with rec, recording which backend served it if
+// known.
+func (p *Pipeline) transcriptionResult(res transcriber.Result, backend string, rec recording) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.transcriptionResultLocked(res, backend, rec)
+}
+
+func (p *Pipeline) transcriptionResultLocked(res transcriber.Result, backend string, rec recording) {
+	raw := res.Text
+	p.status.Partial = ""
+	p.logger.Printf("transcription result: %q", raw)
+	if raw == "" || raw == "[BLANK_AUDIO]" {
+		p.logger.Printf("empty transcription, skipping paste")
+		p.toIdleLocked()
+		return
+	}
+	text := p.vocab.Apply(raw)
+	if text != raw {
+		p.logger.Printf("vocabulary: %q", text)
+	}
+	cmd := p.commands.Interpret(text, rec.language(p.status.Language))
+	if cmd.Text != text {
+		p.logger.Printf("commands: %q", cmd.Text)
+	}
+	text = cmd.Text
+	erase := 0
+	if cmd.ScratchPrevious {
+		erase = utf8.RuneCountInString(p.pasted)
+		p.logger.Printf("commands: scratching %d characters", erase)
+	}
+	if text == "" {
+		if erase == 0 {
+			p.toIdleLocked()
+			return
+		}
+		p.pending = history.Entry{}
+		p.pasteLocked("", erase, p.pasteDelayMs, p.pasteMode)
+		return
+	}
+
+	// Consecutive transcriptions are separated by a leading space, unless
+	// they start with punctuation or a line break, or the paster puts
+	// them on their own lines.
+	needsSpace := p.status.LastTranscript != "" && !cmd.Attach && !p.lines
+	tone, pp, ppEnabled := p.status.Tone, p.pp, p.ppEnabled
+	mode, delayMs := p.pasteMode, p.pasteDelayMs
+	if prof := rec.profile; prof != nil {
+		if prof.Tone != "" {
+			tone, pp, ppEnabled = p.toneLocked(prof.Tone)
+		}
+		mode = cmp.Or(prof.PasteMode, mode)
+		delayMs = cmp.Or(prof.PasteDelayMs, delayMs)
+	}
+	if rec.override.Tone != "" {
+		tone, pp, ppEnabled = p.toneLocked(rec.override.Tone)
+	}
+	if rec.override.Copy {
+		mode, needsSpace = copyMode, false
+	}
+	p.status.LastTranscript = text
+	p.status.Backend = backend
+	p.status.Uncertain = nil
+	p.pending = history.Entry{
+		Time:      time.Now(),
+		Raw:       raw,
+		Text:      text,
+		Tone:      tone,
+		Model:     p.modelName,
+		Backend:   backend,
+		PasteMode: mode,
+		Language:  res.Language,
+		Profile:   rec.profileName(),
+	}
+	for _, seg := range res.Segments {
+		segText := p.vocab.Apply(seg.Text)
+		p.pending.Segments = append(p.pending.Segments, history.Segment{
+			Start:      seg.Start,
+			End:        seg.End,
+			Text:       segText,
+			AvgLogprob: seg.AvgLogprob,
+		})
+		if seg.LowConfidence(p.lowLogprob) {
+			p.status.Uncertain = append(p.status.Uncertain, segText)
+		}
+	}
+	if len(p.status.Uncertain) > 0 {
+		p.logger.Printf("transcription: %d low-confidence segments", len(p.status.Uncertain))
+	}
+	if ppEnabled {
+		p.pending.PostModel = p.status.PostModel
+		p.status.State = StatePostProcessing
+		p.emitLocked(Event{Kind: EventTranscribed, Text: text})
+		go p.rewrite(pp, text, needsSpace, erase, delayMs, mode)
+		return
+	}
+	p.status.State = StatePasting
+	p.emitLocked(Event{Kind: EventTranscribed, Text: text})
+	p.pasteLocked(withSpace(text, needsSpace), erase, delayMs, mode)
+}
+
+// toneLocked returns the post-processor for a profile's or override's
+// tone, and whether post-processing is enabled: the current one if tone is
+// selected, or one built with the current model.
+func (p *Pipeline) toneLocked(tone string) (string, postprocess.PostProcessor, bool) {
+	tone = strings.ToLower(tone)
+	switch {
+	case tone == "off":
+		return tone, p.pp, false
+	case p.ppEnabled && strings.EqualFold(tone, p.status.Tone):
+		return p.status.Tone, p.pp, true
+	}
+	cfg := p.ppCfg
+	cfg.Enabled = true
+	cfg.Tone = tone
+	cfg.Model = p.status.PostModel
+	return tone, postprocess.New(&cfg, nil, p.logger), true
+}
+
+// TranscriptionFailed reports a recording or transcription error.
+func (p *Pipeline) TranscriptionFailed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.status.Partial = ""
+	p.failLocked(err)
+}
+
+// Repaste pastes text that was already delivered, such as a history entry,
+// after delayMs. It does not create a new history entry.
+func (p *Pipeline) Repaste(text string, delayMs int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status.State != StateIdle {
+		return ErrBusy
+	}
+	p.pending = history.Entry{}
+	p.pasteLocked(text, 0, delayMs, p.pasteMode)
+	return nil
+}
+
+// TranscribeContext returns ctx carrying the selected language, or the
+// profile's matched for the recording in progress, for transcriptions
+// made outside the pipeline such as streaming.
+func (p *Pipeline) TranscribeContext(ctx context.Context) context.Context {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.transcribeContextLocked(ctx, p.current)
+}
+
+// transcribeContextLocked returns ctx carrying the language for a
+// recording processed with rec.
+func (p *Pipeline) transcribeContextLocked(ctx context.Context, rec recording) context.Context {
+	return transcriber.WithLanguage(ctx, rec.language(p.status.Language))
+}
+
+// Undo deletes the text typed by the last paste with backspaces. It
+// refuses with ErrFocusChanged if another window has been focused since,
+// where that is detectable. With refocus, as when undoing from the TUI,
+// the pasted window is focused again instead. If the window is unknown,
+// as on Wayland, the text is deleted wherever the focus is.
+func (p *Pipeline) Undo(refocus bool) error {
+	p.mu.Lock()
+	text, window := p.pasted, p.pastedIn
+	p.mu.Unlock()
+	if text == "" {
+		return ErrNothingToUndo
+	}
+
+	// Focus is checked outside the lock; it runs xdotool or osascript.
+	switch current, err := p.focus(); {
+	case refocus && window == "":
+		p.logger.Printf("undo: the pasted window is unknown, not refocusing")
+	case err != nil:
+		p.logger.Printf("undo: focus check failed: %v", err)
+	case window != "" && current != window:
+		if !refocus {
+			return ErrFocusChanged
+		}
+		if err := p.activate(window); err != nil {
+			return fmt.Errorf("%w: %v", ErrFocusChanged, err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.status.State != StateIdle {
+		return ErrBusy
+	}
+	if p.pasted != text {
+		return ErrNothingToUndo // pasted or undone meanwhile
+	}
+	n := utf8.RuneCountInString(text)
+	p.logger.Printf("undo: erasing %d characters", n)
+	p.pending = history.Entry{}
+	p.pasteLocked("", n, p.pasteDelayMs, p.pasteMode)
+	return nil
+}
+
+func (p *Pipeline) transcribe(wavData []byte, rec recording) {
+	p.mu.Lock()
+	ctx := p.transcribeContextLocked(context.Background(), rec)
+	p.mu.Unlock()
+	ctx, servedBy := transcriber.WithServedBy(ctx)
+	var res transcriber.Result
+	var err error
+	if p.detailed {
+		res, err = transcriber.TranscribeDetailed(ctx, p.trans, wavData)
+	} else {
+		res.Text, err = p.trans.Transcribe(ctx, wavData)
+	}
+	if err != nil {
+		p.TranscriptionFailed(err)
+		return
+	}
+	p.transcriptionResult(res, servedBy(), rec)
+}
+
+func (p *Pipeline) rewrite(pp postprocess.PostProcessor, text string, needsSpace bool, erase, delayMs int, mode string) {
+	result, err := pp.Rewrite(context.Background(), text)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		// Graceful degradation: paste the original transcript.
+		p.logger.Printf("post-processing error (falling back to original): %v", err)
+		result = text
+	} else {
+		p.logger.Printf("post-processing result: %q", result)
+		p.pending.Text = result
+		p.emitLocked(Event{Kind: EventRewritten, Text: result})
+	}
+	p.pasteLocked(withSpace(result, needsSpace), erase, delayMs, mode)
+}
+
+// pasteLocked enters the pasting state and, in the background, deletes the
+// last erase characters and pastes text in mode.
+func (p *Pipeline) pasteLocked(text string, erase, delayMs int, mode string) {
+	p.status.State = StatePasting
+	p.emitLocked(Event{Kind: EventPasting, Text: text})
+	go func() {
+		p.logger.Printf("paste: mode=%s delay=%dms erase=%d", mode, delayMs, erase)
+		err := p.deliver(text, erase, delayMs, mode)
+		var window string
+		if err != nil {
+			p.logger.Printf("paste error: %v", err)
+			err = fmt.Errorf("paste: %w", err)
+		} else {
+			p.logger.Printf("paste: success")
+			if mode == copyMode {
+				text = "" // nothing was typed, so there is nothing to undo
+			} else if text != "" {
+				window, _ = p.focus()
+			}
+		}
+		p.pasteDone(text, window, err)
+	}()
+}
+
+// deliver erases and pastes for pasteLocked. The delay applies once,
+// before the first keystroke.
+func (p *Pipeline) deliver(text string, erase, delayMs int, mode string) error {
+	if erase > 0 {
+		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		delayMs = 0
+		if err := p.backspace(erase); err != nil {
+			return fmt.Errorf("erase: %w", err)
+		}
+	}
+	if text == "" {
+		return nil
+	}
+	if mode == copyMode {
+		return p.copy(text)
+	}
+	return p.paste(text, delayMs, mode)
+}
+
+func (p *Pipeline) pasteDone(text, window string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pasted, p.pastedIn = text, window
+	if err != nil {
+		p.pasted = ""
+	}
+
+	var entry *history.Entry
+	if p.pending.Raw != "" {
+		e := p.pending
+		p.pending = history.Entry{}
+		if !p.stoppedAt.IsZero() {
+			e.LatencyMs = time.Since(p.stoppedAt).Milliseconds()
+		}
+		if err != nil {
+			e.PasteError = err.Error()
+		}
+		if p.history != nil {
+			// Saved synchronously so entries stay in order; the file is small.
+			if herr := p.history.Append(e); herr != nil {
+				p.logger.Printf("history save failed: %v", herr)
+			}
+		}
+		entry = &e
+	}
+
+	if err != nil {
+		p.failLocked(err)
+		return
+	}
+	p.status.State = StateIdle
+	p.emitLocked(Event{Kind: EventPasted, Entry: entry})
+	p.nextSegmentLocked()
+}
+
+// failLocked enters the error state and returns to idle after errorTimeout
+// unless the pipeline has left it (or failed again) by then.
+func (p *Pipeline) failLocked(err error) {
+	p.status.State = StateError
+	p.status.LastError = err.Error()
+	p.gen++
+	gen := p.gen
+	p.emitLocked(Event{Kind: EventError, Err: err.Error()})
+	time.AfterFunc(p.errorTimeout, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.gen != gen || p.status.State != StateError {
+			return
+		}
+		p.status.LastError = ""
+		p.toIdleLocked()
+	})
+}
+
+func (p *Pipeline) toIdleLocked() {
+	p.status.State = StateIdle
+	p.emitLocked(Event{Kind: EventIdle})
+	p.nextSegmentLocked()
+}
+
+// emitLocked sends ev with the current status to every subscriber without
+// blocking.
+func (p *Pipeline) emitLocked(ev Event) {
+	ev.Time = time.Now()
+	ev.Status = p.status
+	for _, ch := range p.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+func withSpace(text string, needsSpace bool) string {
+	if needsSpace {
+		return " " + text
+	}
+	return text
+}
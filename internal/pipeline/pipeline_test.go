@@ -0,0 +1,887 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Danondso/palaver/internal/clipboard"
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/dictation"
+	"github.com/Danondso/palaver/internal/history"
+	"github.com/Danondso/palaver/internal/postprocess"
+	"github.com/Danondso/palaver/internal/profile"
+	"github.com/Danondso/palaver/internal/transcriber"
+	"github.com/Danondso/palaver/internal/vocabulary"
+)
+
+type mockTranscriber struct {
+	result string
+	err    error
+}
+
+func (m *mockTranscriber) Transcribe(_ context.Context, _ []byte) (string, error) {
+	return m.result, m.err
+}
+
+type mockPostProcessor struct {
+	result string
+	err    error
+}
+
+func (m *mockPostProcessor) Rewrite(_ context.Context, text string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.result != "" {
+		return m.result, nil
+	}
+	return text, nil
+}
+
+type pasteCall struct {
+	text    string
+	delayMs int
+}
+
+// newTestPipeline returns a pipeline whose pastes are recorded instead of
+// typed, and a subscription to its events.
+func newTestPipeline(t *testing.T, trans transcriber.Transcriber, pasteErr error) (*Pipeline, <-chan Event, <-chan pasteCall) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.PostProcessing.Tone = "off"
+	p := New(cfg, trans, &postprocess.NoopPostProcessor{}, nil, nil, log.New(io.Discard, "", 0))
+	pastes := make(chan pasteCall, 8)
+	p.SetPasteFunc(func(text string, delayMs int, _ string) error {
+		pastes <- pasteCall{text, delayMs}
+		return pasteErr
+	})
+	p.SetFocusFuncs(func() (string, error) { return "", nil }, func(string) error { return nil })
+	events, unsubscribe := p.Subscribe()
+	t.Cleanup(unsubscribe)
+	return p, events, pastes
+}
+
+// waitFor reads events until one of the given kind arrives.
+func waitFor(t *testing.T, events <-chan Event, kind EventKind) Event {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Kind == kind {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event", kind)
+		}
+	}
+}
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateIdle, "idle"},
+		{StatePostProcessing, "post_processing"},
+		{StateError, "error"},
+		{State(42), "state(42)"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("State(%d).String() = %q, want %q", int(tt.state), got, tt.want)
+		}
+	}
+}
+
+func TestStatusJSONRoundTrip(t *testing.T) {
+	want := Status{State: StatePostProcessing, Tone: "formal", LastTranscript: "hi"}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"state":"post_processing"`) {
+		t.Errorf("expected state by name, got %s", data)
+	}
+	var got Status
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip: got %+v, want %+v", got, want)
+	}
+	if err := json.Unmarshal([]byte(`{"state":"dozing"}`), &got); err == nil {
+		t.Error("expected an error for an unknown state")
+	}
+}
+
+func TestRecordTranscribePaste(t *testing.T) {
+	p, events, pastes := newTestPipeline(t, &mockTranscriber{result: "hello world"}, nil)
+
+	p.RecordingStarted()
+	if ev := waitFor(t, events, EventRecordingStarted); ev.Status.State != StateRecording {
+		t.Errorf("expected StateRecording, got %s", ev.Status.State)
+	}
+
+	p.RecordingStopped([]byte("wav"), false)
+	if ev := waitFor(t, events, EventRecordingStopped); ev.Status.State != StateTranscribing {
+		t.Errorf("expected StateTranscribing, got %s", ev.Status.State)
+	}
+	if ev := waitFor(t, events, EventPasting); ev.Status.LastTranscript != "hello world" {
+		t.Errorf("expected last transcript 'hello world', got %q", ev.Status.LastTranscript)
+	}
+	if call := <-pastes; call.text != "hello world" {
+		t.Errorf("expected paste of 'hello world', got %q", call.text)
+	}
+	if ev := waitFor(t, events, EventPasted); ev.Status.State != StateIdle {
+		t.Errorf("expected StateIdle after paste, got %s", ev.Status.State)
+	}
+}
+
+func TestConsecutiveTranscriptionsGetLeadingSpace(t *testing.T) {
+	p, events, pastes := newTestPipeline(t, &mockTranscriber{}, nil)
+
+	p.TranscriptionResult("first")
+	<-pastes
+	waitFor(t, events, EventPasted)
+	p.TranscriptionResult("second")
+	if call := <-pastes; call.text != " second" {
+		t.Errorf("expected leading space on second paste, got %q", call.text)
+	}
+}
+
+func TestRecordingLatched(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+
+	p.RecordingLatched()
+	if p.Status().Latched {
+		t.Error("expected latch to be ignored when not recording")
+	}
+
+	p.RecordingStarted()
+	p.RecordingLatched()
+	if ev := waitFor(t, events, EventRecordingLatched); !ev.Status.Latched {
+		t.Error("expected Latched after RecordingLatched")
+	}
+	p.RecordingStopped(nil, true)
+	if p.Status().Latched {
+		t.Error("expected Latched cleared after recording stops")
+	}
+}
+
+func TestStreamedRecordingWaitsForResult(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{result: "batch"}, nil)
+
+	p.RecordingStarted()
+	p.Partial("live words")
+	if ev := waitFor(t, events, EventPartial); ev.Status.Partial != "live words" {
+		t.Errorf("expected partial 'live words', got %q", ev.Status.Partial)
+	}
+	p.RecordingStopped([]byte("wav"), true)
+	select {
+	case ev := <-events:
+		if ev.Kind != EventRecordingStopped {
+			t.Fatalf("expected only the stop event, got %s", ev.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected stop event")
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("expected no batch transcription for a streamed recording, got %s", ev.Kind)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.TranscriptionResult("live words done")
+	if ev := waitFor(t, events, EventTranscribed); ev.Status.Partial != "" {
+		t.Error("expected partial cleared on final result")
+	}
+}
+
+func TestPartialIgnoredWhenIdle(t *testing.T) {
+	p, _, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.Partial("late partial")
+	if p.Status().Partial != "" {
+		t.Error("expected late partial to be ignored when idle")
+	}
+}
+
+func TestRecordingDiscarded(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.RecordingStarted()
+	p.RecordingDiscarded("no speech detected")
+	ev := waitFor(t, events, EventRecordingDiscarded)
+	if ev.Status.State != StateIdle || ev.Text != "no speech detected" {
+		t.Errorf("unexpected discard event: %+v", ev)
+	}
+}
+
+// gatedTranscriber returns each recording's audio as its transcript once
+// release allows it.
+type gatedTranscriber struct {
+	release chan struct{}
+}
+
+func (g *gatedTranscriber) Transcribe(_ context.Context, wav []byte) (string, error) {
+	<-g.release
+	return string(wav), nil
+}
+
+func TestOverlappingSegmentsAreQueued(t *testing.T) {
+	trans := &gatedTranscriber{release: make(chan struct{})}
+	p, events, pastes := newTestPipeline(t, trans, nil)
+	p.Listening(true)
+
+	p.SegmentStarted()
+	p.SegmentStopped([]byte("one"))
+	waitFor(t, events, EventRecordingStopped)
+
+	// Speech is heard again while the first segment is transcribed.
+	p.SegmentStarted()
+	if st := p.Status().State; st != StateTranscribing {
+		t.Fatalf("expected the first segment to keep transcribing, got %s", st)
+	}
+	p.SegmentStopped([]byte("two"))
+	p.RecordingDiscarded("no speech detected") // a third, empty segment
+	if st := p.Status().State; st != StateTranscribing {
+		t.Fatalf("expected a discarded segment not to interrupt, got %s", st)
+	}
+
+	close(trans.release)
+	for _, want := range []string{"one", " two"} {
+		select {
+		case got := <-pastes:
+			if got.text != want {
+				t.Errorf("expected paste %q, got %q", want, got.text)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected paste %q", want)
+		}
+	}
+	waitFor(t, events, EventPasted)
+	waitFor(t, events, EventPasted)
+	if st := p.Status().State; st != StateIdle {
+		t.Errorf("expected idle once the queue is empty, got %s", st)
+	}
+}
+
+func TestBlankTranscriptionSkipsPaste(t *testing.T) {
+	for _, text := range []string{"", "[BLANK_AUDIO]"} {
+		p, events, pastes := newTestPipeline(t, &mockTranscriber{result: text}, nil)
+		p.RecordingStopped([]byte("wav"), false)
+		if ev := waitFor(t, events, EventIdle); ev.Status.LastTranscript != "" {
+			t.Errorf("%q: expected no transcript recorded", text)
+		}
+		select {
+		case <-pastes:
+			t.Errorf("%q: expected no paste", text)
+		default:
+		}
+	}
+}
+
+func TestTranscriptionErrorReturnsToIdle(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{err: fmt.Errorf("connection refused")}, nil)
+	p.errorTimeout = 10 * time.Millisecond
+
+	p.RecordingStopped([]byte("wav"), false)
+	ev := waitFor(t, events, EventError)
+	if ev.Status.State != StateError || ev.Status.LastError != "connection refused" {
+		t.Errorf("unexpected error status: %+v", ev.Status)
+	}
+	ev = waitFor(t, events, EventIdle)
+	if ev.Status.LastError != "" {
+		t.Errorf("expected error cleared, got %q", ev.Status.LastError)
+	}
+}
+
+func TestErrorTimeoutSkippedAfterNewRecording(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.errorTimeout = 20 * time.Millisecond
+
+	p.TranscriptionFailed(fmt.Errorf("boom"))
+	p.RecordingStarted()
+	time.Sleep(50 * time.Millisecond)
+	if got := p.Status().State; got != StateRecording {
+		t.Errorf("expected recording to survive the stale error timeout, got %s", got)
+	}
+	for len(events) > 0 {
+		if ev := <-events; ev.Kind == EventIdle {
+			t.Error("expected no idle event from a stale error timeout")
+		}
+	}
+}
+
+func TestPostProcessing(t *testing.T) {
+	tests := []struct {
+		name string
+		pp   *mockPostProcessor
+		want string
+	}{
+		{"rewritten", &mockPostProcessor{result: "please help me"}, "please help me"},
+		{"error falls back to original", &mockPostProcessor{err: fmt.Errorf("timeout")}, "help me"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, events, pastes := newTestPipeline(t, &mockTranscriber{}, nil)
+			p.SetPostProcessor(tt.pp, "polite", "llama3.2")
+
+			p.TranscriptionResult("help me")
+			if ev := waitFor(t, events, EventTranscribed); ev.Status.State != StatePostProcessing {
+				t.Errorf("expected StatePostProcessing, got %s", ev.Status.State)
+			}
+			if call := <-pastes; call.text != tt.want {
+				t.Errorf("expected paste %q, got %q", tt.want, call.text)
+			}
+			waitFor(t, events, EventPasted)
+		})
+	}
+}
+
+func TestSetPostProcessing(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+
+	if err := p.SetPostProcessing("Formal", "mistral"); err != nil {
+		t.Fatalf("SetPostProcessing: %v", err)
+	}
+	ev := waitFor(t, events, EventSettings)
+	if ev.Status.Tone != "formal" || ev.Status.PostModel != "mistral" {
+		t.Errorf("unexpected settings status: %+v", ev.Status)
+	}
+	if _, ok := p.PostProcessor().(*postprocess.LLMPostProcessor); !ok {
+		t.Errorf("expected an LLM post-processor, got %T", p.PostProcessor())
+	}
+
+	if err := p.SetPostProcessing("off", "mistral"); err != nil {
+		t.Fatalf("SetPostProcessing(off): %v", err)
+	}
+	if _, ok := p.PostProcessor().(*postprocess.NoopPostProcessor); !ok {
+		t.Errorf("expected a no-op post-processor for tone off, got %T", p.PostProcessor())
+	}
+
+	if err := p.SetPostProcessing("shouty", ""); !errors.Is(err, ErrUnknownTone) {
+		t.Errorf("expected ErrUnknownTone, got %v", err)
+	}
+	if tone := p.Status().Tone; tone != "off" {
+		t.Errorf("expected tone unchanged after an unknown tone, got %s", tone)
+	}
+}
+
+func TestToneOffSkipsPostProcessing(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.SetPostProcessor(&mockPostProcessor{result: "rewritten"}, "off", "")
+	p.TranscriptionResult("hello")
+	if ev := waitFor(t, events, EventTranscribed); ev.Status.State != StatePasting {
+		t.Errorf("expected StatePasting when tone is off, got %s", ev.Status.State)
+	}
+}
+
+func TestDisabledPostProcessingReportsToneOff(t *testing.T) {
+	cfg := config.Default()
+	cfg.PostProcessing.Enabled = false
+	cfg.PostProcessing.Tone = "formal"
+	p := New(cfg, &mockTranscriber{}, &postprocess.NoopPostProcessor{}, nil, nil, log.New(io.Discard, "", 0))
+	if st := p.Status(); st.Tone != "off" {
+		t.Errorf("expected tone off while post-processing is disabled, got %q", st.Tone)
+	}
+}
+
+func TestPasteErrorEntersErrorState(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, fmt.Errorf("no display"))
+	p.TranscriptionResult("hello")
+	ev := waitFor(t, events, EventError)
+	if ev.Status.LastError != "paste: no display" {
+		t.Errorf("expected wrapped paste error, got %q", ev.Status.LastError)
+	}
+}
+
+func TestHistoryRecorded(t *testing.T) {
+	cfg := config.Default()
+	cfg.PostProcessing.Tone = "off"
+	store := history.New(&config.HistoryConfig{Path: filepath.Join(t.TempDir(), "history.jsonl")})
+	p := New(cfg, &mockTranscriber{result: "um hello"}, &postprocess.NoopPostProcessor{}, nil, store, log.New(io.Discard, "", 0))
+	p.SetFocusFuncs(func() (string, error) { return "", nil }, func(string) error { return nil })
+	p.SetPasteFunc(func(string, int, string) error { return nil })
+	p.SetModelName("whisper-1")
+	p.SetPostProcessor(&mockPostProcessor{result: "Hello."}, "polite", "llama3.2")
+	events, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	p.RecordingStopped([]byte("wav"), false)
+	ev := waitFor(t, events, EventPasted)
+	if ev.Entry == nil || ev.Entry.Text != "Hello." {
+		t.Fatalf("expected pasted event to carry the entry, got %+v", ev.Entry)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Raw != "um hello" || e.Text != "Hello." {
+		t.Errorf("unexpected entry text: raw=%q text=%q", e.Raw, e.Text)
+	}
+	if e.Model != "whisper-1" || e.Tone != "polite" || e.PostModel != "llama3.2" || e.PasteMode != cfg.Paste.Mode {
+		t.Errorf("unexpected entry metadata: %+v", e)
+	}
+
+	// A re-paste is not a new transcription.
+	if err := p.Repaste("Hello.", 0); err != nil {
+		t.Fatalf("Repaste: %v", err)
+	}
+	if ev := waitFor(t, events, EventPasted); ev.Entry != nil {
+		t.Error("expected no history entry for a re-paste")
+	}
+	if entries, _ := store.Load(); len(entries) != 1 {
+		t.Errorf("expected re-paste not to be recorded, got %d entries", len(entries))
+	}
+}
+
+func TestServingBackendRecorded(t *testing.T) {
+	trans := transcriber.NewFallback([]transcriber.Backend{
+		{Name: "parakeet", Transcriber: &mockTranscriber{err: errors.New("restarting")}},
+		{Name: "remote", Transcriber: &mockTranscriber{result: "hello"}},
+	}, nil)
+	p, events, _ := newTestPipeline(t, trans, nil)
+
+	p.RecordingStopped([]byte("wav"), false)
+	ev := waitFor(t, events, EventPasted)
+	if ev.Status.Backend != "remote" {
+		t.Errorf("expected status backend remote, got %q", ev.Status.Backend)
+	}
+	if ev.Entry == nil || ev.Entry.Backend != "remote" {
+		t.Errorf("expected history entry backend remote, got %+v", ev.Entry)
+	}
+}
+
+// detailedTranscriber returns a fixed detailed result.
+type detailedTranscriber struct{ res transcriber.Result }
+
+func (d *detailedTranscriber) Transcribe(context.Context, []byte) (string, error) {
+	return d.res.Text, nil
+}
+
+func (d *detailedTranscriber) TranscribeDetailed(context.Context, []byte) (transcriber.Result, error) {
+	return d.res, nil
+}
+
+func TestDetailedTranscription(t *testing.T) {
+	trans := &detailedTranscriber{res: transcriber.Result{
+		Text:     "Ship it. Maybe Tuesday.",
+		Language: "english",
+		Segments: []transcriber.Segment{
+			{Start: 0, End: 1, Text: "Ship it.", AvgLogprob: -0.2},
+			{Start: 1, End: 2.5, Text: "Maybe Tuesday.", AvgLogprob: -1.6},
+		},
+	}}
+	cfg := config.Default()
+	cfg.PostProcessing.Tone = "off"
+	cfg.Transcription.Detailed = true
+	p := New(cfg, trans, &postprocess.NoopPostProcessor{}, nil, nil, log.New(io.Discard, "", 0))
+	p.SetPasteFunc(func(string, int, string) error { return nil })
+	p.SetFocusFuncs(func() (string, error) { return "", nil }, func(string) error { return nil })
+	events, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	p.RecordingStopped([]byte("wav"), false)
+	ev := waitFor(t, events, EventPasted)
+	if !reflect.DeepEqual(ev.Status.Uncertain, []string{"Maybe Tuesday."}) {
+		t.Errorf("expected the low-confidence segment flagged, got %v", ev.Status.Uncertain)
+	}
+	if ev.Entry == nil || ev.Entry.Language != "english" || len(ev.Entry.Segments) != 2 || ev.Entry.Segments[1].End != 2.5 {
+		t.Errorf("expected language and segments in the history entry, got %+v", ev.Entry)
+	}
+
+	// Plain results clear the flags.
+	p.TranscriptionResult("next")
+	if ev := waitFor(t, events, EventPasted); ev.Status.Uncertain != nil {
+		t.Errorf("expected no uncertain segments for a plain result, got %v", ev.Status.Uncertain)
+	}
+}
+
+func TestVocabularyAppliedBeforePaste(t *testing.T) {
+	p, events, pastes := newTestPipeline(t, &mockTranscriber{result: "run cube control get pods"}, nil)
+	vocab, err := vocabulary.New(&config.VocabularyConfig{
+		Replace: []config.Replacement{{From: "cube control", To: "kubectl"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetVocabulary(vocab)
+
+	p.RecordingStopped([]byte("wav"), false)
+	if got := (<-pastes).text; got != "run kubectl get pods" {
+		t.Errorf("expected the corrected transcript pasted, got %q", got)
+	}
+	ev := waitFor(t, events, EventPasted)
+	if ev.Entry == nil || ev.Entry.Raw != "run cube control get pods" || ev.Entry.Text != "run kubectl get pods" {
+		t.Errorf("expected raw and corrected text in the history entry, got %+v", ev.Entry)
+	}
+}
+
+func TestSpokenCommands(t *testing.T) {
+	trans := &mockTranscriber{result: "hello world"}
+	p, events, pastes := newTestPipeline(t, trans, nil)
+	commands, err := dictation.New(&config.CommandsConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetCommands(commands)
+	erased := make(chan int, 1)
+	p.SetBackspaceFunc(func(n int) error {
+		erased <- n
+		return nil
+	})
+
+	p.RecordingStopped([]byte("wav"), false)
+	<-pastes
+	waitFor(t, events, EventPasted)
+
+	// Punctuation joins the previous paste without a space.
+	trans.result = "comma new line next"
+	p.RecordingStopped([]byte("wav"), false)
+	if got := (<-pastes).text; got != ",\nnext" {
+		t.Errorf("expected punctuation attached to the previous paste, got %q", got)
+	}
+	waitFor(t, events, EventPasted)
+
+	// "Scratch that" deletes the previous paste and pastes nothing.
+	trans.result = "Scratch that."
+	p.RecordingStopped([]byte("wav"), false)
+	ev := waitFor(t, events, EventPasted)
+	if n := <-erased; n != len(",\nnext") {
+		t.Errorf("expected %d characters erased, got %d", len(",\nnext"), n)
+	}
+	if ev.Entry != nil {
+		t.Errorf("expected no history entry for a deletion, got %+v", ev.Entry)
+	}
+	select {
+	case paste := <-pastes:
+		t.Errorf("expected nothing pasted, got %q", paste.text)
+	default:
+	}
+
+	// Nothing is left to scratch.
+	p.RecordingStopped([]byte("wav"), false)
+	waitFor(t, events, EventIdle)
+	select {
+	case n := <-erased:
+		t.Errorf("expected nothing erased twice, got %d", n)
+	default:
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		name      string
+		pastedIn  string // window focused after the paste
+		focusedAt string // window focused at undo time
+		refocus   bool
+		wantErr   error
+		activated string
+	}{
+		{name: "same window", pastedIn: "42", focusedAt: "42"},
+		{name: "focus unknown", pastedIn: "", focusedAt: ""},
+		{name: "focus changed", pastedIn: "42", focusedAt: "7", wantErr: ErrFocusChanged},
+		{name: "focus changed with refocus", pastedIn: "42", focusedAt: "7", refocus: true, activated: "42"},
+		{name: "refocus with the window unknown", pastedIn: "", focusedAt: "", refocus: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, events, pastes := newTestPipeline(t, &mockTranscriber{result: "hello"}, nil)
+			focused, activated := tt.pastedIn, ""
+			p.SetFocusFuncs(func() (string, error) { return focused, nil }, func(w string) error {
+				activated = w
+				return nil
+			})
+			erased := make(chan int, 1)
+			p.SetBackspaceFunc(func(n int) error {
+				erased <- n
+				return nil
+			})
+
+			if err := p.Undo(false); !errors.Is(err, ErrNothingToUndo) {
+				t.Fatalf("expected ErrNothingToUndo before any paste, got %v", err)
+			}
+			p.RecordingStopped([]byte("wav"), false)
+			<-pastes
+			waitFor(t, events, EventPasted)
+
+			focused = tt.focusedAt
+			err := p.Undo(tt.refocus)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if activated != tt.activated {
+				t.Errorf("expected window %q activated, got %q", tt.activated, activated)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			waitFor(t, events, EventPasted)
+			if n := <-erased; n != len("hello") {
+				t.Errorf("expected %d characters erased, got %d", len("hello"), n)
+			}
+			if err := p.Undo(tt.refocus); !errors.Is(err, ErrNothingToUndo) {
+				t.Errorf("expected a second undo to have nothing to undo, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSetPaster(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{result: "hello"}, nil)
+	fake := &clipboard.Fake{}
+	p.SetPaster(fake)
+
+	p.RecordingStopped([]byte("wav"), false)
+	waitFor(t, events, EventPasted)
+	if err := p.Undo(false); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	waitFor(t, events, EventPasted)
+	if got := fake.Pasted(); !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Errorf("expected one paste through the backend, got %q", got)
+	}
+	if got := fake.Erased(); !reflect.DeepEqual(got, []int{len("hello")}) {
+		t.Errorf("expected the undo through the backend, got %v", got)
+	}
+
+	fake.Err = clipboard.ErrUnsupported
+	p.TranscriptionResult("again")
+	if ev := waitFor(t, events, EventError); !strings.Contains(ev.Status.LastError, "not supported") {
+		t.Errorf("expected the backend error, got %q", ev.Status.LastError)
+	}
+}
+
+func TestLineWriterGetsNoSpace(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	var out bytes.Buffer
+	p.SetPaster(clipboard.NewWriter(&out))
+
+	p.TranscriptionResult("first")
+	waitFor(t, events, EventPasted)
+	p.TranscriptionResult("second")
+	waitFor(t, events, EventPasted)
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Errorf("expected each transcript on its own line, got %q", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.SetPostProcessor(&mockPostProcessor{result: "Dear team, hello."}, "formal", "llama3.2")
+	profiles, err := profile.New([]config.Profile{
+		{Name: "slack", Class: "slack", Tone: "off", PasteMode: "clipboard", PasteDelayMs: 200, Language: "de"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := profile.Window{Class: "Slack"}
+	p.SetProfiles(profiles, func() (profile.Window, error) { return window, nil })
+	type call struct {
+		text, mode string
+		delayMs    int
+	}
+	calls := make(chan call, 2)
+	p.SetPasteFunc(func(text string, delayMs int, mode string) error {
+		calls <- call{text, mode, delayMs}
+		return nil
+	})
+
+	p.RecordingStarted()
+	if got := p.Status().Profile; got != "slack" {
+		t.Errorf("expected profile slack, got %q", got)
+	}
+	p.RecordingStopped(nil, true)
+	p.mu.Lock()
+	lang := p.current.language(p.status.Language)
+	p.mu.Unlock()
+	if lang != "de" {
+		t.Errorf("expected the profile's language, got %q", lang)
+	}
+	p.TranscriptionResult("hello")
+	if got := <-calls; got != (call{"hello", "clipboard", 200}) {
+		t.Errorf("expected the profile's tone and paste settings, got %+v", got)
+	}
+	waitFor(t, events, EventPasted)
+
+	// Another window: the global settings apply again.
+	window = profile.Window{Class: "kitty"}
+	p.RecordingStarted()
+	if got := p.Status().Profile; got != "" {
+		t.Errorf("expected no profile, got %q", got)
+	}
+	p.RecordingStopped(nil, true)
+	p.TranscriptionResult("hello")
+	if got := <-calls; got != (call{" Dear team, hello.", config.Default().Paste.Mode, config.Default().Paste.DelayMs}) {
+		t.Errorf("expected the global settings, got %+v", got)
+	}
+}
+
+func TestRecordingOverride(t *testing.T) {
+	p, events, pastes := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.SetPostProcessor(&mockPostProcessor{result: "Dear team, hello."}, "formal", "llama3.2")
+	copied := make(chan string, 1)
+	p.SetCopyFunc(func(text string) error {
+		copied <- text
+		return nil
+	})
+
+	p.RecordingStartedWith(Override{Tone: "off", Copy: true})
+	p.RecordingStopped(nil, true)
+	p.TranscriptionResult("hello")
+	if got := <-copied; got != "hello" {
+		t.Errorf("expected the raw transcript copied, got %q", got)
+	}
+	ev := waitFor(t, events, EventPasted)
+	if ev.Entry == nil || ev.Entry.PasteMode != "copy" || ev.Entry.Tone != "off" {
+		t.Errorf("expected a copy entry without post-processing, got %+v", ev.Entry)
+	}
+	if err := p.Undo(false); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected nothing to undo after copying, got %v", err)
+	}
+
+	// The override only lasts for one recording.
+	p.RecordingStarted()
+	p.RecordingStopped(nil, true)
+	p.TranscriptionResult("hello")
+	if call := <-pastes; call.text != " Dear team, hello." {
+		t.Errorf("expected the selected tone to apply again, got %q", call.text)
+	}
+	select {
+	case text := <-copied:
+		t.Errorf("unexpected copy %q", text)
+	default:
+	}
+}
+
+func TestOverrideKeptWhileNextRecordingStarts(t *testing.T) {
+	trans := &gatedTranscriber{release: make(chan struct{})}
+	p, events, pastes := newTestPipeline(t, trans, nil)
+	p.SetPostProcessor(&mockPostProcessor{result: "Dear team, hello."}, "formal", "llama3.2")
+	copied := make(chan string, 1)
+	p.SetCopyFunc(func(text string) error {
+		copied <- text
+		return nil
+	})
+
+	p.RecordingStartedWith(Override{Tone: "off", Copy: true})
+	p.RecordingStopped([]byte("hello"), false)
+	// The plain hotkey is pressed before the first transcript arrives.
+	p.RecordingStarted()
+	trans.release <- struct{}{}
+	if got := <-copied; got != "hello" {
+		t.Errorf("expected the first recording copied without a tone, got %q", got)
+	}
+	ev := waitFor(t, events, EventPasted)
+	if ev.Entry == nil || ev.Entry.PasteMode != "copy" || ev.Entry.Tone != "off" {
+		t.Errorf("expected a copy entry without post-processing, got %+v", ev.Entry)
+	}
+
+	p.RecordingStopped([]byte("hello"), false)
+	trans.release <- struct{}{}
+	if call := <-pastes; call.text != " Dear team, hello." {
+		t.Errorf("expected the second recording pasted with the selected tone, got %q", call.text)
+	}
+	select {
+	case text := <-copied:
+		t.Errorf("unexpected copy %q", text)
+	default:
+	}
+}
+
+func TestProfileKeptWhileNextRecordingStarts(t *testing.T) {
+	trans := &gatedTranscriber{release: make(chan struct{})}
+	p, events, _ := newTestPipeline(t, trans, nil)
+	profiles, err := profile.New([]config.Profile{
+		{Name: "slack", Class: "slack", PasteMode: "clipboard", Language: "de"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := profile.Window{Class: "Slack"}
+	p.SetProfiles(profiles, func() (profile.Window, error) { return window, nil })
+	modes := make(chan string, 1)
+	p.SetPasteFunc(func(_ string, _ int, mode string) error {
+		modes <- mode
+		return nil
+	})
+
+	p.RecordingStarted()
+	p.RecordingStopped([]byte("hallo"), false)
+	// The next recording starts in another window.
+	window = profile.Window{Class: "kitty"}
+	p.RecordingStarted()
+	trans.release <- struct{}{}
+	if mode := <-modes; mode != "clipboard" {
+		t.Errorf("expected the first recording's profile paste mode, got %q", mode)
+	}
+	ev := waitFor(t, events, EventPasted)
+	if ev.Entry == nil || ev.Entry.Profile != "slack" {
+		t.Errorf("expected the first recording's profile in history, got %+v", ev.Entry)
+	}
+	p.mu.Lock()
+	lang := p.current.language(p.status.Language)
+	p.mu.Unlock()
+	if lang == "de" {
+		t.Error("expected the recording in progress not to use the earlier profile's language")
+	}
+}
+
+func TestRepasteBusy(t *testing.T) {
+	p, _, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.RecordingStarted()
+	if err := p.Repaste("again", 0); err != ErrBusy {
+		t.Errorf("expected ErrBusy while recording, got %v", err)
+	}
+}
+
+func TestRepasteUsesDelay(t *testing.T) {
+	p, events, pastes := newTestPipeline(t, &mockTranscriber{}, nil)
+	if err := p.Repaste("again", 2000); err != nil {
+		t.Fatalf("Repaste: %v", err)
+	}
+	if ev := waitFor(t, events, EventPasting); ev.Status.State != StatePasting {
+		t.Errorf("expected StatePasting, got %s", ev.Status.State)
+	}
+	if call := <-pastes; call.text != "again" || call.delayMs != 2000 {
+		t.Errorf("unexpected paste call: %+v", call)
+	}
+}
+
+func TestListening(t *testing.T) {
+	p, events, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	p.Listening(true)
+	if ev := waitFor(t, events, EventListening); !ev.Status.Armed {
+		t.Error("expected Armed after Listening(true)")
+	}
+	p.Listening(false)
+	if ev := waitFor(t, events, EventListening); ev.Status.Armed {
+		t.Error("expected not Armed after Listening(false)")
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	p, _, _ := newTestPipeline(t, &mockTranscriber{}, nil)
+	events, unsubscribe := p.Subscribe()
+	unsubscribe()
+	unsubscribe() // idempotent
+	p.RecordingStarted()
+	if _, ok := <-events; ok {
+		t.Error("expected closed channel after unsubscribe")
+	}
+}
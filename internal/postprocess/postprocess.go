@@ -100,6 +100,12 @@ func ResolveTone(name string) Tone {
 	return tones["off"]
 }
 
+// HasTone reports whether name is a registered tone, including "off".
+func HasTone(name string) bool {
+	_, ok := tones[strings.ToLower(name)]
+	return ok
+}
+
 // NextTone returns the tone name after the given one in the cycle order.
 func NextTone(current string) string {
 	current = strings.ToLower(current)
@@ -214,6 +214,16 @@ func TestResolveToneUnknown(t *testing.T) {
 	}
 }
 
+func TestHasTone(t *testing.T) {
+	defer saveToneState()()
+	RegisterCustomTones([]config.CustomTone{{Name: "Pirate", Prompt: "arr"}}, nil)
+	for name, want := range map[string]bool{"off": true, "Formal": true, "pirate": true, "shouty": false, "": false} {
+		if got := HasTone(name); got != want {
+			t.Errorf("HasTone(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
 func TestNextToneCycle(t *testing.T) {
 	defer saveToneState()()
 
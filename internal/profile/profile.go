@@ -0,0 +1,104 @@
+// Package profile picks per-application settings from [[profile]] config
+// blocks, based on the focused window.
+package profile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/postprocess"
+)
+
+// Window describes the focused window. Fields the platform cannot report
+// are empty.
+type Window struct {
+	Class   string // X11 WM_CLASS class, Wayland app id, or macOS bundle id
+	Title   string
+	Process string // name of the process that owns the window
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("class=%q title=%q process=%q", w.Class, w.Title, w.Process)
+}
+
+// Matcher finds the profile for a window.
+type Matcher struct {
+	rules []rule
+}
+
+type rule struct {
+	profile               config.Profile
+	class, title, process *regexp.Regexp // nil matches anything
+}
+
+// New compiles profiles into a Matcher. Custom tones must already be
+// registered with postprocess so profile tones can be checked.
+func New(profiles []config.Profile) (*Matcher, error) {
+	m := &Matcher{}
+	for i, p := range profiles {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("profile %d", i+1)
+		}
+		if p.Class == "" && p.Title == "" && p.Process == "" {
+			return nil, fmt.Errorf("profile %q: set class, title, or process", p.Name)
+		}
+		if p.Tone != "" && !postprocess.HasTone(p.Tone) {
+			return nil, fmt.Errorf("profile %q: unknown tone %q", p.Name, p.Tone)
+		}
+		switch p.PasteMode {
+		case "", "type", "clipboard":
+		default:
+			return nil, fmt.Errorf("profile %q: invalid paste_mode %q (want type or clipboard)", p.Name, p.PasteMode)
+		}
+		if p.PasteDelayMs < 0 {
+			return nil, fmt.Errorf("profile %q: paste_delay_ms must not be negative", p.Name)
+		}
+		p.Language = strings.ToLower(p.Language)
+		r := rule{profile: p}
+		var err error
+		if r.class, err = compile(p.Class); err != nil {
+			return nil, fmt.Errorf("profile %q: class: %w", p.Name, err)
+		}
+		if r.title, err = compile(p.Title); err != nil {
+			return nil, fmt.Errorf("profile %q: title: %w", p.Name, err)
+		}
+		if r.process, err = compile(p.Process); err != nil {
+			return nil, fmt.Errorf("profile %q: process: %w", p.Name, err)
+		}
+		m.rules = append(m.rules, r)
+	}
+	return m, nil
+}
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + expr)
+}
+
+// Empty reports whether there are no profiles, so the focused window need
+// not be detected. A nil Matcher is empty.
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// Match returns the first profile matching w, or nil.
+func (m *Matcher) Match(w Window) *config.Profile {
+	if m == nil {
+		return nil
+	}
+	for i := range m.rules {
+		r := &m.rules[i]
+		if matches(r.class, w.Class) && matches(r.title, w.Title) && matches(r.process, w.Process) {
+			return &r.profile
+		}
+	}
+	return nil
+}
+
+func matches(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
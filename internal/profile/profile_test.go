@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+func TestMatch(t *testing.T) {
+	m, err := New([]config.Profile{
+		{Name: "agent", Title: `claude|codex`, Tone: "token-efficient"},
+		{Name: "mail", Class: "thunderbird", Tone: "formal"},
+		{Name: "slack", Class: "^slack$", PasteMode: "clipboard"},
+		{Name: "terminal", Class: "kitty", Process: "^kitty$"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		window Window
+		want   string // "" = no profile
+	}{
+		{"title", Window{Class: "kitty", Title: "✳ Claude Code", Process: "kitty"}, "agent"},
+		{"first match wins", Window{Class: "thunderbird", Title: "codex review"}, "agent"},
+		{"case-insensitive", Window{Class: "Thunderbird", Title: "Inbox"}, "mail"},
+		{"anchored", Window{Class: "slack-desktop"}, ""},
+		{"exact", Window{Class: "Slack"}, "slack"},
+		{"all fields must match", Window{Class: "kitty", Process: "bash"}, ""},
+		{"class and process", Window{Class: "kitty", Process: "kitty"}, "terminal"},
+		{"unknown window", Window{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.Match(tt.window)
+			switch {
+			case got == nil && tt.want != "":
+				t.Errorf("Match(%v) = nil, want %q", tt.window, tt.want)
+			case got != nil && got.Name != tt.want:
+				t.Errorf("Match(%v) = %q, want %q", tt.window, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile config.Profile
+	}{
+		{"no match fields", config.Profile{Name: "x", Tone: "formal"}},
+		{"bad regex", config.Profile{Name: "x", Class: "("}},
+		{"unknown tone", config.Profile{Name: "x", Class: "a", Tone: "pirate"}},
+		{"bad paste mode", config.Profile{Name: "x", Class: "a", PasteMode: "fax"}},
+		{"negative delay", config.Profile{Name: "x", Class: "a", PasteDelayMs: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]config.Profile{tt.profile}); err == nil {
+				t.Errorf("expected an error for %+v", tt.profile)
+			}
+		})
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	var m *Matcher
+	if !m.Empty() || m.Match(Window{Class: "x"}) != nil {
+		t.Error("nil matcher should be empty and match nothing")
+	}
+	m, err := New(nil)
+	if err != nil || !m.Empty() {
+		t.Errorf("New(nil) = %v, %v; want an empty matcher", m, err)
+	}
+}
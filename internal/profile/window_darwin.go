@@ -0,0 +1,43 @@
+//go:build darwin
+
+package profile
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// focusedWindowScript prints the frontmost application's bundle id,
+// process name, and front window title on separate lines. The title needs
+// Accessibility permissions and is left empty without them.
+const focusedWindowScript = `tell application "System Events"
+	set p to first application process whose frontmost is true
+	set t to ""
+	try
+		set t to name of front window of p
+	end try
+	return (bundle identifier of p) & linefeed & (name of p) & linefeed & t
+end tell`
+
+// FocusedWindow describes the frontmost application's front window.
+func FocusedWindow() (Window, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "osascript", "-e", focusedWindowScript).Output()
+	if err != nil {
+		return Window{}, fmt.Errorf("osascript frontmost window: %w", err)
+	}
+	return parseOsascript(string(out)), nil
+}
+
+// parseOsascript reads the output of focusedWindowScript.
+func parseOsascript(out string) Window {
+	lines := strings.SplitN(strings.TrimRight(out, "\n"), "\n", 3)
+	for len(lines) < 3 {
+		lines = append(lines, "")
+	}
+	return Window{Class: lines[0], Process: lines[1], Title: lines[2]}
+}
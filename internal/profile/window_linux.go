@@ -0,0 +1,184 @@
+//go:build linux
+
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FocusedWindow describes the focused window, asking sway or Hyprland over
+// IPC when running under them and X11 (xprop) otherwise.
+func FocusedWindow() (Window, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	switch {
+	case os.Getenv("SWAYSOCK") != "":
+		out, err := exec.CommandContext(ctx, "swaymsg", "-t", "get_tree").Output()
+		if err != nil {
+			return Window{}, fmt.Errorf("swaymsg get_tree: %w", err)
+		}
+		return parseSwayTree(out)
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		out, err := exec.CommandContext(ctx, "hyprctl", "activewindow", "-j").Output()
+		if err != nil {
+			return Window{}, fmt.Errorf("hyprctl activewindow: %w", err)
+		}
+		return parseHyprland(out)
+	case os.Getenv("DISPLAY") != "" && os.Getenv("WAYLAND_DISPLAY") == "":
+		return x11Window(ctx)
+	}
+	return Window{}, errors.New("focused window detection needs X11, sway, or Hyprland")
+}
+
+// swayNode is the part of a sway tree node needed to find the focused
+// window.
+type swayNode struct {
+	Focused          bool   `json:"focused"`
+	Name             string `json:"name"`
+	AppID            string `json:"app_id"`
+	PID              int    `json:"pid"`
+	WindowProperties *struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+// parseSwayTree finds the focused window in swaymsg -t get_tree output.
+// Xwayland windows have a WM_CLASS instead of an app id.
+func parseSwayTree(data []byte) (Window, error) {
+	var root swayNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return Window{}, fmt.Errorf("parse sway tree: %w", err)
+	}
+	n := findFocused(&root)
+	if n == nil {
+		return Window{}, errors.New("sway: no focused window")
+	}
+	w := Window{Class: n.AppID, Title: n.Name, Process: processName(n.PID)}
+	if w.Class == "" && n.WindowProperties != nil {
+		w.Class = n.WindowProperties.Class
+	}
+	return w, nil
+}
+
+func findFocused(n *swayNode) *swayNode {
+	if n.Focused {
+		return n
+	}
+	for _, children := range [][]swayNode{n.Nodes, n.FloatingNodes} {
+		for i := range children {
+			if f := findFocused(&children[i]); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// parseHyprland reads hyprctl activewindow -j output.
+func parseHyprland(data []byte) (Window, error) {
+	var win struct {
+		Class string `json:"class"`
+		Title string `json:"title"`
+		PID   int    `json:"pid"`
+	}
+	if err := json.Unmarshal(data, &win); err != nil {
+		return Window{}, fmt.Errorf("parse hyprctl output: %w", err)
+	}
+	return Window{Class: win.Class, Title: win.Title, Process: processName(win.PID)}, nil
+}
+
+// x11Window asks xprop for the active window and its properties.
+func x11Window(ctx context.Context) (Window, error) {
+	out, err := exec.CommandContext(ctx, "xprop", "-root", "_NET_ACTIVE_WINDOW").Output()
+	if err != nil {
+		return Window{}, fmt.Errorf("xprop _NET_ACTIVE_WINDOW: %w (install with: apt install x11-utils)", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 || !strings.HasPrefix(fields[len(fields)-1], "0x") {
+		return Window{}, fmt.Errorf("xprop: unexpected output %q", strings.TrimSpace(string(out)))
+	}
+	id := strings.TrimSuffix(fields[len(fields)-1], ",")
+	if _, err := strconv.ParseUint(strings.TrimPrefix(id, "0x"), 16, 64); err != nil || id == "0x0" {
+		return Window{}, errors.New("xprop: no active window")
+	}
+	out, err = exec.CommandContext(ctx, "xprop", "-id", id, "WM_CLASS", "_NET_WM_NAME", "WM_NAME", "_NET_WM_PID").Output() //nolint:gosec // id is a parsed hex window id
+	if err != nil {
+		return Window{}, fmt.Errorf("xprop -id %s: %w", id, err)
+	}
+	return parseXprop(string(out)), nil
+}
+
+// xpropString matches one quoted value in xprop output.
+var xpropString = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// parseXprop reads the WM_CLASS, _NET_WM_NAME (or WM_NAME), and
+// _NET_WM_PID lines printed by xprop -id.
+func parseXprop(out string) Window {
+	var w Window
+	var wmName string
+	for line := range strings.Lines(out) {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "(")
+		switch strings.TrimSpace(name) {
+		case "WM_CLASS":
+			// instance, class: the class is the application's name.
+			if strs := xpropStrings(value); len(strs) > 0 {
+				w.Class = strs[len(strs)-1]
+			}
+		case "_NET_WM_NAME":
+			if strs := xpropStrings(value); len(strs) > 0 {
+				w.Title = strs[0]
+			}
+		case "WM_NAME":
+			if strs := xpropStrings(value); len(strs) > 0 {
+				wmName = strs[0]
+			}
+		case "_NET_WM_PID":
+			if pid, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				w.Process = processName(pid)
+			}
+		}
+	}
+	if w.Title == "" {
+		w.Title = wmName
+	}
+	return w
+}
+
+func xpropStrings(value string) []string {
+	var strs []string
+	for _, q := range xpropString.FindAllString(value, -1) {
+		s, err := strconv.Unquote(q)
+		if err != nil {
+			s = q[1 : len(q)-1]
+		}
+		strs = append(strs, s)
+	}
+	return strs
+}
+
+// processName returns the command name of pid, or "" if unknown.
+func processName(pid int) string {
+	if pid <= 0 {
+		return ""
+	}
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
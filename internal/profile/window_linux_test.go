@@ -0,0 +1,85 @@
+//go:build linux
+
+package profile
+
+import "testing"
+
+func TestParseSwayTree(t *testing.T) {
+	tests := []struct {
+		name string
+		tree string
+		want Window
+	}{
+		{
+			name: "wayland app",
+			tree: `{"focused":false,"nodes":[{"nodes":[
+				{"focused":false,"name":"Inbox","app_id":"thunderbird"},
+				{"focused":true,"name":"general | Slack","app_id":"Slack"}]}]}`,
+			want: Window{Class: "Slack", Title: "general | Slack"},
+		},
+		{
+			name: "xwayland floating",
+			tree: `{"nodes":[{"floating_nodes":[
+				{"focused":true,"name":"Steam","app_id":null,"window_properties":{"class":"steam"}}]}]}`,
+			want: Window{Class: "steam", Title: "Steam"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSwayTree([]byte(tt.tree))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseSwayTree([]byte(`{"nodes":[{"focused":false}]}`)); err == nil {
+		t.Error("expected an error without a focused window")
+	}
+}
+
+func TestParseHyprland(t *testing.T) {
+	got, err := parseHyprland([]byte(`{"address":"0x1","class":"kitty","title":"claude","pid":0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Window{Class: "kitty", Title: "claude"}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseXprop(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want Window
+	}{
+		{
+			name: "ewmh",
+			out: `WM_CLASS(STRING) = "Navigator", "firefox"
+_NET_WM_NAME(UTF8_STRING) = "Say \"hi\" — Mozilla Firefox"
+WM_NAME(STRING) = "Say hi"
+_NET_WM_PID:  not found.
+`,
+			want: Window{Class: "firefox", Title: `Say "hi" — Mozilla Firefox`},
+		},
+		{
+			name: "WM_NAME fallback",
+			out: `WM_CLASS(STRING) = "xterm", "XTerm"
+_NET_WM_NAME:  not found.
+WM_NAME(STRING) = "vim notes.txt"
+`,
+			want: Window{Class: "XTerm", Title: "vim notes.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseXprop(tt.out); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package recorder
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-audio/wav"
+)
+
+// WAV format tags accepted by ConvertWAV. WAVE_FORMAT_EXTENSIBLE wraps PCM
+// data in files with more than two channels or more than 16 bits.
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// ConvertWAV decodes an integer PCM WAV file of any channel count and a bit
+// depth of 8, 16, 24, or 32. It downmixes to mono, resamples to targetSR, and
+// re-encodes as 16-bit mono WAV, the format every transcriber expects.
+func ConvertWAV(data []byte, targetSR int) ([]byte, error) {
+	dec := wav.NewDecoder(bytes.NewReader(data))
+	if !dec.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file")
+	}
+	pcmBuf, err := dec.FullPCMBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("decode wav: %w", err)
+	}
+	if dec.WavAudioFormat != wavFormatPCM && dec.WavAudioFormat != wavFormatExtensible {
+		return nil, fmt.Errorf("unsupported WAV encoding %d (only integer PCM is supported)", dec.WavAudioFormat)
+	}
+
+	channels := int(dec.NumChans)
+	if channels < 1 {
+		return nil, fmt.Errorf("invalid channel count %d", channels)
+	}
+	samples := downmixTo16(pcmBuf.Data, channels, int(dec.BitDepth))
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no audio in WAV file")
+	}
+
+	sampleRate := int(dec.SampleRate)
+	if sampleRate != targetSR {
+		samples, err = Resample(samples, float64(sampleRate), float64(targetSR))
+		if err != nil {
+			return nil, fmt.Errorf("resample: %w", err)
+		}
+	}
+
+	wavData, err := EncodeWAV(samples, targetSR)
+	if err != nil {
+		return nil, fmt.Errorf("encode wav: %w", err)
+	}
+	return wavData, nil
+}
+
+// downmixTo16 averages interleaved frames of the given channel count into
+// mono and scales samples of bitDepth bits to signed 16-bit. 8-bit WAV
+// samples are unsigned and are re-centered around zero.
+func downmixTo16(data []int, channels, bitDepth int) []int16 {
+	frames := len(data) / channels
+	out := make([]int16, frames)
+	for i := 0; i < frames; i++ {
+		var sum int64
+		for c := 0; c < channels; c++ {
+			v := int64(data[i*channels+c])
+			switch {
+			case bitDepth == 8:
+				v = (v - 128) << 8
+			case bitDepth > 16:
+				v >>= uint(bitDepth - 16) //nolint:gosec // bitDepth > 16 here, shift is positive
+			}
+			sum += v
+		}
+		out[i] = int16(sum / int64(channels)) //nolint:gosec // average of values scaled to int16 range fits in int16
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package recorder
+
+import (
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// encodeTestWAV writes interleaved samples as a PCM WAV with the given layout.
+func encodeTestWAV(t *testing.T, data []int, sampleRate, bitDepth, channels int) []byte {
+	t.Helper()
+	ws := &writeSeeker{}
+	enc := wav.NewEncoder(ws, sampleRate, bitDepth, channels, 1)
+	buf := &audio.IntBuffer{
+		Data:           data,
+		Format:         &audio.Format{SampleRate: sampleRate, NumChannels: channels},
+		SourceBitDepth: bitDepth,
+	}
+	if err := enc.Write(buf); err != nil {
+		t.Fatalf("write wav: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("close wav: %v", err)
+	}
+	return ws.buf
+}
+
+func TestConvertWAVStereo24BitResamples(t *testing.T) {
+	// 0.5s of 48 kHz stereo 24-bit: left at +2^20, right at -2^19.
+	frames := 24000
+	data := make([]int, frames*2)
+	for i := 0; i < frames; i++ {
+		data[2*i] = 1 << 20
+		data[2*i+1] = -(1 << 19)
+	}
+	out, err := ConvertWAV(encodeTestWAV(t, data, 48000, 24, 2), 16000)
+	if err != nil {
+		t.Fatalf("ConvertWAV: %v", err)
+	}
+
+	sr, ch, bits, err := ValidateWAVHeader(out)
+	if err != nil {
+		t.Fatalf("ValidateWAVHeader: %v", err)
+	}
+	if sr != 16000 || ch != 1 || bits != 16 {
+		t.Errorf("got %d Hz, %d ch, %d bit; want 16000 Hz, 1 ch, 16 bit", sr, ch, bits)
+	}
+
+	samples, _, err := DecodeWAV(out)
+	if err != nil {
+		t.Fatalf("DecodeWAV: %v", err)
+	}
+	if diff := len(samples) - 8000; diff < -50 || diff > 50 {
+		t.Errorf("expected ~8000 samples, got %d", len(samples))
+	}
+	// (2^20 - 2^19) / 2 at 24 bits = 2^18, which is 2^10 at 16 bits.
+	mid := samples[len(samples)/2]
+	if mid < 1000 || mid > 1048 {
+		t.Errorf("expected mid sample near 1024, got %d", mid)
+	}
+}
+
+func TestConvertWAV8BitMono(t *testing.T) {
+	data := make([]int, 1600)
+	for i := range data {
+		data[i] = 192 // unsigned 8-bit: +64 from center
+	}
+	out, err := ConvertWAV(encodeTestWAV(t, data, 16000, 8, 1), 16000)
+	if err != nil {
+		t.Fatalf("ConvertWAV: %v", err)
+	}
+	samples, _, err := DecodeWAV(out)
+	if err != nil {
+		t.Fatalf("DecodeWAV: %v", err)
+	}
+	if len(samples) != 1600 {
+		t.Fatalf("expected 1600 samples, got %d", len(samples))
+	}
+	if samples[0] != 64<<8 {
+		t.Errorf("expected sample %d, got %d", 64<<8, samples[0])
+	}
+}
+
+func TestConvertWAVInvalid(t *testing.T) {
+	if _, err := ConvertWAV([]byte("not a wav file at all, just text padding it out"), 16000); err == nil {
+		t.Error("expected error for invalid WAV data")
+	}
+}
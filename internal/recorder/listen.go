@@ -0,0 +1,244 @@
+package recorder
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// Recording triggers accepted by the audio.trigger config option.
+const (
+	TriggerHotkey = "hotkey" // record while the hotkey says so
+	TriggerVAD    = "vad"    // keep the mic open and record whenever speech is heard
+)
+
+// ValidateTrigger returns an error if trigger is not a known recording
+// trigger. An empty trigger is treated as TriggerHotkey.
+func ValidateTrigger(trigger string) error {
+	switch strings.ToLower(strings.TrimSpace(trigger)) {
+	case "", TriggerHotkey, TriggerVAD:
+		return nil
+	default:
+		return fmt.Errorf("unknown audio trigger: %s (valid: hotkey, vad)", trigger)
+	}
+}
+
+// ListenConfig controls voice-activated segmentation while armed.
+type ListenConfig struct {
+	Threshold  float64 // RMS level (0.0–1.0) that counts as voice
+	StartMs    int     // voice required before a segment starts
+	HangoverMs int     // silence required before a segment ends
+	PreRollMs  int     // audio kept from before the segment started
+}
+
+// Segmenter splits a continuous stream of mono audio blocks into voice
+// segments. It is a pure state machine so it can be driven from tests;
+// Recorder.Arm feeds it with live microphone blocks.
+type Segmenter struct {
+	cfg        ListenConfig
+	sampleRate int
+	maxSamples int // force a segment to end at this length (0 = unlimited)
+
+	active  bool
+	voiceMs int     // consecutive voiced time while idle
+	quietMs int     // consecutive silent time while in a segment
+	pending []int16 // idle: recent audio kept for pre-roll
+	segment []int16 // active: audio of the current segment
+}
+
+// NewSegmenter creates a Segmenter for audio at sampleRate. Segments are cut
+// at maxSamples even if speech continues; 0 disables the limit.
+func NewSegmenter(cfg ListenConfig, sampleRate, maxSamples int) *Segmenter {
+	return &Segmenter{cfg: cfg, sampleRate: sampleRate, maxSamples: maxSamples}
+}
+
+// Active reports whether a segment is in progress.
+func (s *Segmenter) Active() bool {
+	return s.active
+}
+
+// Feed processes one block of mono samples with the given RMS level.
+// started is true when this block began a new segment. When a segment
+// ends, its samples (including pre-roll) are returned in segment.
+func (s *Segmenter) Feed(block []int16, level float64) (started bool, segment []int16) {
+	blockMs := int(samplesDuration(len(block), s.sampleRate) / time.Millisecond)
+	voiced := level >= s.cfg.Threshold
+
+	if !s.active {
+		s.pending = append(s.pending, block...)
+		if voiced {
+			s.voiceMs += blockMs
+		} else {
+			s.voiceMs = 0
+		}
+		if s.voiceMs < s.cfg.StartMs || s.voiceMs == 0 {
+			// Keep only the pre-roll window plus any voiced run in progress.
+			keep := s.sampleRate * (s.cfg.PreRollMs + s.voiceMs) / 1000
+			if len(s.pending) > keep {
+				s.pending = append(s.pending[:0], s.pending[len(s.pending)-keep:]...)
+			}
+			return false, nil
+		}
+		s.active = true
+		s.quietMs = 0
+		s.segment = s.pending
+		s.pending = nil
+		started = true
+	} else {
+		s.segment = append(s.segment, block...)
+		if voiced {
+			s.quietMs = 0
+		} else {
+			s.quietMs += blockMs
+		}
+	}
+
+	if s.quietMs >= s.cfg.HangoverMs || (s.maxSamples > 0 && len(s.segment) >= s.maxSamples) {
+		return started, s.Flush()
+	}
+	return started, nil
+}
+
+// Flush ends the current segment, if any, and returns its samples.
+func (s *Segmenter) Flush() []int16 {
+	if !s.active {
+		s.pending = nil
+		s.voiceMs = 0
+		return nil
+	}
+	segment := s.segment
+	s.active = false
+	s.segment = nil
+	s.voiceMs = 0
+	s.quietMs = 0
+	return segment
+}
+
+// listenEvent is sent from listenLoop to the delivery goroutine.
+type listenEvent struct {
+	started bool    // a segment began
+	samples []int16 // a segment ended (nil if this is a start event)
+}
+
+// Arm opens the input stream and keeps it open, cutting voice segments with
+// a Segmenter. onStart is called when a segment begins and onSegment with
+// the WAV-encoded segment (or an error such as ErrNoSpeech) when it ends.
+// Callbacks run on a separate goroutine so slow consumers never stall
+// capture. Returns an error if already recording or armed.
+func (r *Recorder) Arm(cfg ListenConfig, onStart func(), onSegment func([]byte, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.recording {
+		return fmt.Errorf("already recording")
+	}
+	if r.armed {
+		return fmt.Errorf("already armed")
+	}
+
+	stream, inputBuf, channels, err := r.openInput()
+	if err != nil {
+		return err
+	}
+
+	r.armed = true
+	r.armDone = make(chan struct{})
+	r.armLoopDone = make(chan struct{})
+
+	events := make(chan listenEvent, 16)
+	seg := NewSegmenter(cfg, int(r.nativeSR), int(r.nativeSR)*r.maxDurationSec)
+	go r.listenLoop(stream, inputBuf, channels, seg, events, r.armDone, r.armLoopDone)
+	go r.deliverSegments(events, onStart, onSegment)
+
+	return nil
+}
+
+// Disarm stops voice-activated listening. A segment in progress is ended
+// and delivered as usual.
+func (r *Recorder) Disarm() error {
+	r.mu.Lock()
+	if !r.armed {
+		r.mu.Unlock()
+		return fmt.Errorf("not armed")
+	}
+	r.armed = false
+	done := r.armDone
+	loopDone := r.armLoopDone
+	r.mu.Unlock()
+
+	close(done)
+	<-loopDone
+	atomic.StoreUint64(&r.audioLevel, math.Float64bits(0))
+	return nil
+}
+
+// IsArmed returns whether voice-activated listening is running.
+func (r *Recorder) IsArmed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.armed
+}
+
+func (r *Recorder) listenLoop(stream *portaudio.Stream, inputBuf []int16, channels int, seg *Segmenter, events chan<- listenEvent, done, loopDone chan struct{}) {
+	defer close(loopDone)
+	defer close(events)
+	defer func() {
+		_ = stream.Stop()
+		_ = stream.Close()
+		if samples := seg.Flush(); samples != nil {
+			events <- listenEvent{samples: samples}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if err := stream.Read(); err != nil {
+			return
+		}
+
+		block := make([]int16, 0, len(inputBuf)/channels)
+		if channels == 2 {
+			block = append(block, DownmixStereoToMono(inputBuf)...)
+		} else {
+			block = append(block, inputBuf...)
+		}
+		level := computeRMS(block, 1)
+		atomic.StoreUint64(&r.audioLevel, math.Float64bits(level))
+
+		started, samples := seg.Feed(block, level)
+		if started {
+			events <- listenEvent{started: true}
+		}
+		if samples != nil {
+			events <- listenEvent{samples: samples}
+		}
+	}
+}
+
+// deliverSegments encodes finished segments and runs the Arm callbacks.
+func (r *Recorder) deliverSegments(events <-chan listenEvent, onStart func(), onSegment func([]byte, error)) {
+	for ev := range events {
+		if ev.started {
+			if onStart != nil {
+				onStart()
+			}
+			continue
+		}
+		r.mu.Lock()
+		nativeSR, targetSR, vad, logger := r.nativeSR, r.targetSR, r.vad, r.logger
+		r.mu.Unlock()
+		wavData, err := encodeRecording(ev.samples, nativeSR, targetSR, vad, logger)
+		if onSegment != nil {
+			onSegment(wavData, err)
+		}
+	}
+}
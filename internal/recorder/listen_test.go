@@ -0,0 +1,102 @@
+package recorder
+
+import "testing"
+
+// feedBlocks feeds 100ms blocks at the given levels and collects the
+// start events and finished segments.
+func feedBlocks(s *Segmenter, levels []float64) (starts int, segments [][]int16) {
+	const blockLen = 1600 // 100ms at 16 kHz
+	for _, level := range levels {
+		block := make([]int16, blockLen)
+		started, seg := s.Feed(block, level)
+		if started {
+			starts++
+		}
+		if seg != nil {
+			segments = append(segments, seg)
+		}
+	}
+	return starts, segments
+}
+
+func testListenConfig() ListenConfig {
+	return ListenConfig{Threshold: 0.05, StartMs: 200, HangoverMs: 300, PreRollMs: 200}
+}
+
+func TestSegmenterIgnoresShortBlips(t *testing.T) {
+	s := NewSegmenter(testListenConfig(), 16000, 0)
+	starts, segments := feedBlocks(s, []float64{0, 0.1, 0, 0.1, 0, 0, 0})
+	if starts != 0 || len(segments) != 0 {
+		t.Errorf("expected no segments for 100ms blips, got starts=%d segments=%d", starts, len(segments))
+	}
+}
+
+func TestSegmenterCutsSegmentAfterHangover(t *testing.T) {
+	s := NewSegmenter(testListenConfig(), 16000, 0)
+	// 500ms silence, 500ms voice, 200ms pause (within hangover), 300ms voice, 300ms silence.
+	levels := []float64{0, 0, 0, 0, 0, 0.1, 0.1, 0.1, 0.1, 0.1, 0, 0, 0.1, 0.1, 0.1, 0, 0, 0}
+	starts, segments := feedBlocks(s, levels)
+	if starts != 1 {
+		t.Fatalf("expected 1 segment start, got %d", starts)
+	}
+	if len(segments) != 1 {
+		t.Fatalf("expected 1 segment, got %d", len(segments))
+	}
+	// 200ms pre-roll + 1300ms from the first voiced block to the end of the hangover.
+	if got, want := len(segments[0]), 1600*15; got != want {
+		t.Errorf("segment length = %d samples, want %d", got, want)
+	}
+	if s.Active() {
+		t.Error("expected segmenter idle after hangover")
+	}
+}
+
+func TestSegmenterMaxDuration(t *testing.T) {
+	s := NewSegmenter(testListenConfig(), 16000, 1600*10)
+	levels := make([]float64, 25)
+	for i := range levels {
+		levels[i] = 0.1
+	}
+	_, segments := feedBlocks(s, levels)
+	if len(segments) < 2 {
+		t.Fatalf("expected continuous speech to be cut into several segments, got %d", len(segments))
+	}
+	for i, seg := range segments {
+		if len(seg) > 1600*10 {
+			t.Errorf("segment %d has %d samples, exceeds max %d", i, len(seg), 1600*10)
+		}
+	}
+}
+
+func TestSegmenterFlush(t *testing.T) {
+	s := NewSegmenter(testListenConfig(), 16000, 0)
+	feedBlocks(s, []float64{0.1, 0.1, 0.1})
+	if !s.Active() {
+		t.Fatal("expected active segment")
+	}
+	if seg := s.Flush(); len(seg) != 1600*3 {
+		t.Errorf("flushed %d samples, want %d", len(seg), 1600*3)
+	}
+	if s.Flush() != nil {
+		t.Error("expected nil from second flush")
+	}
+}
+
+func TestValidateTrigger(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"", false},
+		{"hotkey", false},
+		{"vad", false},
+		{"VAD", false},
+		{"clap", true},
+	}
+	for _, tt := range tests {
+		err := ValidateTrigger(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTrigger(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"log"
 	"math"
 	"sync"
 	"sync/atomic"
@@ -29,10 +30,20 @@ type Recorder struct {
 	maxDurationSec int
 	startTime      time.Time
 	truncated      bool
-	audioLevel     uint64 // atomic float64 bits; RMS of last chunk (0.0–1.0)
+	audioLevel     uint64       // atomic float64 bits; RMS of last chunk (0.0–1.0)
+	chunkSamples   int          // streaming: native samples per emitted chunk (0 = off)
+	chunkStart     int          // streaming: index in buf where the next chunk begins
+	rawChunks      chan []int16 // streaming: native-rate chunks awaiting encoding
+	heldChunks     int          // streaming: chunks held back because the encoder was behind
+	vad            VADConfig
+	logger         *log.Logger
+	armed          bool          // voice-activated listening is running
+	armDone        chan struct{} // closed when listenLoop should exit
+	armLoopDone    chan struct{} // closed when listenLoop has exited
 }
 
-// New creates a Recorder. Call portaudio.Initialize() before using this.
+// New creates a Recorder. Recordings stop growing after maxDurationSec
+// seconds; 0 means no limit. Call portaudio.Initialize() before using this.
 func New(targetSampleRate, maxDurationSec int) (*Recorder, error) {
 	defIn, err := portaudio.DefaultInputDevice()
 	if err != nil {
@@ -47,19 +58,84 @@ func New(targetSampleRate, maxDurationSec int) (*Recorder, error) {
 	}, nil
 }
 
+// SetVAD configures voice activity detection for subsequent recordings.
+// When enabled, Stop trims leading and trailing silence and returns
+// ErrNoSpeech for recordings without speech. logger may be nil.
+func (r *Recorder) SetVAD(cfg VADConfig, logger *log.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vad = cfg
+	r.logger = logger
+}
+
 // Start begins capturing audio. Returns an error if already recording.
 func (r *Recorder) Start() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.start(0)
+}
+
+// StartStream begins capturing audio like Start and additionally emits
+// WAV-encoded chunks of up to about chunkSec seconds (at the target sample
+// rate) on the returned channel while recording, each cut at the quietest
+// point near its end (see streamCut). The remaining audio is emitted
+// as a final chunk when Stop is called, after which the channel is closed.
+// Stop still returns the complete recording.
+func (r *Recorder) StartStream(chunkSec int) (<-chan []byte, error) {
+	if chunkSec < 1 {
+		chunkSec = 1
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	if err := r.start(int(r.nativeSR) * chunkSec); err != nil {
+		return nil, err
+	}
+	out := make(chan []byte, 8)
+	go encodeChunks(r.rawChunks, out, r.nativeSR, r.targetSR)
+	return out, nil
+}
+
+// start opens the input stream and launches readLoop. chunkSamples > 0
+// enables streaming chunk emission. Must be called with r.mu held.
+func (r *Recorder) start(chunkSamples int) error {
 	if r.recording {
 		return fmt.Errorf("already recording")
 	}
+	if r.armed {
+		return fmt.Errorf("recorder is armed for voice-activated recording")
+	}
 
 	r.buf = nil
 	r.truncated = false
 	r.startTime = time.Now()
+	r.chunkSamples = 0
+	r.chunkStart = 0
+	r.rawChunks = nil
+	r.heldChunks = 0
+
+	stream, inputBuf, channels, err := r.openInput()
+	if err != nil {
+		return err
+	}
 
+	r.stream = stream
+	r.recording = true
+	r.done = make(chan struct{})
+	r.loopDone = make(chan struct{})
+	if chunkSamples > 0 {
+		r.chunkSamples = chunkSamples
+		r.rawChunks = make(chan []int16, 64)
+	}
+
+	go r.readLoop(stream, inputBuf, channels, r.done, r.loopDone)
+
+	return nil
+}
+
+// openInput opens and starts the default input stream with ~100ms buffers.
+// It returns the stream, its read buffer, and the channel count (1 or 2).
+func (r *Recorder) openInput() (*portaudio.Stream, []int16, int, error) {
 	channels := r.nativeChannels
 	if channels > 2 {
 		channels = 2
@@ -73,27 +149,19 @@ func (r *Recorder) Start() error {
 
 	stream, err := portaudio.OpenDefaultStream(channels, 0, r.nativeSR, framesPerBuffer, &inputBuf)
 	if err != nil {
-		return fmt.Errorf("open stream: %w", err)
+		return nil, nil, 0, fmt.Errorf("open stream: %w", err)
 	}
 
 	if err := stream.Start(); err != nil {
 		_ = stream.Close()
-		return fmt.Errorf("start stream: %w", err)
+		return nil, nil, 0, fmt.Errorf("start stream: %w", err)
 	}
-
-	r.stream = stream
-	r.recording = true
-	r.done = make(chan struct{})
-	r.loopDone = make(chan struct{})
-
-	go r.readLoop(stream, inputBuf, channels, r.done, r.loopDone)
-
-	return nil
+	return stream, inputBuf, channels, nil
 }
 
 func (r *Recorder) readLoop(stream *portaudio.Stream, inputBuf []int16, channels int, done, loopDone chan struct{}) {
 	defer close(loopDone)
-	maxSamples := int(r.nativeSR) * r.maxDurationSec
+	maxSamples := int(r.nativeSR) * r.maxDurationSec // 0 = unlimited
 
 	for {
 		select {
@@ -124,7 +192,11 @@ func (r *Recorder) readLoop(stream *portaudio.Stream, inputBuf []int16, channels
 
 		atomic.StoreUint64(&r.audioLevel, math.Float64bits(computeRMS(inputBuf, channels)))
 
-		if len(r.buf) >= maxSamples {
+		if r.chunkSamples > 0 {
+			r.emitChunkLocked()
+		}
+
+		if maxSamples > 0 && len(r.buf) >= maxSamples {
 			r.truncated = true
 			r.recording = false
 			r.mu.Unlock()
@@ -134,6 +206,64 @@ func (r *Recorder) readLoop(stream *portaudio.Stream, inputBuf []int16, channels
 	}
 }
 
+// emitChunkLocked sends the next stream chunk to the encoder once
+// chunkSamples of audio are pending. It never blocks: if the encoder is
+// behind, the audio stays pending and goes out with a later chunk. Must be
+// called with r.mu held.
+func (r *Recorder) emitChunkLocked() {
+	cut := streamCut(r.buf, r.chunkStart, r.chunkSamples, int(r.nativeSR))
+	if cut == r.chunkStart {
+		return
+	}
+	chunk := make([]int16, cut-r.chunkStart)
+	copy(chunk, r.buf[r.chunkStart:cut])
+	select {
+	case r.rawChunks <- chunk:
+		r.chunkStart = cut
+	default:
+		r.heldChunks++
+	}
+}
+
+// streamCut returns where the stream chunk starting at start should end in
+// buf: at the quietest 20ms frame in the second half of the pending audio,
+// so that a word is less likely to be split between two chunks. It returns
+// start while fewer than chunkSamples samples are pending.
+func streamCut(buf []int16, start, chunkSamples, sampleRate int) int {
+	if chunkSamples <= 0 || len(buf)-start < chunkSamples {
+		return start
+	}
+	frameLen := max(sampleRate*vadFrameMs/1000, 1)
+	cut, quietest := len(buf), -1.0
+	for f := len(buf) - frameLen; f >= start+chunkSamples/2; f -= frameLen {
+		if level := computeRMS(buf[f:f+frameLen], 1); quietest < 0 || level < quietest {
+			cut, quietest = f+frameLen/2, level
+		}
+	}
+	return cut
+}
+
+// encodeChunks resamples and WAV-encodes each native-rate chunk from raw and
+// sends it on out. It closes out once raw is closed. Chunks that fail to
+// convert are dropped.
+func encodeChunks(raw <-chan []int16, out chan<- []byte, nativeSR float64, targetSR int) {
+	defer close(out)
+	for samples := range raw {
+		if int(nativeSR) != targetSR {
+			resampled, err := Resample(samples, nativeSR, float64(targetSR))
+			if err != nil {
+				continue
+			}
+			samples = resampled
+		}
+		wavData, err := EncodeWAV(samples, targetSR)
+		if err != nil {
+			continue
+		}
+		out <- wavData
+	}
+}
+
 // Stop stops recording and returns the WAV-encoded audio data.
 // The second return value indicates if recording was truncated due to max duration.
 func (r *Recorder) Stop() ([]byte, bool, error) {
@@ -172,27 +302,68 @@ func (r *Recorder) Stop() ([]byte, bool, error) {
 	truncated := r.truncated
 	nativeSR := r.nativeSR
 	targetSR := r.targetSR
+	rawChunks := r.rawChunks
+	chunkStart := r.chunkStart
+	heldChunks := r.heldChunks
+	vad := r.vad
+	logger := r.logger
+	r.rawChunks = nil
+	r.chunkSamples = 0
 	r.mu.Unlock()
 
+	if heldChunks > 0 && logger != nil {
+		logger.Printf("stream: encoder fell behind %d times; the audio went out with later chunks", heldChunks)
+	}
+
+	// Flush the unsent tail to the streaming consumer and end the stream.
+	if rawChunks != nil {
+		if chunkStart < len(samples) {
+			tail := make([]int16, len(samples)-chunkStart)
+			copy(tail, samples[chunkStart:])
+			rawChunks <- tail
+		}
+		close(rawChunks)
+	}
+
 	if len(samples) == 0 {
 		return nil, truncated, fmt.Errorf("no audio captured")
 	}
 
+	wavData, err := encodeRecording(samples, nativeSR, targetSR, vad, logger)
+	return wavData, truncated, err
+}
+
+// encodeRecording applies voice activity trimming (if enabled), resamples
+// native-rate mono samples to targetSR, and WAV-encodes the result.
+func encodeRecording(samples []int16, nativeSR float64, targetSR int, vad VADConfig, logger *log.Logger) ([]byte, error) {
+	if vad.Enabled {
+		trimmed, res := TrimSilence(samples, int(nativeSR), vad)
+		if logger != nil {
+			logger.Printf("recording vad: kept %s of %s (trimmed %s, speech=%v)",
+				res.Kept.Round(time.Millisecond), res.Original.Round(time.Millisecond),
+				(res.Original - res.Kept).Round(time.Millisecond), res.Speech)
+		}
+		if !res.Speech {
+			return nil, ErrNoSpeech
+		}
+		samples = trimmed
+	}
+
 	// Resample using polyphase FIR if needed
 	if int(nativeSR) != targetSR {
 		resampled, err := Resample(samples, nativeSR, float64(targetSR))
 		if err != nil {
-			return nil, truncated, fmt.Errorf("resample: %w", err)
+			return nil, fmt.Errorf("resample: %w", err)
 		}
 		samples = resampled
 	}
 
 	wavData, err := EncodeWAV(samples, targetSR)
 	if err != nil {
-		return nil, truncated, fmt.Errorf("encode wav: %w", err)
+		return nil, fmt.Errorf("encode wav: %w", err)
 	}
 
-	return wavData, truncated, nil
+	return wavData, nil
 }
 
 // IsRecording returns whether the recorder is currently capturing.
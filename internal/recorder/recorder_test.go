@@ -142,3 +142,77 @@ func TestValidateWAVHeaderTooShort(t *testing.T) {
 		t.Error("expected error for short data")
 	}
 }
+
+func TestEncodeChunks(t *testing.T) {
+	raw := make(chan []int16, 2)
+	out := make(chan []byte, 2)
+	raw <- make([]int16, 48000)
+	raw <- make([]int16, 4800)
+	close(raw)
+
+	encodeChunks(raw, out, 48000, 16000)
+
+	var sizes []int
+	for wavData := range out {
+		samples, sr, err := DecodeWAV(wavData)
+		if err != nil {
+			t.Fatalf("decode chunk: %v", err)
+		}
+		if sr != 16000 {
+			t.Errorf("expected chunk sample rate 16000, got %d", sr)
+		}
+		sizes = append(sizes, len(samples))
+	}
+	if len(sizes) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(sizes))
+	}
+	if sizes[0] < 15800 || sizes[0] > 16200 {
+		t.Errorf("expected ~16000 samples in first chunk, got %d", sizes[0])
+	}
+}
+
+func TestStreamCut(t *testing.T) {
+	const sr = 16000
+	// 0.7s of speech, a 100ms pause, then 0.5s more speech.
+	buf := append(speech(sr*7/10), make([]int16, sr/10)...)
+	buf = append(buf, speech(sr/2)...)
+
+	if cut := streamCut(buf[:sr/2], 0, sr, sr); cut != 0 {
+		t.Errorf("expected no cut before a chunk is pending, got %d", cut)
+	}
+	cut := streamCut(buf, 0, sr, sr)
+	if cut < sr*7/10 || cut > sr*8/10 {
+		t.Errorf("expected the cut in the pause at 0.7-0.8s, got %.3fs", float64(cut)/sr)
+	}
+	// Without a pause, the cut still falls in the second half.
+	loud := speech(sr * 2)
+	if cut := streamCut(loud, sr/2, sr, sr); cut < sr || cut > len(loud) {
+		t.Errorf("expected the cut after half a chunk, got %d", cut)
+	}
+}
+
+func TestEmitChunkHoldsAudioWhenEncoderIsBehind(t *testing.T) {
+	const sr = 16000
+	r := &Recorder{nativeSR: sr, chunkSamples: sr, rawChunks: make(chan []int16, 1)}
+	r.buf = speech(sr)
+	r.emitChunkLocked()
+	first := r.chunkStart
+	if first == 0 {
+		t.Fatal("expected the first chunk to be sent")
+	}
+
+	// The encoder has not taken the first chunk yet.
+	r.buf = append(r.buf, speech(sr)...)
+	r.emitChunkLocked()
+	if r.chunkStart != first || r.heldChunks != 1 {
+		t.Fatalf("expected the chunk held back, got start %d and %d held", r.chunkStart, r.heldChunks)
+	}
+
+	<-r.rawChunks
+	r.buf = append(r.buf, speech(sr/2)...)
+	r.emitChunkLocked()
+	chunk := <-r.rawChunks
+	if len(chunk) != r.chunkStart-first || r.chunkStart <= first+sr {
+		t.Errorf("expected the held audio in the next chunk, got %d samples ending at %d", len(chunk), r.chunkStart)
+	}
+}
@@ -0,0 +1,96 @@
+package recorder
+
+import (
+	"fmt"
+	"time"
+)
+
+// SplitConfig controls how long recordings are cut into chunks for
+// transcription.
+type SplitConfig struct {
+	MaxChunkSec      int     // longest chunk to produce
+	OverlapMs        int     // audio repeated across a cut that is not at silence
+	SearchMs         int     // how far back from MaxChunkSec to look for silence
+	SilenceThreshold float64 // frame RMS (0.0–1.0) below which a cut is clean
+}
+
+// Chunk is a slice of a longer recording. Overlap is true when the chunk
+// starts with audio repeated from the end of the previous chunk, so its
+// transcript may duplicate the previous chunk's last words.
+type Chunk struct {
+	Samples []int16
+	Start   int // index of the first sample in the recording
+	Overlap bool
+}
+
+// WAVChunk is a WAV-encoded Chunk.
+type WAVChunk struct {
+	WAV     []byte
+	Offset  time.Duration // where the chunk starts in the recording
+	Overlap bool
+}
+
+// Split cuts mono samples into chunks no longer than cfg.MaxChunkSec. Each
+// cut is placed at the quietest 20ms frame within cfg.SearchMs before the
+// limit; if that frame is still louder than cfg.SilenceThreshold (the
+// speaker never paused), the cut falls at the limit and the next chunk
+// starts cfg.OverlapMs earlier so no word is lost at the boundary.
+func Split(samples []int16, sampleRate int, cfg SplitConfig) []Chunk {
+	maxLen := sampleRate * cfg.MaxChunkSec
+	if maxLen <= 0 || len(samples) <= maxLen {
+		return []Chunk{{Samples: samples}}
+	}
+	frameLen := max(sampleRate*vadFrameMs/1000, 1)
+	searchLen := min(sampleRate*cfg.SearchMs/1000, maxLen/2)
+	overlap := min(sampleRate*cfg.OverlapMs/1000, maxLen/2)
+
+	var chunks []Chunk
+	start := 0
+	overlapped := false
+	for len(samples)-start > maxLen {
+		limit := start + maxLen
+
+		// Find the quietest frame in [limit-searchLen, limit).
+		cut, quietest := limit, -1.0
+		for f := limit - frameLen; f >= limit-searchLen && f > start; f -= frameLen {
+			if level := computeRMS(samples[f:f+frameLen], 1); quietest < 0 || level < quietest {
+				cut, quietest = f+frameLen/2, level
+			}
+		}
+
+		if quietest >= 0 && quietest < cfg.SilenceThreshold {
+			chunks = append(chunks, Chunk{Samples: samples[start:cut], Start: start, Overlap: overlapped})
+			start, overlapped = cut, false
+			continue
+		}
+		chunks = append(chunks, Chunk{Samples: samples[start:limit], Start: start, Overlap: overlapped})
+		start, overlapped = limit-overlap, overlap > 0
+	}
+	return append(chunks, Chunk{Samples: samples[start:], Start: start, Overlap: overlapped})
+}
+
+// SplitWAV decodes a mono 16-bit WAV recording, splits it with Split, and
+// re-encodes each chunk at the original sample rate.
+func SplitWAV(wavData []byte, cfg SplitConfig) ([]WAVChunk, error) {
+	samples, sampleRate, err := DecodeWAV(wavData)
+	if err != nil {
+		return nil, err
+	}
+	chunks := Split(samples, sampleRate, cfg)
+	if len(chunks) == 1 {
+		return []WAVChunk{{WAV: wavData}}, nil
+	}
+	out := make([]WAVChunk, len(chunks))
+	for i, c := range chunks {
+		data, err := EncodeWAV(c.Samples, sampleRate)
+		if err != nil {
+			return nil, fmt.Errorf("encode chunk %d: %w", i, err)
+		}
+		out[i] = WAVChunk{
+			WAV:     data,
+			Offset:  time.Duration(c.Start) * time.Second / time.Duration(sampleRate),
+			Overlap: c.Overlap,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+)
+
+// speech returns n samples of a loud alternating signal.
+func speech(n int) []int16 {
+	s := make([]int16, n)
+	for i := range s {
+		if i%2 == 0 {
+			s[i] = 8000
+		} else {
+			s[i] = -8000
+		}
+	}
+	return s
+}
+
+func splitTestConfig() SplitConfig {
+	return SplitConfig{MaxChunkSec: 10, OverlapMs: 1000, SearchMs: 3000, SilenceThreshold: 0.01}
+}
+
+func TestSplitShortAudioIsOneChunk(t *testing.T) {
+	samples := speech(16000 * 5)
+	chunks := Split(samples, 16000, splitTestConfig())
+	if len(chunks) != 1 || len(chunks[0].Samples) != len(samples) || chunks[0].Overlap {
+		t.Fatalf("expected a single unmodified chunk, got %d chunks", len(chunks))
+	}
+}
+
+func TestSplitAtSilence(t *testing.T) {
+	// 8s speech, 0.5s silence, 8s speech: the cut should land in the pause.
+	var samples []int16
+	samples = append(samples, speech(16000*8)...)
+	samples = append(samples, make([]int16, 8000)...)
+	samples = append(samples, speech(16000*8)...)
+
+	chunks := Split(samples, 16000, splitTestConfig())
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	cut := len(chunks[0].Samples)
+	if cut < 16000*8 || cut > 16000*8+8000 {
+		t.Errorf("expected cut inside the pause (128000–136000), got %d", cut)
+	}
+	if chunks[1].Overlap {
+		t.Error("expected no overlap for a cut at silence")
+	}
+	if len(chunks[0].Samples)+len(chunks[1].Samples) != len(samples) {
+		t.Error("expected chunks to cover the recording exactly")
+	}
+}
+
+func TestSplitWithoutSilenceOverlaps(t *testing.T) {
+	samples := speech(16000 * 25)
+	chunks := Split(samples, 16000, splitTestConfig())
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	total := 0
+	for i, c := range chunks {
+		if len(c.Samples) > 16000*10 {
+			t.Errorf("chunk %d has %d samples, exceeds 10s", i, len(c.Samples))
+		}
+		if (i > 0) != c.Overlap {
+			t.Errorf("chunk %d: Overlap = %v", i, c.Overlap)
+		}
+		if i > 0 && c.Start != chunks[i-1].Start+len(chunks[i-1].Samples)-16000 {
+			t.Errorf("chunk %d: Start = %d, expected 1s before the previous chunk's end", i, c.Start)
+		}
+		total += len(c.Samples)
+	}
+	// Two cuts, each repeating 1s of audio.
+	if want := len(samples) + 2*16000; total != want {
+		t.Errorf("expected %d samples including overlap, got %d", want, total)
+	}
+}
+
+func TestSplitWAV(t *testing.T) {
+	wavData, err := EncodeWAV(speech(16000*25), 16000)
+	if err != nil {
+		t.Fatalf("EncodeWAV: %v", err)
+	}
+	chunks, err := SplitWAV(wavData, splitTestConfig())
+	if err != nil {
+		t.Fatalf("SplitWAV: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+	for i, c := range chunks {
+		if sr, ch, _, err := ValidateWAVHeader(c.WAV); err != nil || sr != 16000 || ch != 1 {
+			t.Errorf("chunk %d: invalid WAV header (sr=%d ch=%d err=%v)", i, sr, ch, err)
+		}
+	}
+	// Cuts at 10s, then 1s of overlap before each: 0s, 9s, 18s.
+	for i, want := range []time.Duration{0, 9 * time.Second, 18 * time.Second} {
+		if chunks[i].Offset != want {
+			t.Errorf("chunk %d: Offset = %s, want %s", i, chunks[i].Offset, want)
+		}
+	}
+}
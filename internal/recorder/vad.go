@@ -0,0 +1,131 @@
+package recorder
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoSpeech is returned by Stop when voice activity detection finds no
+// speech in the recording, so it is not worth sending to the backend.
+var ErrNoSpeech = errors.New("no speech detected")
+
+// VADConfig controls the energy/zero-crossing voice activity detector used
+// to trim leading and trailing silence from recordings.
+type VADConfig struct {
+	Enabled     bool
+	Threshold   float64 // frame RMS (0.0–1.0) at or above which a frame is speech
+	PaddingMs   int     // audio kept before the first and after the last speech frame
+	MinSpeechMs int     // total speech required for the recording to be kept
+}
+
+// TrimResult describes what TrimSilence kept.
+type TrimResult struct {
+	Original time.Duration // duration before trimming
+	Kept     time.Duration // duration after trimming
+	Speech   bool          // false if the recording contained no speech
+}
+
+const (
+	vadFrameMs = 20
+
+	// Frames next to detected speech that are quieter than Threshold but
+	// still above Threshold*unvoicedLevelRatio with a high zero-crossing
+	// rate are treated as unvoiced speech (s, f, t onsets and tails).
+	unvoicedLevelRatio = 0.25
+	unvoicedMinZCR     = 0.25
+	unvoicedMaxMs      = 200
+)
+
+// TrimSilence removes leading and trailing silence from mono samples.
+// Frames whose RMS (see computeRMS) reaches cfg.Threshold count as speech;
+// the speech region is then widened across adjacent low-energy frames with
+// a high zero-crossing rate so soft consonants are not clipped, and finally
+// padded by cfg.PaddingMs on both sides. If there is less than
+// cfg.MinSpeechMs of speech, the result reports Speech=false and the
+// returned slice is empty.
+func TrimSilence(samples []int16, sampleRate int, cfg VADConfig) ([]int16, TrimResult) {
+	result := TrimResult{Original: samplesDuration(len(samples), sampleRate)}
+	if sampleRate <= 0 || len(samples) == 0 {
+		return nil, result
+	}
+
+	frameLen := sampleRate * vadFrameMs / 1000
+	if frameLen < 1 {
+		frameLen = 1
+	}
+	numFrames := (len(samples) + frameLen - 1) / frameLen
+
+	levels := make([]float64, numFrames)
+	zcrs := make([]float64, numFrames)
+	speechFrames := 0
+	first, last := -1, -1
+	for i := 0; i < numFrames; i++ {
+		end := (i + 1) * frameLen
+		if end > len(samples) {
+			end = len(samples)
+		}
+		frame := samples[i*frameLen : end]
+		levels[i] = computeRMS(frame, 1)
+		zcrs[i] = zeroCrossingRate(frame)
+		if levels[i] >= cfg.Threshold {
+			speechFrames++
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	if first < 0 || speechFrames*vadFrameMs < cfg.MinSpeechMs {
+		return nil, result
+	}
+
+	unvoiced := func(i int) bool {
+		return levels[i] >= cfg.Threshold*unvoicedLevelRatio && zcrs[i] >= unvoicedMinZCR
+	}
+	maxExtend := unvoicedMaxMs / vadFrameMs
+	for n := 0; n < maxExtend && first > 0 && unvoiced(first-1); n++ {
+		first--
+	}
+	for n := 0; n < maxExtend && last < numFrames-1 && unvoiced(last+1); n++ {
+		last++
+	}
+
+	pad := sampleRate * cfg.PaddingMs / 1000
+	start := first*frameLen - pad
+	if start < 0 {
+		start = 0
+	}
+	end := (last+1)*frameLen + pad
+	if end > len(samples) {
+		end = len(samples)
+	}
+
+	trimmed := samples[start:end]
+	result.Kept = samplesDuration(len(trimmed), sampleRate)
+	result.Speech = true
+	return trimmed, result
+}
+
+// zeroCrossingRate returns the fraction of adjacent sample pairs that
+// change sign, in the range [0.0, 1.0].
+func zeroCrossingRate(frame []int16) float64 {
+	if len(frame) < 2 {
+		return 0
+	}
+	crossings := 0
+	for i := 1; i < len(frame); i++ {
+		if (frame[i-1] >= 0) != (frame[i] >= 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / float64(len(frame)-1)
+}
+
+// samplesDuration converts a sample count at sampleRate to a duration.
+func samplesDuration(n, sampleRate int) time.Duration {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second / time.Duration(sampleRate)
+}
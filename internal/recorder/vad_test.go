@@ -0,0 +1,114 @@
+package recorder
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var testVAD = VADConfig{Enabled: true, Threshold: 0.02, PaddingMs: 100, MinSpeechMs: 100}
+
+// tone returns n samples of a sine wave at freq Hz with the given amplitude.
+func tone(n, sampleRate int, freq, amplitude float64) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	return out
+}
+
+// noise returns n samples of uniform white noise with the given amplitude.
+func noise(n int, amplitude float64) []int16 {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // deterministic test noise
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(amplitude * (rng.Float64()*2 - 1))
+	}
+	return out
+}
+
+func concat(parts ...[]int16) []int16 {
+	var out []int16
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestTrimSilenceTrimsLeadingAndTrailing(t *testing.T) {
+	sr := 16000
+	samples := concat(
+		make([]int16, sr),         // 1s silence
+		tone(sr/2, sr, 220, 8000), // 0.5s speech-level tone
+		make([]int16, sr*3/2),     // 1.5s silence
+	)
+
+	trimmed, res := TrimSilence(samples, sr, testVAD)
+	if !res.Speech {
+		t.Fatal("expected speech to be detected")
+	}
+	if res.Original != 3*time.Second {
+		t.Errorf("expected original 3s, got %s", res.Original)
+	}
+	// 0.5s of speech plus 100ms padding on each side.
+	if res.Kept < 650*time.Millisecond || res.Kept > 750*time.Millisecond {
+		t.Errorf("expected ~700ms kept, got %s", res.Kept)
+	}
+	if len(trimmed) != int(res.Kept*time.Duration(sr)/time.Second) {
+		t.Errorf("trimmed length %d does not match kept duration %s", len(trimmed), res.Kept)
+	}
+}
+
+func TestTrimSilenceNoSpeech(t *testing.T) {
+	sr := 16000
+	tests := []struct {
+		name    string
+		samples []int16
+	}{
+		{"digital silence", make([]int16, sr)},
+		{"low noise floor", noise(sr, 200)},
+		{"short click", concat(make([]int16, sr/2), tone(sr/100, sr, 1000, 20000), make([]int16, sr/2))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, res := TrimSilence(tt.samples, sr, testVAD)
+			if res.Speech {
+				t.Errorf("expected no speech, kept %s", res.Kept)
+			}
+			if len(trimmed) != 0 {
+				t.Errorf("expected no samples, got %d", len(trimmed))
+			}
+		})
+	}
+}
+
+func TestTrimSilenceKeepsUnvoicedOnset(t *testing.T) {
+	sr := 16000
+	// A quiet, high-frequency "s"-like onset just below the threshold
+	// followed by voiced speech.
+	fricative := noise(sr/10, 800)
+	samples := concat(make([]int16, sr/2), fricative, tone(sr/2, sr, 220, 8000), make([]int16, sr/2))
+
+	noPad := testVAD
+	noPad.PaddingMs = 0
+	_, res := TrimSilence(samples, sr, noPad)
+	if !res.Speech {
+		t.Fatal("expected speech to be detected")
+	}
+	if res.Kept < 580*time.Millisecond {
+		t.Errorf("expected the 100ms fricative onset to be kept, got %s", res.Kept)
+	}
+}
+
+func TestZeroCrossingRate(t *testing.T) {
+	if zcr := zeroCrossingRate([]int16{1, -1, 1, -1, 1}); zcr != 1 {
+		t.Errorf("expected alternating signal ZCR 1, got %f", zcr)
+	}
+	if zcr := zeroCrossingRate([]int16{5, 5, 5, 5}); zcr != 0 {
+		t.Errorf("expected constant signal ZCR 0, got %f", zcr)
+	}
+	if zcr := zeroCrossingRate(nil); zcr != 0 {
+		t.Errorf("expected empty frame ZCR 0, got %f", zcr)
+	}
+}
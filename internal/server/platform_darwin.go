@@ -3,14 +3,67 @@
 package server
 
 import (
+	"cmp"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
+// The ggml models palaver setup downloads.
+const (
+	englishModel      = "ggml-base.en.bin" // English only
+	multilingualModel = "ggml-base.bin"    // any language, and auto-detection
+)
+
+// whisperModel returns the model palaver setup downloads for language: the
+// English-only base model for English, and the multilingual base model
+// for auto-detection or any other language, which the English-only one
+// cannot transcribe.
+func whisperModel(language string) string {
+	if isEnglish(language) {
+		return englishModel
+	}
+	return multilingualModel
+}
+
+func isEnglish(language string) bool {
+	return strings.EqualFold(strings.TrimSpace(language), "en")
+}
+
+// installedModel returns the model to load: the one for language if it
+// is installed, else the other one, or "" if neither is. The language can
+// change after setup, e.g. with the TUI's l key, so the server runs with
+// whichever model is there.
+func installedModel(modelsDir, language string) string {
+	preferred := whisperModel(language)
+	other := multilingualModel
+	if preferred == multilingualModel {
+		other = englishModel
+	}
+	for _, model := range []string{preferred, other} {
+		if _, err := os.Stat(filepath.Join(modelsDir, model)); err == nil {
+			return model
+		}
+	}
+	return ""
+}
+
+// modelWarning explains why the model loaded for startLanguage cannot
+// transcribe language, or returns "".
+func modelWarning(modelsDir, startLanguage, language string) string {
+	if installedModel(modelsDir, startLanguage) != englishModel || isEnglish(language) {
+		return ""
+	}
+	if lang := strings.ToLower(strings.TrimSpace(language)); lang != "" && lang != "auto" {
+		return fmt.Sprintf("the English-only whisper model cannot transcribe %q; set transcription.language and run 'palaver setup' to download the multilingual model", lang)
+	}
+	return "the English-only whisper model cannot detect the language; set transcription.language and run 'palaver setup' to download the multilingual model"
+}
+
 func libExtension() string {
 	return ".dylib"
 }
@@ -34,25 +87,32 @@ func resolveServerBinary(dataDir string) string {
 	return "whisper-server"
 }
 
-func isServerInstalled(binaryPath, modelsDir string) bool {
+// isServerInstalled reports whether whisper-server and either model are
+// installed. A model that does not suit language is reported by
+// modelWarning instead.
+func isServerInstalled(binaryPath, modelsDir, language string) bool {
 	if _, err := exec.LookPath(binaryPath); err != nil {
 		if _, err := os.Stat(binaryPath); err != nil {
 			return false
 		}
 	}
-	if _, err := os.Stat(filepath.Join(modelsDir, "ggml-base.en.bin")); err != nil {
-		return false
-	}
-	return true
+	return installedModel(modelsDir, language) != ""
 }
 
-func serverArgs(port int, modelsDir string) []string {
+// serverArgs sets whisper-server's default language, which requests
+// without a language field use, and loads the installed model, preferring
+// the one for language.
+func serverArgs(port int, modelsDir, language string) []string {
+	model := cmp.Or(installedModel(modelsDir, language), whisperModel(language))
+	if language == "" {
+		language = "auto"
+	}
 	return []string{
-		"--model", filepath.Join(modelsDir, "ggml-base.en.bin"),
+		"--model", filepath.Join(modelsDir, model),
 		"--port", fmt.Sprintf("%d", port),
 		"--host", "127.0.0.1",
 		"--inference-path", "/v1/audio/transcriptions",
-		"--language", "en",
+		"--language", language,
 		"--no-timestamps",
 	}
 }
@@ -77,7 +137,7 @@ func needsOnnxRuntime() bool {
 	return false
 }
 
-func setupServer(binaryPath, modelsDir, onnxDir string, logger *log.Logger, progress ProgressFunc) error {
+func setupServer(binaryPath, modelsDir, onnxDir, language string, logger *log.Logger, progress ProgressFunc) error {
 	// Check that whisper-server is in PATH
 	if _, err := exec.LookPath("whisper-server"); err != nil {
 		logger.Printf("whisper-server not found in PATH")
@@ -85,12 +145,13 @@ func setupServer(binaryPath, modelsDir, onnxDir string, logger *log.Logger, prog
 		return fmt.Errorf("whisper-server not found: install with 'brew install whisper-cpp'")
 	}
 
-	// Download ggml-base.en.bin model if missing
-	modelPath := filepath.Join(modelsDir, "ggml-base.en.bin")
+	// Download the model for the configured language if missing
+	model := whisperModel(language)
+	modelPath := filepath.Join(modelsDir, model)
 	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-		logger.Printf("downloading whisper model: ggml-base.en.bin")
-		url := "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-base.en.bin"
-		checksum, err := downloadFile(url, modelPath, progress, "ggml-base.en.bin")
+		logger.Printf("downloading whisper model: %s", model)
+		url := "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/" + model
+		checksum, err := downloadFile(url, modelPath, progress, model)
 		if err != nil {
 			return fmt.Errorf("download whisper model: %w", err)
 		}
@@ -0,0 +1,68 @@
+//go:build darwin
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWhisperModelFollowsLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "", want: multilingualModel},
+		{language: "auto", want: multilingualModel},
+		{language: "EN", want: englishModel},
+		{language: "de", want: multilingualModel},
+	}
+	for _, tt := range tests {
+		if got := whisperModel(tt.language); got != tt.want {
+			t.Errorf("whisperModel(%q) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestInstalledModel(t *testing.T) {
+	dir := t.TempDir()
+	if got := installedModel(dir, "de"); got != "" {
+		t.Errorf("expected no model installed, got %q", got)
+	}
+	if isServerInstalled("/bin/sh", dir, "de") {
+		t.Error("expected the server not installed without a model")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, englishModel), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// The language changed to German after setup downloaded the English model.
+	if got := installedModel(dir, "de"); got != englishModel {
+		t.Errorf("expected the installed English model, got %q", got)
+	}
+	if !isServerInstalled("/bin/sh", dir, "de") {
+		t.Error("expected the server installed with the English model")
+	}
+	if w := modelWarning(dir, "en", "de"); w == "" {
+		t.Error("expected a warning for German with the English model")
+	}
+	if w := modelWarning(dir, "en", "auto"); w == "" {
+		t.Error("expected a warning for auto-detection with the English model")
+	}
+	if w := modelWarning(dir, "en", "en"); w != "" {
+		t.Errorf("expected no warning for English, got %q", w)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, multilingualModel), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	args := serverArgs(5092, dir, "de")
+	if !slices.Contains(args, filepath.Join(dir, multilingualModel)) {
+		t.Errorf("expected the multilingual model in %v", args)
+	}
+	if w := modelWarning(dir, "de", "fr"); w != "" {
+		t.Errorf("expected no warning with the multilingual model, got %q", w)
+	}
+}
@@ -30,7 +30,9 @@ func resolveServerBinary(dataDir string) string {
 	return filepath.Join(dataDir, "parakeet")
 }
 
-func isServerInstalled(binaryPath, modelsDir string) bool {
+// isServerInstalled ignores language; the parakeet model is the same for
+// every language.
+func isServerInstalled(binaryPath, modelsDir, _ string) bool {
 	if _, err := os.Stat(binaryPath); err != nil {
 		return false
 	}
@@ -40,7 +42,14 @@ func isServerInstalled(binaryPath, modelsDir string) bool {
 	return true
 }
 
-func serverArgs(port int, modelsDir string) []string {
+// modelWarning is always empty; the parakeet model is the same for every
+// language.
+func modelWarning(_, _, _ string) string {
+	return ""
+}
+
+// serverArgs ignores language; the parakeet server has no language flag.
+func serverArgs(port int, modelsDir, _ string) []string {
 	return []string{
 		"-port", fmt.Sprintf("%d", port),
 		"-models", modelsDir,
@@ -71,7 +80,7 @@ func needsOnnxRuntime() bool {
 	return true
 }
 
-func setupServer(binaryPath, modelsDir, onnxDir string, logger *log.Logger, progress ProgressFunc) error {
+func setupServer(binaryPath, modelsDir, onnxDir, _ string, logger *log.Logger, progress ProgressFunc) error {
 	// Download binary
 	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
 		logger.Printf("downloading parakeet binary...")
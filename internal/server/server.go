@@ -21,6 +21,7 @@ type Server struct {
 	ModelsDir  string
 	OnnxDir    string // directory containing libonnxruntime (Linux only)
 	Port       int
+	Language   string // default transcription language, which picks the model if both are installed (macOS only); empty = auto-detect
 	Logger     *log.Logger
 
 	cmd *exec.Cmd
@@ -44,7 +45,7 @@ func New(cfg *config.ServerConfig, logger *log.Logger) *Server {
 
 // IsInstalled returns true if the server binary and required model files exist.
 func (s *Server) IsInstalled() bool {
-	if !isServerInstalled(s.BinaryPath, s.ModelsDir) {
+	if !isServerInstalled(s.BinaryPath, s.ModelsDir, s.Language) {
 		return false
 	}
 	if needsOnnxRuntime() && !s.onnxRuntimeAvailable() {
@@ -53,6 +54,13 @@ func (s *Server) IsInstalled() bool {
 	return true
 }
 
+// LanguageWarning explains why the server's model cannot transcribe
+// language, such as the English-only whisper model asked for German, or
+// returns "".
+func (s *Server) LanguageWarning(language string) string {
+	return modelWarning(s.ModelsDir, s.Language, language)
+}
+
 // onnxRuntimeAvailable checks if ONNX Runtime is available either system-wide
 // or in our bundled OnnxDir.
 func (s *Server) onnxRuntimeAvailable() bool {
@@ -65,9 +73,10 @@ func (s *Server) onnxRuntimeAvailable() bool {
 	return systemOnnxRuntimeAvailable()
 }
 
-// Setup downloads server dependencies if they are missing.
+// Setup downloads server dependencies if they are missing, including the
+// model for Language.
 func (s *Server) Setup(progress ProgressFunc) error {
-	return setupServer(s.BinaryPath, s.ModelsDir, s.OnnxDir, s.Logger, progress)
+	return setupServer(s.BinaryPath, s.ModelsDir, s.OnnxDir, s.Language, s.Logger, progress)
 }
 
 // Start spawns the server process and waits for it to become healthy.
@@ -81,7 +90,7 @@ func (s *Server) Start(ctx context.Context) error {
 
 	s.Logger.Printf("starting %s on port %d", serverBinaryName(), s.Port)
 
-	cmd := exec.CommandContext(ctx, s.BinaryPath, serverArgs(s.Port, s.ModelsDir)...) //nolint:gosec // binary path from config, intended behavior
+	cmd := exec.CommandContext(ctx, s.BinaryPath, serverArgs(s.Port, s.ModelsDir, s.Language)...) //nolint:gosec // binary path from config, intended behavior
 	cmd.Stdout = s.Logger.Writer()
 	cmd.Stderr = s.Logger.Writer()
 
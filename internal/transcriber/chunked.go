@@ -0,0 +1,206 @@
+package transcriber
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Chunk is one piece of a long recording, WAV-encoded. Overlap is true when
+// the chunk starts with audio repeated from the end of the previous chunk.
+type Chunk struct {
+	WAV     []byte
+	Offset  time.Duration // where the chunk starts in the recording
+	Overlap bool
+}
+
+// Splitter cuts a WAV recording into chunks in playback order. It is
+// injected so this package does not depend on how audio is decoded.
+type Splitter func(wavData []byte) ([]Chunk, error)
+
+// maxOverlapWords bounds the search for duplicated words at an overlap.
+const maxOverlapWords = 20
+
+// Chunked transcribes long recordings by splitting them, transcribing the
+// chunks concurrently with the wrapped Transcriber, and stitching the text.
+// Recordings that fit in one chunk are passed through unchanged.
+type Chunked struct {
+	inner       Transcriber
+	split       Splitter
+	parallelism int
+	logger      *log.Logger
+}
+
+// NewChunked wraps inner. At most parallelism chunks are transcribed at
+// once (minimum 1).
+func NewChunked(inner Transcriber, split Splitter, parallelism int, logger *log.Logger) *Chunked {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &Chunked{inner: inner, split: split, parallelism: parallelism, logger: logger}
+}
+
+// Transcribe splits wavData, transcribes each chunk, and joins the results,
+// dropping words repeated at overlapping boundaries. Any chunk error fails
+// the whole transcription.
+func (c *Chunked) Transcribe(ctx context.Context, wavData []byte) (string, error) {
+	res, err := c.transcribe(ctx, wavData, false)
+	return res.Text, err
+}
+
+// TranscribeDetailed is Transcribe for detailed results. Segment times are
+// shifted by each chunk's offset, and segments repeated at an overlapping
+// boundary are dropped.
+func (c *Chunked) TranscribeDetailed(ctx context.Context, wavData []byte) (Result, error) {
+	return c.transcribe(ctx, wavData, true)
+}
+
+func (c *Chunked) transcribe(ctx context.Context, wavData []byte, detailed bool) (Result, error) {
+	run := func(ctx context.Context, wav []byte) (Result, error) {
+		if detailed {
+			return TranscribeDetailed(ctx, c.inner, wav)
+		}
+		text, err := c.inner.Transcribe(ctx, wav)
+		return Result{Text: text}, err
+	}
+
+	chunks, err := c.split(wavData)
+	if err != nil {
+		return Result{}, fmt.Errorf("split audio: %w", err)
+	}
+	if len(chunks) <= 1 {
+		return run(ctx, wavData)
+	}
+	if c.logger != nil {
+		c.logger.Printf("transcribe: %d chunks, parallelism %d", len(chunks), c.parallelism)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(chunks))
+	sem := make(chan struct{}, c.parallelism)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error // the failure that cancelled the rest
+	)
+	for i, chunk := range chunks {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := run(ctx, chunk.WAV)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+					cancel()
+				}
+				errMu.Unlock()
+				return
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return Result{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	var out Result
+	lastEnd := 0.0
+	for i, res := range results {
+		out.Text = stitch(out.Text, res.Text, chunks[i].Overlap)
+		if out.Language == "" {
+			out.Language = res.Language
+		}
+		offset := chunks[i].Offset.Seconds()
+		for _, seg := range res.Segments {
+			seg.Start += offset
+			seg.End += offset
+			if chunks[i].Overlap && seg.End <= lastEnd {
+				continue // already transcribed at the end of the previous chunk
+			}
+			out.Segments = append(out.Segments, seg)
+			lastEnd = max(lastEnd, seg.End)
+		}
+	}
+	return out, nil
+}
+
+// Ping forwards to the wrapped transcriber.
+func (c *Chunked) Ping(ctx context.Context) error {
+	if hc, ok := c.inner.(HealthChecker); ok {
+		return hc.Ping(ctx)
+	}
+	return fmt.Errorf("health check not supported")
+}
+
+// ListModels forwards to the wrapped transcriber.
+func (c *Chunked) ListModels(ctx context.Context) ([]string, error) {
+	if ml, ok := c.inner.(ModelLister); ok {
+		return ml.ListModels(ctx)
+	}
+	return nil, fmt.Errorf("model listing not supported")
+}
+
+// ConfiguredModel forwards to the wrapped transcriber.
+func (c *Chunked) ConfiguredModel() string {
+	if cm, ok := c.inner.(ConfiguredModeler); ok {
+		return cm.ConfiguredModel()
+	}
+	return ""
+}
+
+// stitch appends next to prev. When overlap is set, the longest run of
+// words that ends prev and starts next (compared case- and
+// punctuation-insensitively, up to maxOverlapWords) is dropped from next.
+func stitch(prev, next string, overlap bool) string {
+	prev, next = strings.TrimSpace(prev), strings.TrimSpace(next)
+	if prev == "" || next == "" {
+		return joinTranscripts([]string{prev, next})
+	}
+	if !overlap {
+		return prev + " " + next
+	}
+
+	prevWords := strings.Fields(prev)
+	nextWords := strings.Fields(next)
+	limit := min(maxOverlapWords, len(prevWords), len(nextWords))
+	for n := limit; n > 0; n-- {
+		if wordsEqual(prevWords[len(prevWords)-n:], nextWords[:n]) {
+			nextWords = nextWords[n:]
+			break
+		}
+	}
+	return joinTranscripts([]string{prev, strings.Join(nextWords, " ")})
+}
+
+func wordsEqual(a, b []string) bool {
+	for i := range a {
+		if normalizeWord(a[i]) != normalizeWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeWord lowercases w and strips punctuation so "Hello," matches "hello".
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
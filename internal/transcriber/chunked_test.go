@@ -0,0 +1,174 @@
+package transcriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chunkEcho transcribes each chunk by returning its WAV bytes as text,
+// tracking the peak number of concurrent calls.
+type chunkEcho struct {
+	active, peak int32
+	failOn       string
+}
+
+func (e *chunkEcho) Transcribe(ctx context.Context, wavData []byte) (string, error) {
+	n := atomic.AddInt32(&e.active, 1)
+	defer atomic.AddInt32(&e.active, -1)
+	for {
+		p := atomic.LoadInt32(&e.peak)
+		if n <= p || atomic.CompareAndSwapInt32(&e.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if e.failOn != "" && string(wavData) == e.failOn {
+		return "", errors.New("backend exploded")
+	}
+	return string(wavData), ctx.Err()
+}
+
+func splitInto(chunks ...Chunk) Splitter {
+	return func([]byte) ([]Chunk, error) { return chunks, nil }
+}
+
+func TestChunkedSingleChunkPassesThrough(t *testing.T) {
+	inner := &chunkEcho{}
+	c := NewChunked(inner, splitInto(Chunk{WAV: []byte("ignored")}), 2, nil)
+	got, err := c.Transcribe(context.Background(), []byte("whole recording"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "whole recording" {
+		t.Errorf("expected original audio passed through, got %q", got)
+	}
+}
+
+func TestChunkedStitchesInOrderWithBoundedParallelism(t *testing.T) {
+	inner := &chunkEcho{}
+	c := NewChunked(inner, splitInto(
+		Chunk{WAV: []byte("one two three four")},
+		Chunk{WAV: []byte("Three, four five six"), Overlap: true},
+		Chunk{WAV: []byte("seven eight")},
+		Chunk{WAV: []byte("nine")},
+		Chunk{WAV: []byte("ten")},
+	), 2, nil)
+
+	got, err := c.Transcribe(context.Background(), []byte("long"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "one two three four five six seven eight nine ten"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if peak := atomic.LoadInt32(&inner.peak); peak > 2 {
+		t.Errorf("expected at most 2 concurrent requests, saw %d", peak)
+	}
+}
+
+func TestChunkedErrorFailsTranscription(t *testing.T) {
+	inner := &chunkEcho{failOn: "b"}
+	c := NewChunked(inner, splitInto(Chunk{WAV: []byte("a")}, Chunk{WAV: []byte("b")}, Chunk{WAV: []byte("c")}), 1, nil)
+	_, err := c.Transcribe(context.Background(), []byte("long"))
+	if err == nil || !strings.Contains(err.Error(), "chunk 2/3: backend exploded") {
+		t.Errorf("expected chunk 2 error, got %v", err)
+	}
+}
+
+// segmentEcho returns one 0–2s segment per chunk, with the chunk's bytes as
+// text, plus a 9.5–10s segment for chunks ending in "+".
+type segmentEcho struct{}
+
+func (segmentEcho) Transcribe(context.Context, []byte) (string, error) {
+	return "", errors.New("expected TranscribeDetailed")
+}
+
+func (segmentEcho) TranscribeDetailed(_ context.Context, wavData []byte) (Result, error) {
+	text := strings.TrimSuffix(string(wavData), "+")
+	res := Result{Text: text, Language: "english", Segments: []Segment{{Start: 0, End: 2, Text: text}}}
+	if strings.HasSuffix(string(wavData), "+") {
+		res.Segments = append(res.Segments, Segment{Start: 9.5, End: 10, Text: "tail"})
+	}
+	return res, nil
+}
+
+func TestChunkedDetailedShiftsSegments(t *testing.T) {
+	c := NewChunked(segmentEcho{}, splitInto(
+		Chunk{WAV: []byte("one")},
+		Chunk{WAV: []byte("two+"), Offset: 10 * time.Second},
+		Chunk{WAV: []byte("three"), Offset: 19 * time.Second, Overlap: true},
+	), 2, nil)
+	res, err := c.TranscribeDetailed(context.Background(), []byte("long"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Text != "one two three" || res.Language != "english" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	var got []string
+	for _, s := range res.Segments {
+		got = append(got, fmt.Sprintf("%s@%g-%g", s.Text, s.Start, s.End))
+	}
+	// three's 19–21s segment ends after two's tail (19.5–20s), so it is kept.
+	if want := "one@0-2,two@10-12,tail@19.5-20,three@19-21"; strings.Join(got, ",") != want {
+		t.Errorf("segments = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestChunkedDetailedDropsRepeatedSegments(t *testing.T) {
+	c := NewChunked(segmentEcho{}, splitInto(
+		Chunk{WAV: []byte("one+")},
+		Chunk{WAV: []byte("two"), Offset: 8 * time.Second, Overlap: true},
+	), 1, nil)
+	res, err := c.TranscribeDetailed(context.Background(), []byte("long"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// two's segment (8–10s) lies within audio the first chunk already
+	// covered up to its tail at 10s.
+	if len(res.Segments) != 2 || res.Segments[1].Text != "tail" {
+		t.Errorf("expected the repeated segment dropped, got %+v", res.Segments)
+	}
+}
+
+func TestTranscribeDetailedPlainTranscriber(t *testing.T) {
+	res, err := TranscribeDetailed(context.Background(), &chunkEcho{}, []byte("plain"))
+	if err != nil || res.Text != "plain" || res.Segments != nil {
+		t.Errorf("expected a text-only result, got %+v, %v", res, err)
+	}
+}
+
+func TestChunkedForwardsOptionalInterfaces(t *testing.T) {
+	c := NewChunked(NewOpenAI("http://127.0.0.1:1", "whisper-1", 1, false, nil), nil, 1, nil)
+	if got := c.ConfiguredModel(); got != "whisper-1" {
+		t.Errorf("expected configured model forwarded, got %q", got)
+	}
+	if err := NewChunked(&chunkEcho{}, nil, 1, nil).Ping(context.Background()); err == nil {
+		t.Error("expected Ping error when the wrapped transcriber cannot health check")
+	}
+}
+
+func TestStitch(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		overlap    bool
+		want       string
+	}{
+		{"", "hello", false, "hello"},
+		{"hello", "", true, "hello"},
+		{"hello world", "world again", false, "hello world world again"},
+		{"hello world", "World. Again", true, "hello world Again"},
+		{"we ship on friday", "on friday we ship", true, "we ship on friday we ship"},
+		{"no shared words", "at all here", true, "no shared words at all here"},
+	}
+	for _, tt := range tests {
+		if got := stitch(tt.prev, tt.next, tt.overlap); got != tt.want {
+			t.Errorf("stitch(%q, %q, %v) = %q, want %q", tt.prev, tt.next, tt.overlap, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package transcriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend is one transcriber in a Fallback chain.
+type Backend struct {
+	Name        string
+	Transcriber Transcriber
+	Timeout     time.Duration // bounds the health check and transcription; 0 = no limit
+}
+
+// Fallback tries its backends in priority order. Backends that implement
+// HealthChecker are pinged first and skipped if unreachable; a backend
+// that fails or times out hands the request to the next one.
+type Fallback struct {
+	backends []Backend
+	logger   *log.Logger
+}
+
+// NewFallback creates a chain from backends, highest priority first.
+func NewFallback(backends []Backend, logger *log.Logger) *Fallback {
+	return &Fallback{backends: backends, logger: logger}
+}
+
+// Transcribe returns the first successful transcript. The serving backend
+// is reported to the context (see WithServedBy). If every backend fails,
+// the error lists each failure.
+func (f *Fallback) Transcribe(ctx context.Context, wavData []byte) (string, error) {
+	res, err := f.transcribe(ctx, wavData, false)
+	return res.Text, err
+}
+
+// TranscribeDetailed is Transcribe for detailed results. Backends without
+// detailed results return only the text.
+func (f *Fallback) TranscribeDetailed(ctx context.Context, wavData []byte) (Result, error) {
+	return f.transcribe(ctx, wavData, true)
+}
+
+func (f *Fallback) transcribe(ctx context.Context, wavData []byte, detailed bool) (Result, error) {
+	var errs []error
+	for _, b := range f.backends {
+		res, err := f.try(ctx, b, wavData, detailed)
+		if err == nil {
+			reportServed(ctx, b.Name)
+			if f.logger != nil {
+				f.logger.Printf("transcribe: served by %s", b.Name)
+			}
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		if f.logger != nil {
+			f.logger.Printf("transcribe: backend %s failed, trying next: %v", b.Name, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+	}
+	return Result{}, fmt.Errorf("all transcription backends failed: %w", errors.Join(errs...))
+}
+
+func (f *Fallback) try(ctx context.Context, b Backend, wavData []byte, detailed bool) (Result, error) {
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+	if hc, ok := b.Transcriber.(HealthChecker); ok {
+		if err := hc.Ping(ctx); err != nil {
+			return Result{}, fmt.Errorf("unreachable: %w", err)
+		}
+	}
+	if detailed {
+		return TranscribeDetailed(ctx, b.Transcriber, wavData)
+	}
+	text, err := b.Transcriber.Transcribe(ctx, wavData)
+	return Result{Text: text}, err
+}
+
+// Ping succeeds if any backend is usable: it answers its health check or
+// has none (e.g. the command provider).
+func (f *Fallback) Ping(ctx context.Context) error {
+	var errs []error
+	for _, b := range f.backends {
+		hc, ok := b.Transcriber.(HealthChecker)
+		if !ok {
+			return nil
+		}
+		err := hc.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+	}
+	return errors.Join(errs...)
+}
+
+// ListModels lists the models of the first backend that can list them.
+func (f *Fallback) ListModels(ctx context.Context) ([]string, error) {
+	for _, b := range f.backends {
+		if ml, ok := b.Transcriber.(ModelLister); ok {
+			if models, err := ml.ListModels(ctx); err == nil {
+				return models, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("model listing not supported")
+}
+
+// ConfiguredModel returns the configured model of the primary backend.
+func (f *Fallback) ConfiguredModel() string {
+	if len(f.backends) == 0 {
+		return ""
+	}
+	if cm, ok := f.backends[0].Transcriber.(ConfiguredModeler); ok {
+		return cm.ConfiguredModel()
+	}
+	return ""
+}
+
+type servedKey struct{}
+
+// served collects backend names reported during one request.
+type served struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// WithServedBy returns a context that records which backends serve
+// transcriptions made with it, and a function that returns their names,
+// comma-separated in order of first use. Chunked recordings may be served
+// by more than one backend. The names are empty for transcribers other
+// than Fallback.
+func WithServedBy(ctx context.Context) (context.Context, func() string) {
+	s := &served{}
+	return context.WithValue(ctx, servedKey{}, s), func() string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return strings.Join(s.names, ",")
+	}
+}
+
+func reportServed(ctx context.Context, name string) {
+	s, ok := ctx.Value(servedKey{}).(*served)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range s.names {
+		if n == name {
+			return
+		}
+	}
+	s.names = append(s.names, name)
+}
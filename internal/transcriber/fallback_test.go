@@ -0,0 +1,203 @@
+package transcriber
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// fakeBackend returns text or err, optionally after a delay, and fails its
+// health check when down is set.
+type fakeBackend struct {
+	text  string
+	err   error
+	delay time.Duration
+	down  bool
+	calls atomic.Int32
+}
+
+func (b *fakeBackend) Transcribe(ctx context.Context, _ []byte) (string, error) {
+	b.calls.Add(1)
+	select {
+	case <-time.After(b.delay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return b.text, b.err
+}
+
+func (b *fakeBackend) Ping(context.Context) error {
+	if b.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// noPingBackend has no health check, like the command provider.
+type noPingBackend struct{ text string }
+
+func (b noPingBackend) Transcribe(context.Context, []byte) (string, error) { return b.text, nil }
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends []Backend
+		want     string
+		served   string
+		wantErr  string
+	}{
+		{
+			name: "primary serves",
+			backends: []Backend{
+				{Name: "parakeet", Transcriber: &fakeBackend{text: "primary"}},
+				{Name: "remote", Transcriber: &fakeBackend{text: "secondary"}},
+			},
+			want:   "primary",
+			served: "parakeet",
+		},
+		{
+			name: "unreachable primary is skipped",
+			backends: []Backend{
+				{Name: "parakeet", Transcriber: &fakeBackend{text: "primary", down: true}},
+				{Name: "remote", Transcriber: &fakeBackend{text: "secondary"}},
+			},
+			want:   "secondary",
+			served: "remote",
+		},
+		{
+			name: "failed request falls through to command",
+			backends: []Backend{
+				{Name: "parakeet", Transcriber: &fakeBackend{err: errors.New("status 503")}},
+				{Name: "command", Transcriber: noPingBackend{text: "from command"}},
+			},
+			want:   "from command",
+			served: "command",
+		},
+		{
+			name: "timed out backend falls through",
+			backends: []Backend{
+				{Name: "slow", Transcriber: &fakeBackend{text: "late", delay: time.Second}, Timeout: 20 * time.Millisecond},
+				{Name: "fast", Transcriber: &fakeBackend{text: "on time"}},
+			},
+			want:   "on time",
+			served: "fast",
+		},
+		{
+			name: "all fail",
+			backends: []Backend{
+				{Name: "parakeet", Transcriber: &fakeBackend{down: true}},
+				{Name: "remote", Transcriber: &fakeBackend{err: errors.New("status 500")}},
+			},
+			wantErr: "parakeet: unreachable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, servedBy := WithServedBy(context.Background())
+			got, err := NewFallback(tt.backends, nil).Transcribe(ctx, []byte("wav"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "remote: status 500") {
+					t.Fatalf("expected error listing every backend, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if servedBy() != tt.served {
+				t.Errorf("expected served by %q, got %q", tt.served, servedBy())
+			}
+		})
+	}
+}
+
+func TestFallbackStopsWhenCancelled(t *testing.T) {
+	second := &fakeBackend{text: "secondary"}
+	f := NewFallback([]Backend{
+		{Name: "slow", Transcriber: &fakeBackend{delay: time.Second}},
+		{Name: "remote", Transcriber: second},
+	}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := f.Transcribe(ctx, []byte("wav")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline error, got %v", err)
+	}
+	if second.calls.Load() != 0 {
+		t.Error("expected no fallback after the caller gave up")
+	}
+}
+
+func TestFallbackPing(t *testing.T) {
+	up := NewFallback([]Backend{
+		{Name: "parakeet", Transcriber: &fakeBackend{down: true}},
+		{Name: "remote", Transcriber: &fakeBackend{}},
+	}, nil)
+	if err := up.Ping(context.Background()); err != nil {
+		t.Errorf("expected ping to succeed when a fallback is reachable, got %v", err)
+	}
+	down := NewFallback([]Backend{
+		{Name: "parakeet", Transcriber: &fakeBackend{down: true}},
+	}, nil)
+	if err := down.Ping(context.Background()); err == nil {
+		t.Error("expected ping to fail when no backend is reachable")
+	}
+}
+
+func TestServedByThroughChunked(t *testing.T) {
+	f := NewFallback([]Backend{{Name: "parakeet", Transcriber: &fakeBackend{text: "word"}}}, nil)
+	c := NewChunked(f, splitInto(Chunk{WAV: []byte("a")}, Chunk{WAV: []byte("b")}), 2, nil)
+	ctx, servedBy := WithServedBy(context.Background())
+	if _, err := c.Transcribe(ctx, []byte("wav")); err != nil {
+		t.Fatal(err)
+	}
+	if servedBy() != "parakeet" {
+		t.Errorf("expected each backend listed once, got %q", servedBy())
+	}
+}
+
+func TestNewWithBackends(t *testing.T) {
+	cfg := &config.TranscriptionConfig{
+		Provider:   "openai",
+		Model:      "whisper-1",
+		TimeoutSec: 30,
+		Backends: []config.TranscriptionBackend{
+			{Name: "parakeet", BaseURL: "http://localhost:5092", TimeoutSec: 5},
+			{BaseURL: "https://whisper.example.com"},
+			{Provider: "command", Command: "whisper-cpp -f {input}"},
+		},
+	}
+	trans, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, ok := trans.(*Fallback)
+	if !ok {
+		t.Fatalf("expected *Fallback, got %T", trans)
+	}
+	var names []string
+	for _, b := range f.backends {
+		names = append(names, b.Name)
+	}
+	if got := strings.Join(names, ","); got != "parakeet,https://whisper.example.com,command" {
+		t.Errorf("unexpected backend names: %s", got)
+	}
+	if f.backends[0].Timeout != 5*time.Second || f.backends[1].Timeout != 30*time.Second {
+		t.Errorf("expected per-backend timeouts with inheritance, got %v and %v", f.backends[0].Timeout, f.backends[1].Timeout)
+	}
+	if f.ConfiguredModel() != "whisper-1" {
+		t.Errorf("expected inherited model, got %q", f.ConfiguredModel())
+	}
+
+	cfg.Backends = []config.TranscriptionBackend{{Name: "broken"}}
+	if _, err := New(cfg, nil); err == nil {
+		t.Error("expected an error for an openai backend without base_url")
+	}
+}
@@ -1,7 +1,9 @@
 package transcriber
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -10,6 +12,7 @@ import (
 	"log"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,11 +23,13 @@ type OpenAI struct {
 	baseURL    string
 	model      string
 	timeoutSec int
+	opts       Options
 	client     *http.Client
 	logger     *log.Logger
 }
 
-// NewOpenAI creates an OpenAI-compatible transcriber.
+// NewOpenAI creates an OpenAI-compatible transcriber. Use SetOptions to
+// send a language, prompt, or temperature.
 func NewOpenAI(baseURL, model string, timeoutSec int, tlsSkipVerify bool, logger *log.Logger) *OpenAI {
 	client := &http.Client{}
 	if tlsSkipVerify {
@@ -41,6 +46,12 @@ func NewOpenAI(baseURL, model string, timeoutSec int, tlsSkipVerify bool, logger
 	}
 }
 
+// SetOptions sets the optional fields sent with each request. It must be
+// called before use.
+func (o *OpenAI) SetOptions(opts Options) {
+	o.opts = opts
+}
+
 // ConfiguredModel returns the model name from config.
 func (o *OpenAI) ConfiguredModel() string {
 	return o.model
@@ -102,41 +113,131 @@ func (o *OpenAI) ListModels(ctx context.Context) ([]string, error) {
 
 // Transcribe sends WAV data to the OpenAI-compatible endpoint and returns the text.
 func (o *OpenAI) Transcribe(ctx context.Context, wavData []byte) (string, error) {
+	respBody, err := o.post(ctx, wavData, "text")
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(respBody))
+	if o.logger != nil {
+		o.logger.Printf("transcribe result: %q", text)
+	}
+	return text, nil
+}
+
+// verboseResponse is the verbose_json transcription response.
+type verboseResponse struct {
+	Text     string    `json:"text"`
+	Language string    `json:"language"`
+	Segments []Segment `json:"segments"`
+}
+
+// TranscribeDetailed requests response_format=verbose_json and returns the
+// text with its segments and detected language. Servers that ignore the
+// format and answer with plain text yield a Result with only Text set.
+func (o *OpenAI) TranscribeDetailed(ctx context.Context, wavData []byte) (Result, error) {
+	respBody, err := o.post(ctx, wavData, "verbose_json")
+	if err != nil {
+		return Result{}, err
+	}
+	var v verboseResponse
+	if err := json.Unmarshal(respBody, &v); err != nil {
+		if o.logger != nil {
+			o.logger.Printf("transcribe: response is not verbose_json, using it as text")
+		}
+		return Result{Text: strings.TrimSpace(string(respBody))}, nil
+	}
+	for i := range v.Segments {
+		v.Segments[i].Text = strings.TrimSpace(v.Segments[i].Text)
+	}
+	res := Result{Text: strings.TrimSpace(v.Text), Language: v.Language, Segments: v.Segments}
+	if o.logger != nil {
+		o.logger.Printf("transcribe result: %q language=%s segments=%d", res.Text, res.Language, len(res.Segments))
+	}
+	return res, nil
+}
+
+// post sends a non-streaming transcription request in the given
+// response_format and returns the response body.
+func (o *OpenAI) post(ctx context.Context, wavData []byte, format string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(o.timeoutSec)*time.Second)
 	defer cancel()
 
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
+	req, err := o.newRequest(ctx, wavData, format, false)
+	if err != nil {
+		return nil, err
+	}
 
-	part, err := writer.CreateFormFile("file", "audio.wav")
+	start := time.Now()
+	resp, err := o.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("create form file: %w", err)
+		return nil, fmt.Errorf("send request: %w", err)
 	}
-	if _, err := part.Write(wavData); err != nil {
-		return "", fmt.Errorf("write wav data: %w", err)
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1 MB cap
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
 	}
+	latency := time.Since(start)
 
-	if err := writer.WriteField("model", o.model); err != nil {
-		return "", fmt.Errorf("write model field: %w", err)
+	if o.logger != nil {
+		o.logger.Printf("transcribe response: status=%d body_size=%d latency=%s", resp.StatusCode, len(respBody), latency.Round(time.Millisecond))
 	}
-	if err := writer.WriteField("response_format", "text"); err != nil {
-		return "", fmt.Errorf("write response_format field: %w", err)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transcription failed (status %d): %s", resp.StatusCode, string(respBody))
 	}
+	return respBody, nil
+}
 
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("close multipart writer: %w", err)
+// TranscribeStream transcribes each WAV chunk as it arrives, asking the
+// server to stream transcript events (stream=true). Servers that ignore the
+// stream flag and answer with plain text are handled as well; their text
+// simply arrives once per chunk instead of word by word.
+func (o *OpenAI) TranscribeStream(ctx context.Context, chunks <-chan []byte, onPartial func(string)) (string, error) {
+	// Drain remaining chunks on early return so the recorder never blocks.
+	defer func() {
+		for range chunks {
+		}
+	}()
+
+	var done []string
+	emit := func(pending string) {
+		if onPartial == nil {
+			return
+		}
+		parts := append(done[:len(done):len(done)], pending)
+		onPartial(joinTranscripts(parts))
 	}
 
-	url := o.baseURL + "/v1/audio/transcriptions"
+	for wavData := range chunks {
+		text, err := o.transcribeChunk(ctx, wavData, emit)
+		if err != nil {
+			return "", err
+		}
+		if text != "" {
+			done = append(done, text)
+			emit("")
+		}
+	}
+
+	text := joinTranscripts(done)
 	if o.logger != nil {
-		o.logger.Printf("transcribe request: POST %s wav_size=%d", url, len(wavData))
+		o.logger.Printf("transcribe stream result: %q chunks=%d", text, len(done))
 	}
+	return text, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+// transcribeChunk sends one chunk with stream=true and returns its final
+// text, reporting in-progress text for the chunk through onDelta.
+func (o *OpenAI) transcribeChunk(ctx context.Context, wavData []byte, onDelta func(string)) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(o.timeoutSec)*time.Second)
+	defer cancel()
+
+	req, err := o.newRequest(ctx, wavData, "text", true)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	start := time.Now()
 	resp, err := o.client.Do(req)
@@ -145,23 +246,142 @@ func (o *OpenAI) Transcribe(ctx context.Context, wavData []byte) (string, error)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1 MB cap
+	body := io.LimitReader(resp.Body, 1<<20) // 1 MB cap
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(body)
+		return "", fmt.Errorf("transcription failed (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var text string
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		text, err = readTranscriptEvents(body, onDelta)
+	} else {
+		var respBody []byte
+		respBody, err = io.ReadAll(body)
+		text = strings.TrimSpace(string(respBody))
+	}
 	if err != nil {
 		return "", fmt.Errorf("read response: %w", err)
 	}
-	latency := time.Since(start)
 
 	if o.logger != nil {
-		o.logger.Printf("transcribe response: status=%d body_size=%d latency=%s", resp.StatusCode, len(respBody), latency.Round(time.Millisecond))
+		o.logger.Printf("transcribe chunk: wav_size=%d latency=%s text=%q", len(wavData), time.Since(start).Round(time.Millisecond), text)
 	}
+	return text, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("transcription failed (status %d): %s", resp.StatusCode, string(respBody))
+// transcriptEvent is a server-sent event from a streaming transcription.
+type transcriptEvent struct {
+	Type  string `json:"type"`
+	Delta string `json:"delta"`
+	Text  string `json:"text"`
+}
+
+// readTranscriptEvents parses "transcript.text.delta" and
+// "transcript.text.done" server-sent events and returns the final text.
+func readTranscriptEvents(r io.Reader, onDelta func(string)) (string, error) {
+	var pending strings.Builder
+	final := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+		var ev transcriptEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return "", fmt.Errorf("decode event: %w", err)
+		}
+		switch ev.Type {
+		case "transcript.text.delta":
+			pending.WriteString(ev.Delta)
+			if onDelta != nil {
+				onDelta(strings.TrimSpace(pending.String()))
+			}
+		case "transcript.text.done":
+			final = ev.Text
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if final == "" {
+		final = pending.String()
 	}
+	return strings.TrimSpace(final), nil
+}
 
-	text := strings.TrimSpace(string(respBody))
+// joinTranscripts joins non-empty transcript pieces with single spaces.
+func joinTranscripts(parts []string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// newRequest builds the multipart transcription request for wavData,
+// asking for the given response_format. When stream is true the server is
+// asked to stream transcript events.
+func (o *OpenAI) newRequest(ctx context.Context, wavData []byte, format string, stream bool) (*http.Request, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return nil, fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := part.Write(wavData); err != nil {
+		return nil, fmt.Errorf("write wav data: %w", err)
+	}
+
+	if err := writer.WriteField("model", o.model); err != nil {
+		return nil, fmt.Errorf("write model field: %w", err)
+	}
+	if err := writer.WriteField("response_format", format); err != nil {
+		return nil, fmt.Errorf("write response_format field: %w", err)
+	}
+	lang := requestLanguage(ctx, o.opts.Language)
+	if lang != "" {
+		if err := writer.WriteField("language", lang); err != nil {
+			return nil, fmt.Errorf("write language field: %w", err)
+		}
+	}
+	if o.opts.Prompt != "" {
+		if err := writer.WriteField("prompt", o.opts.Prompt); err != nil {
+			return nil, fmt.Errorf("write prompt field: %w", err)
+		}
+	}
+	if o.opts.Temperature != nil {
+		if err := writer.WriteField("temperature", strconv.FormatFloat(*o.opts.Temperature, 'f', -1, 64)); err != nil {
+			return nil, fmt.Errorf("write temperature field: %w", err)
+		}
+	}
+	if stream {
+		if err := writer.WriteField("stream", "true"); err != nil {
+			return nil, fmt.Errorf("write stream field: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	url := o.baseURL + "/v1/audio/transcriptions"
 	if o.logger != nil {
-		o.logger.Printf("transcribe result: %q", text)
+		o.logger.Printf("transcribe request: POST %s wav_size=%d stream=%v language=%s", url, len(wavData), stream, cmp.Or(lang, "auto"))
 	}
-	return text, nil
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req, nil
 }
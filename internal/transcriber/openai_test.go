@@ -3,6 +3,7 @@ package transcriber
 import (
 	"context"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -82,3 +83,215 @@ func TestOpenAITranscribeError(t *testing.T) {
 		t.Error("expected error for 404 response")
 	}
 }
+
+func TestOpenAITranscribeDetailed(t *testing.T) {
+	var receivedFormat string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedFormat = r.FormValue("response_format") //nolint:gosec // test code
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"task":"transcribe","language":"english","duration":3.2,"text":" Hello there. Mumble. ","segments":[` +
+			`{"id":0,"start":0.0,"end":1.4,"text":" Hello there.","avg_logprob":-0.21,"no_speech_prob":0.01},` +
+			`{"id":1,"start":1.4,"end":3.2,"text":" Mumble.","avg_logprob":-1.7,"no_speech_prob":0.3}]}`))
+	}))
+	defer server.Close()
+
+	res, err := NewOpenAI(server.URL, "whisper-1", 30, false, nil).TranscribeDetailed(context.Background(), []byte("wav"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedFormat != "verbose_json" {
+		t.Errorf("expected response_format verbose_json, got %q", receivedFormat)
+	}
+	if res.Text != "Hello there. Mumble." || res.Language != "english" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if len(res.Segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(res.Segments))
+	}
+	if s := res.Segments[1]; s.Text != "Mumble." || s.Start != 1.4 || s.End != 3.2 || !s.LowConfidence(-1) {
+		t.Errorf("unexpected second segment: %+v", s)
+	}
+	if res.Segments[0].LowConfidence(-1) {
+		t.Error("expected the first segment to be confident")
+	}
+}
+
+func TestOpenAITranscribeDetailedPlainTextFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("  just text  "))
+	}))
+	defer server.Close()
+
+	res, err := NewOpenAI(server.URL, "parakeet", 30, false, nil).TranscribeDetailed(context.Background(), []byte("wav"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Text != "just text" || len(res.Segments) != 0 {
+		t.Errorf("expected a text-only result, got %+v", res)
+	}
+}
+
+func TestOpenAITranscribeStream(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil { //nolint:gosec // test code with bounded input
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if r.FormValue("stream") != "true" { //nolint:gosec // test code
+			t.Errorf("expected stream=true, got %q", r.FormValue("stream")) //nolint:gosec // test code
+		}
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+		if requests == 1 {
+			_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.delta\",\"delta\":\"Hello\"}\n\n")
+			_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.delta\",\"delta\":\" there\"}\n\n")
+			_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.done\",\"text\":\"Hello there\"}\n\n")
+			return
+		}
+		_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.delta\",\"delta\":\"world\"}\n\n")
+		_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.done\",\"text\":\"world\"}\n\n")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	chunks := make(chan []byte, 2)
+	chunks <- []byte("chunk-1")
+	chunks <- []byte("chunk-2")
+	close(chunks)
+
+	var partials []string
+	transcriber := NewOpenAI(server.URL, "test-model", 30, false, nil)
+	result, err := transcriber.TranscribeStream(context.Background(), chunks, func(text string) {
+		partials = append(partials, text)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "Hello there world" {
+		t.Errorf("expected 'Hello there world', got %q", result)
+	}
+
+	want := []string{"Hello", "Hello there", "Hello there", "Hello there world", "Hello there world"}
+	if len(partials) != len(want) {
+		t.Fatalf("expected partials %q, got %q", want, partials)
+	}
+	for i := range want {
+		if partials[i] != want[i] {
+			t.Errorf("partial %d: expected %q, got %q", i, want[i], partials[i])
+		}
+	}
+}
+
+func TestOpenAITranscribeStreamPlainTextFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, " segment \n")
+	}))
+	defer server.Close()
+
+	chunks := make(chan []byte, 2)
+	chunks <- []byte("a")
+	chunks <- []byte("b")
+	close(chunks)
+
+	transcriber := NewOpenAI(server.URL, "test-model", 30, false, nil)
+	result, err := transcriber.TranscribeStream(context.Background(), chunks, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "segment segment" {
+		t.Errorf("expected 'segment segment', got %q", result)
+	}
+}
+
+func TestOpenAITranscribeStreamErrorDrainsChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	chunks := make(chan []byte)
+	go func() {
+		for i := 0; i < 3; i++ {
+			chunks <- []byte("chunk")
+		}
+		close(chunks)
+	}()
+
+	transcriber := NewOpenAI(server.URL, "test-model", 30, false, nil)
+	if _, err := transcriber.TranscribeStream(context.Background(), chunks, nil); err == nil {
+		t.Error("expected error for 500 response")
+	}
+}
+
+func TestOpenAIRequestOptions(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil { //nolint:gosec // test code with bounded input
+			t.Errorf("parse multipart: %v", err)
+		}
+		got = map[string]string{}
+		for _, field := range []string{"language", "prompt", "temperature"} {
+			if v, ok := r.MultipartForm.Value[field]; ok {
+				got[field] = v[0]
+			}
+		}
+		_, _ = w.Write([]byte("Guten Tag"))
+	}))
+	defer server.Close()
+
+	temperature := 0.2
+	o := NewOpenAI(server.URL, "whisper-1", 30, false, nil)
+	o.SetOptions(Options{Language: "de", Prompt: "Palaver, Grafana", Temperature: &temperature})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]string
+	}{
+		{
+			name: "configured options",
+			ctx:  context.Background(),
+			want: map[string]string{"language": "de", "prompt": "Palaver, Grafana", "temperature": "0.2"},
+		},
+		{
+			name: "language overridden",
+			ctx:  WithLanguage(context.Background(), "en"),
+			want: map[string]string{"language": "en", "prompt": "Palaver, Grafana", "temperature": "0.2"},
+		},
+		{
+			name: "auto omits language",
+			ctx:  WithLanguage(context.Background(), "auto"),
+			want: map[string]string{"prompt": "Palaver, Grafana", "temperature": "0.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := o.Transcribe(tt.ctx, []byte("wav")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("expected fields %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Unset options are not sent.
+	if _, err := NewOpenAI(server.URL, "whisper-1", 30, false, nil).Transcribe(context.Background(), []byte("wav")); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected no optional fields by default, got %v", got)
+	}
+
+	// A temperature of 0 is sent, not mistaken for unset.
+	zero := 0.0
+	o.SetOptions(Options{Temperature: &zero})
+	if _, err := o.Transcribe(context.Background(), []byte("wav")); err != nil {
+		t.Fatal(err)
+	}
+	if got["temperature"] != "0" {
+		t.Errorf("expected temperature 0 to be sent, got %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package transcriber
+
+import (
+	"context"
+	"strings"
+)
+
+// Options are the optional fields sent with each request to an
+// OpenAI-compatible backend.
+type Options struct {
+	Language    string   // ISO-639-1 code, e.g. "de"; empty or "auto" lets the server detect it
+	Prompt      string   // text that guides spelling and style, e.g. names and jargon
+	Temperature *float64 // sampling temperature; nil leaves the server's default
+}
+
+type languageKey struct{}
+
+// WithLanguage returns a context whose transcriptions request lang instead
+// of the configured language. An empty lang or "auto" asks the server to
+// detect the language.
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// requestLanguage returns the language to request: the one set with
+// WithLanguage if any, else configured. It is empty for auto-detection.
+func requestLanguage(ctx context.Context, configured string) string {
+	lang := configured
+	if l, ok := ctx.Value(languageKey{}).(string); ok {
+		lang = l
+	}
+	if strings.EqualFold(lang, "auto") {
+		return ""
+	}
+	return lang
+}
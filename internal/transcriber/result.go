@@ -0,0 +1,37 @@
+package transcriber
+
+import "context"
+
+// Result is a transcript with the details a verbose_json response carries.
+// Only Text is set for backends that return plain text.
+type Result struct {
+	Text     string    `json:"text"`
+	Language string    `json:"language,omitempty"` // detected language, e.g. "english"
+	Segments []Segment `json:"segments,omitempty"`
+}
+
+// Segment is a timed stretch of the transcript. Start and End are seconds
+// from the beginning of the recording.
+type Segment struct {
+	Start        float64 `json:"start"`
+	End          float64 `json:"end"`
+	Text         string  `json:"text"`
+	AvgLogprob   float64 `json:"avg_logprob"`    // mean token log-probability; closer to 0 is more confident
+	NoSpeechProb float64 `json:"no_speech_prob"` // probability the segment is not speech
+}
+
+// LowConfidence reports whether the segment's average log-probability is
+// below threshold.
+func (s Segment) LowConfidence(threshold float64) bool {
+	return s.AvgLogprob < threshold
+}
+
+// TranscribeDetailed transcribes with t's TranscribeDetailed method if it
+// has one, and otherwise returns a Result holding only the plain text.
+func TranscribeDetailed(ctx context.Context, t Transcriber, wavData []byte) (Result, error) {
+	if dt, ok := t.(DetailedTranscriber); ok {
+		return dt.TranscribeDetailed(ctx, wavData)
+	}
+	text, err := t.Transcribe(ctx, wavData)
+	return Result{Text: text}, err
+}
@@ -1,9 +1,11 @@
 package transcriber
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/Danondso/palaver/internal/config"
 )
@@ -13,6 +15,25 @@ type Transcriber interface {
 	Transcribe(ctx context.Context, wavData []byte) (string, error)
 }
 
+// StreamTranscriber is optionally implemented by transcribers that can
+// transcribe audio incrementally while it is still being recorded.
+type StreamTranscriber interface {
+	// TranscribeStream transcribes WAV chunks as they arrive on chunks.
+	// onPartial is called with the accumulated text whenever new text is
+	// available. The final transcript is returned once chunks is closed and
+	// every chunk has been transcribed. chunks is always drained, even on
+	// error, so the producer never blocks.
+	TranscribeStream(ctx context.Context, chunks <-chan []byte, onPartial func(string)) (string, error)
+}
+
+// DetailedTranscriber is optionally implemented by transcribers that can
+// return segment timings, the detected language, and confidence along with
+// the text. Use TranscribeDetailed to call it with a fallback for plain
+// transcribers.
+type DetailedTranscriber interface {
+	TranscribeDetailed(ctx context.Context, wavData []byte) (Result, error)
+}
+
 // HealthChecker is optionally implemented by transcribers that can report
 // backend availability.
 type HealthChecker interface {
@@ -31,11 +52,63 @@ type ConfiguredModeler interface {
 	ConfiguredModel() string
 }
 
-// New creates a Transcriber based on the provider config.
+// New creates a Transcriber based on the provider config. When
+// [[transcription.backends]] are configured, it returns a Fallback that
+// tries them in order.
 func New(cfg *config.TranscriptionConfig, logger *log.Logger) (Transcriber, error) {
+	if len(cfg.Backends) == 0 {
+		return newProvider(cfg, logger)
+	}
+	backends := make([]Backend, 0, len(cfg.Backends))
+	for i, b := range cfg.Backends {
+		bc := backendConfig(cfg, b)
+		if bc.Provider == "openai" && bc.BaseURL == "" {
+			return nil, fmt.Errorf("transcription backend %d: base_url is required", i+1)
+		}
+		t, err := newProvider(&bc, logger)
+		if err != nil {
+			return nil, fmt.Errorf("transcription backend %d: %w", i+1, err)
+		}
+		name := b.Name
+		if name == "" {
+			name = bc.Provider
+			if bc.Provider == "openai" {
+				name = bc.BaseURL
+			}
+		}
+		backends = append(backends, Backend{
+			Name:        name,
+			Transcriber: t,
+			Timeout:     time.Duration(bc.TimeoutSec) * time.Second,
+		})
+	}
+	return NewFallback(backends, logger), nil
+}
+
+// backendConfig fills in a backend's provider, model, and timeout from the
+// [transcription] section when they are not set. Language, prompt, and
+// temperature always come from [transcription].
+func backendConfig(cfg *config.TranscriptionConfig, b config.TranscriptionBackend) config.TranscriptionConfig {
+	return config.TranscriptionConfig{
+		Provider:      cmp.Or(b.Provider, cfg.Provider),
+		BaseURL:       b.BaseURL,
+		Model:         cmp.Or(b.Model, cfg.Model),
+		TimeoutSec:    cmp.Or(b.TimeoutSec, cfg.TimeoutSec),
+		Command:       b.Command,
+		TLSSkipVerify: b.TLSSkipVerify,
+		Language:      cfg.Language,
+		Prompt:        cfg.Prompt,
+		Temperature:   cfg.Temperature,
+	}
+}
+
+// newProvider creates the single provider named by cfg.Provider.
+func newProvider(cfg *config.TranscriptionConfig, logger *log.Logger) (Transcriber, error) {
 	switch cfg.Provider {
 	case "openai":
-		return NewOpenAI(cfg.BaseURL, cfg.Model, cfg.TimeoutSec, cfg.TLSSkipVerify, logger), nil
+		o := NewOpenAI(cfg.BaseURL, cfg.Model, cfg.TimeoutSec, cfg.TLSSkipVerify, logger)
+		o.SetOptions(Options{Language: cfg.Language, Prompt: cfg.Prompt, Temperature: cfg.Temperature})
+		return o, nil
 	case "command":
 		if cfg.Command == "" {
 			return nil, fmt.Errorf("command provider requires a non-empty command")
@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/hotkey"
+)
+
+// bindView is the state of the hotkey capture, which picks a hotkey by
+// listening for it on every input device.
+type bindView struct {
+	open    bool
+	ctx     context.Context // cancelled to stop the capture
+	cancel  context.CancelFunc
+	presses <-chan hotkey.KeyPress
+	devices []string
+	last    *hotkey.KeyPress // the last key seen
+	combo   string           // the last combo pressed and released
+	saved   bool
+	err     string
+}
+
+type bindStartedMsg struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	presses <-chan hotkey.KeyPress
+	devices []string
+	err     error
+}
+
+type bindKeyMsg struct{ press hotkey.KeyPress }
+
+// startCaptureCmd opens the input devices and starts reporting their keys.
+func startCaptureCmd(newCapture func() (hotkey.Capture, error)) tea.Cmd {
+	return func() tea.Msg {
+		c, err := newCapture()
+		if err != nil {
+			return bindStartedMsg{err: err}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		presses := make(chan hotkey.KeyPress)
+		go func() {
+			_ = c.Start(ctx, func(p hotkey.KeyPress) {
+				select {
+				case presses <- p:
+				case <-ctx.Done():
+				}
+			})
+		}()
+		return bindStartedMsg{ctx: ctx, cancel: cancel, presses: presses, devices: c.Devices()}
+	}
+}
+
+// waitKeyCmd waits for the capture's next key.
+func waitKeyCmd(ctx context.Context, presses <-chan hotkey.KeyPress) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case p := <-presses:
+			return bindKeyMsg{press: p}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handle records a capture message and returns the command that waits for
+// the next key.
+func (v *bindView) handle(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case bindStartedMsg:
+		if msg.err != nil {
+			v.err = msg.err.Error()
+			return nil
+		}
+		if !v.open {
+			msg.cancel() // closed before the devices were open
+			return nil
+		}
+		v.ctx, v.cancel, v.presses, v.devices = msg.ctx, msg.cancel, msg.presses, msg.devices
+		return waitKeyCmd(v.ctx, v.presses)
+	case bindKeyMsg:
+		if v.ctx == nil || v.ctx.Err() != nil {
+			return nil
+		}
+		v.last = &msg.press
+		if msg.press.Done {
+			v.combo = msg.press.Combo
+		}
+		return waitKeyCmd(v.ctx, v.presses)
+	}
+	return nil
+}
+
+// stop stops the capture.
+func (v *bindView) stop() {
+	if v.cancel != nil {
+		v.cancel()
+	}
+}
+
+// setHotkey sets hotkey.key to combo, or adds a [[hotkey.binding]] for
+// action if it is not empty.
+func setHotkey(cfg *config.Config, action, combo string) {
+	if action == "" {
+		cfg.Hotkey.Key = combo
+		return
+	}
+	cfg.Hotkey.Bindings = append(cfg.Hotkey.Bindings, config.HotkeyBinding{Key: combo, Action: action})
+}
+
+// render renders the capture. target names the setting being bound and
+// current its value, if any.
+func (v bindView) render(target, current string) string {
+	var b strings.Builder
+	header := "Bind " + target
+	if current != "" {
+		header += "  (now " + current + ")"
+	}
+	b.WriteString(labelStyle.Render(header))
+	b.WriteString("\n")
+
+	switch {
+	case v.err != "":
+		b.WriteString(statusBadStyle.Width(panelContentWidth).Render("Cannot read input devices: " + v.err))
+		b.WriteString("\n\n")
+		b.WriteString(quitStyle.Render("esc: close"))
+		return b.String()
+	case v.saved:
+		b.WriteString(bodyStyle.Render(fmt.Sprintf("Saved %s = %s. Restart palaver to use it.", target, v.combo)))
+		b.WriteString("\n\n")
+		b.WriteString(quitStyle.Render("enter/esc: close"))
+		return b.String()
+	case v.ctx == nil:
+		b.WriteString(bodyStyle.Render("Opening input devices..."))
+		b.WriteString("\n\n")
+		b.WriteString(quitStyle.Render("esc: cancel"))
+		return b.String()
+	}
+
+	b.WriteString(bodyStyle.Render(fmt.Sprintf("Press the key, button, or combo to use, on any of %d devices.", len(v.devices))))
+	b.WriteString("\n\n")
+	if v.last != nil {
+		name := v.last.Name
+		if name == "" {
+			name = "unnamed key"
+		}
+		seen := fmt.Sprintf("Pressed: %s (code %d) on %s", name, v.last.Code, v.last.Device)
+		b.WriteString(quitStyle.Width(panelContentWidth).Render(seen))
+		b.WriteString("\n")
+	}
+	if v.combo != "" {
+		b.WriteString(hotkeyStyle.Render("New hotkey: " + v.combo))
+		b.WriteString("\n\n")
+		b.WriteString(quitStyle.Render("enter: save  esc: cancel  or press another key"))
+	} else {
+		b.WriteString("\n")
+		b.WriteString(quitStyle.Render("esc: cancel"))
+	}
+	return b.String()
+}
+
+// updateBindKey handles key presses in the terminal while the capture is
+// open. Keys pressed to bind are seen here too, so only enter and esc act.
+func (m Model) updateBindKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.bindView
+	switch msg.String() {
+	case "esc":
+		v.stop()
+		m.bindView = bindView{}
+	case "enter":
+		if v.saved {
+			m.bindView = bindView{}
+			return m, nil
+		}
+		if v.combo == "" {
+			return m, nil
+		}
+		v.stop()
+		v.saved = true
+		setHotkey(m.Config, "", v.combo)
+		m.Logger.Printf("hotkey: saved hotkey.key = %s", v.combo)
+		return m, m.saveConfigCmd()
+	}
+	return m, nil
+}
+
+// BindModel is the hotkey capture on its own, for `palaver bind`. It quits
+// once the hotkey is saved or the capture is cancelled.
+type BindModel struct {
+	Saved string // the hotkey saved, if any
+	Err   error  // why the capture or the save failed
+
+	cfg        *config.Config
+	path       string
+	action     string
+	newCapture func() (hotkey.Capture, error)
+	view       bindView
+}
+
+// NewBindModel returns a BindModel that sets hotkey.key, or adds a
+// [[hotkey.binding]] for action if it is not empty, and saves cfg to path.
+func NewBindModel(cfg *config.Config, path, action string, newCapture func() (hotkey.Capture, error)) BindModel {
+	return BindModel{cfg: cfg, path: path, action: action, newCapture: newCapture, view: bindView{open: true}}
+}
+
+// Init starts the capture.
+func (m BindModel) Init() tea.Cmd {
+	return startCaptureCmd(m.newCapture)
+}
+
+// Update handles capture messages and enter, esc, and ctrl+c.
+func (m BindModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			m.view.stop()
+			return m, tea.Quit
+		case "enter":
+			if m.view.combo == "" || m.view.saved {
+				return m, nil
+			}
+			m.view.stop()
+			m.view.saved = true
+			setHotkey(m.cfg, m.action, m.view.combo)
+			cfg, path := m.cfg, m.path
+			return m, func() tea.Msg {
+				return configSavedMsg{err: config.Save(path, cfg)}
+			}
+		}
+	case configSavedMsg:
+		if msg.err != nil {
+			m.Err = msg.err
+		} else {
+			m.Saved = m.view.combo
+		}
+		return m, tea.Quit
+	case bindStartedMsg:
+		cmd := m.view.handle(msg)
+		if msg.err != nil {
+			m.Err = msg.err
+			return m, tea.Quit
+		}
+		return m, cmd
+	case bindKeyMsg:
+		return m, m.view.handle(msg)
+	}
+	return m, nil
+}
+
+// View renders the capture.
+func (m BindModel) View() string {
+	if m.Saved != "" || m.Err != nil {
+		return "" // main reports the outcome
+	}
+	target, current := "hotkey.key", m.cfg.Hotkey.Key
+	if m.action != "" {
+		target, current = "a "+m.action+" binding", ""
+	}
+	return m.view.render(target, current) + "\n"
+}
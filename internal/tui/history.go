@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Danondso/palaver/internal/clipboard"
+	"github.com/Danondso/palaver/internal/history"
+)
+
+// repasteDelayMs gives the user time to focus the target window before a
+// history entry is pasted again; the TUI itself has focus when enter is pressed.
+const repasteDelayMs = 2000
+
+// historyPageSize is the number of entries shown at once in the browser.
+const historyPageSize = 8
+
+// historyView is the state of the transcription history browser.
+type historyView struct {
+	open      bool
+	entries   []history.Entry // all entries, oldest first
+	query     string
+	searching bool // keystrokes edit query
+	cursor    int  // index into visible()
+	status    string
+}
+
+// visible returns the entries matching the query, newest first.
+func (h historyView) visible() []history.Entry {
+	matches := history.Search(h.entries, h.query)
+	out := make([]history.Entry, len(matches))
+	for i, e := range matches {
+		out[len(matches)-1-i] = e
+	}
+	return out
+}
+
+// selected returns the entry under the cursor.
+func (h historyView) selected() (history.Entry, bool) {
+	vis := h.visible()
+	if h.cursor < 0 || h.cursor >= len(vis) {
+		return history.Entry{}, false
+	}
+	return vis[h.cursor], true
+}
+
+type historyLoadedMsg struct {
+	entries []history.Entry
+	err     error
+}
+
+type historyCopiedMsg struct{ err error }
+
+func (m Model) loadHistoryCmd() tea.Cmd {
+	store := m.History
+	return func() tea.Msg {
+		entries, err := store.Load()
+		return historyLoadedMsg{entries: entries, err: err}
+	}
+}
+
+func copyTextCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		return historyCopiedMsg{err: clipboard.CopyText(text)}
+	}
+}
+
+// updateHistoryKey handles key presses while the history browser is open.
+func (m Model) updateHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := &m.historyView
+	key := msg.String()
+
+	if h.searching {
+		switch msg.Type {
+		case tea.KeyEnter:
+			h.searching = false
+		case tea.KeyEsc:
+			h.searching = false
+			h.query = ""
+			h.cursor = 0
+		case tea.KeyBackspace:
+			if r := []rune(h.query); len(r) > 0 {
+				h.query = string(r[:len(r)-1])
+				h.cursor = 0
+			}
+		case tea.KeySpace:
+			h.query += " "
+			h.cursor = 0
+		case tea.KeyRunes:
+			h.query += string(msg.Runes)
+			h.cursor = 0
+		}
+		return m, nil
+	}
+
+	count := len(h.visible())
+	switch key {
+	case "esc", "h", "q":
+		m.historyView = historyView{}
+	case "up", "k":
+		if h.cursor > 0 {
+			h.cursor--
+		}
+	case "down", "j":
+		if h.cursor < count-1 {
+			h.cursor++
+		}
+	case "pgup":
+		h.cursor = max(h.cursor-historyPageSize, 0)
+	case "pgdown":
+		h.cursor = max(min(h.cursor+historyPageSize, count-1), 0)
+	case "/":
+		h.searching = true
+		h.status = ""
+	case "c":
+		if e, ok := h.selected(); ok {
+			return m, copyTextCmd(e.Text)
+		}
+	case "enter":
+		e, ok := h.selected()
+		if !ok || m.State != StateIdle {
+			return m, nil
+		}
+		m.Logger.Printf("paste: re-pasting history entry from %s in %dms", e.Time.Format(time.Kitchen), repasteDelayMs)
+		if err := m.Pipeline.Repaste(e.Text, max(m.Config.Paste.DelayMs, repasteDelayMs)); err != nil {
+			h.status = "re-paste failed: " + err.Error()
+			return m, nil
+		}
+		m.historyView = historyView{}
+		m.State = StatePasting
+	}
+	return m, nil
+}
+
+// renderHistory renders the history browser in place of the transcript area.
+func (m Model) renderHistory() string {
+	h := m.historyView
+	var b strings.Builder
+
+	header := fmt.Sprintf("History (%d)", len(h.entries))
+	if h.query != "" || h.searching {
+		header += "  search: " + h.query
+		if h.searching {
+			header += "▏"
+		}
+	}
+	b.WriteString(labelStyle.Render(header))
+	b.WriteString("\n")
+
+	vis := h.visible()
+	if len(vis) == 0 {
+		if len(h.entries) == 0 {
+			b.WriteString(bodyStyle.Render("(no transcriptions yet)"))
+		} else {
+			b.WriteString(bodyStyle.Render("(no matches)"))
+		}
+	}
+
+	// Scroll so the cursor stays within the page.
+	start := 0
+	if h.cursor >= historyPageSize {
+		start = h.cursor - historyPageSize + 1
+	}
+	end := min(start+historyPageSize, len(vis))
+	textWidth := panelContentWidth - 16
+	for i := start; i < end; i++ {
+		e := vis[i]
+		text := strings.Join(strings.Fields(e.Text), " ")
+		if r := []rune(text); len(r) > textWidth {
+			text = string(r[:textWidth-3]) + "..."
+		}
+		row := fmt.Sprintf("%s  %s", e.Time.Local().Format("Jan 02 15:04"), text)
+		if i == h.cursor {
+			b.WriteString(hotkeyStyle.Render("› " + row))
+		} else {
+			b.WriteString(bodyStyle.Render("  " + row))
+		}
+		b.WriteString("\n")
+	}
+
+	if e, ok := h.selected(); ok {
+		b.WriteString("\n")
+		b.WriteString(transcriptStyle.Width(panelContentWidth).Render(fmt.Sprintf("%q", e.Text)))
+		b.WriteString("\n")
+		if e.Raw != e.Text {
+			b.WriteString(quitStyle.Width(panelContentWidth).Render(fmt.Sprintf("raw: %q", e.Raw)))
+			b.WriteString("\n")
+		}
+		meta := fmt.Sprintf("tone: %s  model: %s  latency: %dms  paste: %s", orNA(e.Tone), orNA(e.Model), e.LatencyMs, orNA(e.PasteMode))
+		if e.Backend != "" {
+			meta += "  backend: " + e.Backend
+		}
+		if e.Language != "" {
+			meta += "  language: " + e.Language
+		}
+		if e.Profile != "" {
+			meta += "  profile: " + e.Profile
+		}
+		if e.PasteError != "" {
+			meta += "  (paste failed)"
+		}
+		b.WriteString(quitStyle.Render(meta))
+		b.WriteString("\n")
+	}
+
+	if h.status != "" {
+		b.WriteString(quitStyle.Render(h.status))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if h.searching {
+		b.WriteString(quitStyle.Render("type to search  enter: done  esc: clear"))
+	} else {
+		b.WriteString(quitStyle.Render("↑/↓: scroll  /: search  c: copy  enter: re-paste  esc: close"))
+	}
+	return b.String()
+}
+
+func orNA(s string) string {
+	if s == "" {
+		return "n/a"
+	}
+	return s
+}
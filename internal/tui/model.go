@@ -1,17 +1,19 @@
 package tui
 
 import (
+	"cmp"
 	"context"
-	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/Danondso/palaver/internal/chime"
-	"github.com/Danondso/palaver/internal/clipboard"
 	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/history"
+	"github.com/Danondso/palaver/internal/hotkey"
+	"github.com/Danondso/palaver/internal/pipeline"
 	"github.com/Danondso/palaver/internal/postprocess"
 	"github.com/Danondso/palaver/internal/server"
 	"github.com/Danondso/palaver/internal/transcriber"
@@ -28,57 +30,30 @@ type MicChecker interface {
 	MicName() string
 }
 
-// State represents the application state.
-type State int
+// State is the pipeline state mirrored by the TUI.
+type State = pipeline.State
 
 const (
-	StateIdle State = iota
-	StateRecording
-	StateTranscribing
-	StatePostProcessing
-	StatePasting
-	StateError
+	StateIdle           = pipeline.StateIdle
+	StateRecording      = pipeline.StateRecording
+	StateTranscribing   = pipeline.StateTranscribing
+	StatePostProcessing = pipeline.StatePostProcessing
+	StatePasting        = pipeline.StatePasting
+	StateError          = pipeline.StateError
 )
 
-// Messages sent through the Bubble Tea update loop.
-
-type RecordingStartedMsg struct{}
-
-type RecordingStoppedMsg struct {
-	WavData []byte
-}
-
-type TranscriptionResultMsg struct {
-	Text string
-}
-
-type TranscriptionErrorMsg struct {
-	Err error
-}
-
-type PostProcessResultMsg struct {
-	Text         string
-	OriginalText string
-	NeedsSpace   bool
-}
-
-type PostProcessErrorMsg struct {
-	Err          error
-	OriginalText string
-	NeedsSpace   bool
-}
+// Messages sent through the Bubble Tea update loop. Pipeline transitions
+// arrive as pipeline.Event values; see ForwardEvents.
 
 type PPModelsListMsg struct {
 	Models []string
 	Err    error
 }
 
-type PasteDoneMsg struct{ Err error }
-
-type errorTimeoutMsg struct{}
-
 type configSavedMsg struct{ err error }
 
+type undoDoneMsg struct{ err error }
+
 type audioLevelTickMsg struct{}
 
 // StatusCheckMsg carries the result of a mic + backend availability check.
@@ -100,6 +75,18 @@ type ServerStateMsg struct {
 type serverStartDoneMsg struct{ err error }
 type serverStartingMsg struct{}
 
+// ThemeMsg switches the theme from outside the TUI, e.g. the control API.
+// The name must be one HasTheme accepts.
+type ThemeMsg struct {
+	Name string
+}
+
+// InputDevicesMsg reports the input devices the hotkey is read from, when
+// devices are plugged in or removed.
+type InputDevicesMsg struct {
+	Devices []string
+}
+
 // DebugEntry is a structured debug log entry.
 type DebugEntry struct {
 	Time     string // e.g. "11:27:53"
@@ -114,59 +101,101 @@ type DebugLogMsg struct {
 
 const maxDebugLines = 50
 
-// Model is the Bubble Tea model for the Palaver TUI.
+// Model is the Bubble Tea model for the Palaver TUI. It observes a
+// pipeline.Pipeline, which does the recording-to-paste work; State,
+// LastTranscript, PartialTranscript, LastError, Latched, and Armed mirror
+// the pipeline's status.
 type Model struct {
-	State          State
-	LastTranscript string
-	LastError      string
-	Config         *config.Config
-	Transcriber    transcriber.Transcriber
-	Chime          *chime.Player
-	HotkeyName     string
-	Logger         *log.Logger
-	DebugMode      bool
-	DebugEntries   []DebugEntry
-	AudioLevel     float64
-	Recorder       LevelSampler
-	MicChecker     MicChecker
-	MicDetected    bool
-	MicDeviceName  string
-	BackendOnline  bool
-	ModelName      string
-	statusChecked  bool
-	themeName      string
-	PostProcessor  postprocess.PostProcessor
-	toneName       string
-	ppModelName    string
-	ppModels       []string
-	Server         *server.Server     // nil if not using managed server
-	serverState    string             // "", "starting", "running", "stopped", "error"
-	ServerCtx      context.Context    // cancellable context for server operations
-	ServerCancel   context.CancelFunc // cancel function for ServerCtx
+	State             State
+	LastTranscript    string
+	PartialTranscript string // live text while a streaming transcription runs
+	LastError         string
+	Config            *config.Config
+	Pipeline          *pipeline.Pipeline
+	Transcriber       transcriber.Transcriber // used for status checks
+	HotkeyName        string
+	HotkeyMode        string   // "hold", "toggle", or "hybrid"
+	Latched           bool     // recording continues after the hotkey is released
+	Trigger           string   // "hotkey" or "vad"
+	Armed             bool     // hands-free listening is active
+	ServedBy          string   // backend that served the last transcript, with fallback backends
+	Uncertain         []string // low-confidence segments of LastTranscript
+	levelTicking      bool     // an audioLevelTickCmd chain is running
+	Logger            *log.Logger
+	DebugMode         bool
+	DebugEntries      []DebugEntry
+	AudioLevel        float64
+	Recorder          LevelSampler
+	MicChecker        MicChecker
+	MicDetected       bool
+	MicDeviceName     string
+	BackendOnline     bool
+	ModelName         string
+	PasteBackend      string   // paste backend in use; empty hides it
+	PasteErr          string   // why the paste backend failed its startup probe
+	LanguageWarn      string   // why the managed server cannot transcribe the selected language
+	InputDevices      []string // devices the hotkey is read from; nil hides them
+	statusChecked     bool
+	themeName         string
+	PostProcessor     postprocess.PostProcessor
+	toneName          string
+	ppModelName       string
+	ppModels          []string
+	language          string             // requested transcription language, "auto" to detect
+	languages         []string           // choices cycled by the l key
+	Server            *server.Server     // nil if not using managed server
+	serverState       string             // "", "starting", "running", "stopped", "error"
+	ServerCtx         context.Context    // cancellable context for server operations
+	ServerCancel      context.CancelFunc // cancel function for ServerCtx
+	History           *history.Store     // nil if history is disabled; the pipeline saves entries
+	historyView       historyView
+	bindView          bindView
+	undoErr           string // why the last u press did not undo
+
+	// NewCapture opens the capture the b key uses to pick a new hotkey by
+	// pressing it; nil hides the key.
+	NewCapture func() (hotkey.Capture, error)
 }
 
 // NewModel creates a new TUI model.
-func NewModel(cfg *config.Config, t transcriber.Transcriber, pp postprocess.PostProcessor, c *chime.Player, rec LevelSampler, mc MicChecker, logger *log.Logger, debug bool) Model {
+func NewModel(cfg *config.Config, pipe *pipeline.Pipeline, t transcriber.Transcriber, pp postprocess.PostProcessor, rec LevelSampler, mc MicChecker, logger *log.Logger, debug bool) Model {
 	RegisterCustomThemes(cfg.CustomThemes)
 	themeName := cfg.Theme
 	applyTheme(LoadTheme(themeName))
 	return Model{
 		State:         StateIdle,
 		Config:        cfg,
+		Pipeline:      pipe,
 		Transcriber:   t,
 		PostProcessor: pp,
-		Chime:         c,
 		Recorder:      rec,
 		MicChecker:    mc,
 		HotkeyName:    cfg.Hotkey.Key,
+		HotkeyMode:    strings.ToLower(cfg.Hotkey.Mode),
+		Trigger:       strings.ToLower(cfg.Audio.Trigger),
 		Logger:        logger,
 		DebugMode:     debug,
 		themeName:     themeName,
 		toneName:      cfg.PostProcessing.Tone,
 		ppModelName:   cfg.PostProcessing.Model,
+		language:      cmp.Or(strings.ToLower(cfg.Transcription.Language), "auto"),
+		languages:     languageChoices(&cfg.Transcription),
 	}
 }
 
+// languageChoices lists the languages the l key cycles through: auto-detect,
+// the configured language, then transcription.languages.
+func languageChoices(cfg *config.TranscriptionConfig) []string {
+	choices := []string{"auto"}
+	for _, lang := range append([]string{cfg.Language}, cfg.Languages...) {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang != "" && !slices.Contains(choices, lang) {
+			choices = append(choices, lang)
+		}
+	}
+	return choices
+}
+
 // Init returns the initial command.
 func (m Model) Init() tea.Cmd {
 	cmds := []tea.Cmd{m.statusCheckCmd()}
@@ -193,22 +222,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.historyView.open && msg.String() != "ctrl+c" {
+			return m.updateHistoryKey(msg)
+		}
+		if m.bindView.open && msg.String() != "ctrl+c" {
+			return m.updateBindKey(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "t":
-			next := NextTheme(m.themeName)
-			applyTheme(next)
-			m.themeName = strings.ToLower(next.Name)
-			m.Config.Theme = m.themeName
-			return m, m.saveConfigCmd()
+			return m, m.setTheme(NextTheme(m.themeName))
 		case "p":
 			next := postprocess.NextTone(m.toneName)
 			m.toneName = next
 			m.Config.PostProcessing.Tone = next
 			if next == "off" {
 				m.Config.PostProcessing.Enabled = false
-				m.PostProcessor = &postprocess.NoopPostProcessor{}
+				m.rebuildPostProcessor()
 				return m, m.saveConfigCmd()
 			}
 			m.Config.PostProcessing.Enabled = true
@@ -229,43 +260,85 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.rebuildPostProcessor()
 				return m, tea.Batch(m.saveConfigCmd(), m.ppListModelsCmd())
 			}
+		case "l":
+			if len(m.languages) > 1 {
+				i := slices.Index(m.languages, m.language)
+				m.language = m.languages[(i+1)%len(m.languages)]
+				m.Config.Transcription.Language = m.language
+				m.Pipeline.SetLanguage(m.language)
+				if m.Server != nil {
+					m.LanguageWarn = m.Server.LanguageWarning(m.language)
+				}
+				return m, m.saveConfigCmd()
+			}
+		case "u":
+			m.undoErr = ""
+			return m, m.undoCmd()
+		case "h":
+			if m.History != nil {
+				m.historyView = historyView{open: true}
+				return m, m.loadHistoryCmd()
+			}
 		case "r":
 			if m.Server != nil {
 				m.serverState = "starting"
 				return m, m.serverRestartCmd()
 			}
+		case "b":
+			if m.NewCapture != nil {
+				m.bindView = bindView{open: true}
+				return m, startCaptureCmd(m.NewCapture)
+			}
+		}
+
+	case ThemeMsg:
+		return m, m.setTheme(LoadTheme(msg.Name))
+
+	case InputDevicesMsg:
+		m.InputDevices = msg.Devices
+		if m.InputDevices == nil {
+			m.InputDevices = []string{}
 		}
+		return m, nil
 
-	case RecordingStartedMsg:
-		m.State = StateRecording
-		m.LastError = ""
-		if m.Chime != nil {
-			m.Chime.PlayStart()
+	case pipeline.Event:
+		m.State = msg.Status.State
+		m.LastTranscript = msg.Status.LastTranscript
+		m.PartialTranscript = msg.Status.Partial
+		m.LastError = msg.Status.LastError
+		m.Latched = msg.Status.Latched
+		m.Armed = msg.Status.Armed
+		m.ServedBy = msg.Status.Backend
+		m.Uncertain = msg.Status.Uncertain
+		if msg.Kind == pipeline.EventPasted && msg.Entry != nil && m.historyView.open {
+			m.historyView.entries = append(m.historyView.entries, *msg.Entry)
+		}
+		var settingsCmd tea.Cmd
+		if msg.Kind == pipeline.EventSettings {
+			settingsCmd = m.syncPostProcessing()
+		}
+		if m.State == StateRecording || m.Armed {
+			return m, tea.Batch(settingsCmd, m.startLevelTick())
 		}
-		return m, audioLevelTickCmd()
+		m.AudioLevel = 0
+		return m, settingsCmd
 
 	case audioLevelTickMsg:
-		if m.State == StateRecording && m.Recorder != nil {
+		if (m.State == StateRecording || m.Armed) && m.Recorder != nil {
 			m.AudioLevel = m.Recorder.AudioLevel()
 			return m, audioLevelTickCmd()
 		}
+		m.levelTicking = false
 		m.AudioLevel = 0
 		return m, nil
 
-	case RecordingStoppedMsg:
-		m.State = StateTranscribing
-		m.AudioLevel = 0
-		if m.Chime != nil {
-			m.Chime.PlayStop()
-		}
-		return m, m.transcribeCmd(msg.WavData)
-
 	case StatusCheckMsg:
 		m.MicDetected = msg.MicDetected
 		m.MicDeviceName = msg.MicDeviceName
 		m.BackendOnline = msg.BackendOnline
 		if msg.ModelName != "" {
 			m.ModelName = msg.ModelName
+			m.Pipeline.SetModelName(msg.ModelName)
 		}
 		m.statusChecked = true
 		return m, scheduleStatusRecheck()
@@ -273,47 +346,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case statusCheckTickMsg:
 		return m, m.statusCheckCmd()
 
-	case TranscriptionResultMsg:
-		text := msg.Text
-		m.Logger.Printf("transcription result: %q", text)
-		if text == "" || text == "[BLANK_AUDIO]" {
-			m.State = StateIdle
-			m.Logger.Printf("empty transcription, skipping paste")
-			return m, nil
-		}
-		needsSpace := m.LastTranscript != ""
-		m.LastTranscript = msg.Text
-		// Post-processing gate
-		if m.Config.PostProcessing.Enabled && strings.ToLower(m.toneName) != "off" {
-			m.State = StatePostProcessing
-			return m, m.postProcessCmd(text, needsSpace)
-		}
-		// Add a leading space between consecutive transcriptions.
-		if needsSpace {
-			text = " " + text
-		}
-		m.State = StatePasting
-		return m, m.pasteCmd(text)
-
-	case PostProcessResultMsg:
-		m.Logger.Printf("post-processing result: %q", msg.Text)
-		text := msg.Text
-		// Add a leading space between consecutive transcriptions (after rewriting).
-		if msg.NeedsSpace {
-			text = " " + text
-		}
-		m.State = StatePasting
-		return m, m.pasteCmd(text)
-
-	case PostProcessErrorMsg:
-		m.Logger.Printf("post-processing error (falling back to original): %v", msg.Err)
-		text := msg.OriginalText
-		if msg.NeedsSpace {
-			text = " " + text
-		}
-		m.State = StatePasting
-		return m, m.pasteCmd(text)
-
 	case PPModelsListMsg:
 		if msg.Err != nil {
 			m.Logger.Printf("failed to list post-processing models: %v", msg.Err)
@@ -340,23 +372,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-	case PasteDoneMsg:
-		if msg.Err != nil {
-			m.State = StateError
-			m.LastError = msg.Err.Error()
-			return m, scheduleErrorTimeout()
+	case historyLoadedMsg:
+		if msg.err != nil {
+			m.Logger.Printf("history load failed: %v", msg.err)
+			m.historyView.status = "failed to load history: " + msg.err.Error()
 		}
-		m.State = StateIdle
-		return m, nil
+		m.historyView.entries = msg.entries
+		m.historyView.cursor = 0
 
-	case TranscriptionErrorMsg:
-		m.State = StateError
-		m.LastError = msg.Err.Error()
-		return m, scheduleErrorTimeout()
+	case bindStartedMsg, bindKeyMsg:
+		return m, m.bindView.handle(msg)
 
-	case errorTimeoutMsg:
-		m.State = StateIdle
-		m.LastError = ""
+	case historyCopiedMsg:
+		if msg.err != nil {
+			m.historyView.status = "copy failed: " + msg.err.Error()
+		} else {
+			m.historyView.status = "copied to clipboard"
+		}
 
 	case serverStartingMsg:
 		m.serverState = "starting"
@@ -378,6 +410,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.Logger.Printf("failed to save config: %v", msg.err)
 		}
 
+	case undoDoneMsg:
+		if msg.err != nil {
+			m.Logger.Printf("undo: %v", msg.err)
+			m.undoErr = msg.err.Error()
+		}
+
 	case DebugLogMsg:
 		m.DebugEntries = append(m.DebugEntries, msg.Entry)
 		if len(m.DebugEntries) > maxDebugLines {
@@ -388,40 +426,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) transcribeCmd(wavData []byte) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		text, err := m.Transcriber.Transcribe(ctx, wavData)
-		if err != nil {
-			return TranscriptionErrorMsg{Err: err}
-		}
-		return TranscriptionResultMsg{Text: text}
-	}
-}
+const audioLevelTickInterval = 100 * time.Millisecond
 
-func (m Model) pasteCmd(text string) tea.Cmd {
-	delayMs := m.Config.Paste.DelayMs
-	mode := m.Config.Paste.Mode
-	logger := m.Logger
-	return func() tea.Msg {
-		logger.Printf("paste: mode=%s delay=%dms", mode, delayMs)
-		if err := clipboard.PasteText(text, delayMs, mode); err != nil {
-			logger.Printf("paste error: %v", err)
-			return PasteDoneMsg{Err: fmt.Errorf("paste: %w", err)}
-		}
-		logger.Printf("paste: success")
-		return PasteDoneMsg{}
+// startLevelTick starts polling the recorder's audio level unless a tick
+// chain is already running.
+func (m *Model) startLevelTick() tea.Cmd {
+	if m.levelTicking {
+		return nil
 	}
+	m.levelTicking = true
+	return audioLevelTickCmd()
 }
 
-func scheduleErrorTimeout() tea.Cmd {
-	return tea.Tick(5*time.Second, func(time.Time) tea.Msg {
-		return errorTimeoutMsg{}
-	})
-}
-
-const audioLevelTickInterval = 100 * time.Millisecond
-
 func audioLevelTickCmd() tea.Cmd {
 	return tea.Tick(audioLevelTickInterval, func(time.Time) tea.Msg {
 		return audioLevelTickMsg{}
@@ -478,6 +494,16 @@ func (m Model) saveConfigCmd() tea.Cmd {
 	}
 }
 
+// undoCmd deletes the last paste. The terminal has focus while u is
+// pressed, so the window the paste went to is focused again first, which
+// runs xdotool or osascript and so stays out of Update.
+func (m Model) undoCmd() tea.Cmd {
+	p := m.Pipeline
+	return func() tea.Msg {
+		return undoDoneMsg{err: p.Undo(true)}
+	}
+}
+
 func (m Model) serverRestartCmd() tea.Cmd {
 	srv := m.Server
 	ctx := m.ServerCtx
@@ -497,28 +523,42 @@ func (m Model) ServerStartCmd() tea.Cmd {
 	}
 }
 
-// rebuildPostProcessor creates a new LLMPostProcessor from the current config.
+// setTheme applies t and saves it to the config.
+func (m *Model) setTheme(t Theme) tea.Cmd {
+	applyTheme(t)
+	m.themeName = strings.ToLower(t.Name)
+	m.Config.Theme = m.themeName
+	return m.saveConfigCmd()
+}
+
+// rebuildPostProcessor applies the current tone and model to the pipeline
+// and keeps the post-processor used for model listing in sync.
 func (m *Model) rebuildPostProcessor() {
-	tone := postprocess.ResolveTone(m.toneName)
-	m.PostProcessor = postprocess.NewLLM(
-		m.Config.PostProcessing.BaseURL,
-		m.ppModelName,
-		tone.Prompt,
-		m.Config.PostProcessing.TimeoutSec,
-		m.Logger,
-	)
+	if err := m.Pipeline.SetPostProcessing(m.toneName, m.ppModelName); err != nil {
+		m.Logger.Printf("post-processing: %v", err)
+	}
+	m.PostProcessor = m.Pipeline.PostProcessor()
 }
 
-func (m Model) postProcessCmd(text string, needsSpace bool) tea.Cmd {
-	pp := m.PostProcessor
-	return func() tea.Msg {
-		ctx := context.Background()
-		result, err := pp.Rewrite(ctx, text)
-		if err != nil {
-			return PostProcessErrorMsg{Err: err, OriginalText: text, NeedsSpace: needsSpace}
-		}
-		return PostProcessResultMsg{Text: result, OriginalText: text, NeedsSpace: needsSpace}
+// syncPostProcessing adopts a tone or model change made outside the TUI,
+// e.g. through the control API, and saves it to the config. The pipeline's
+// current status is used rather than the event's, so a stale event cannot
+// undo a newer key press.
+func (m *Model) syncPostProcessing() tea.Cmd {
+	st := m.Pipeline.Status()
+	if strings.EqualFold(st.Tone, m.toneName) && st.PostModel == m.ppModelName {
+		return nil
+	}
+	m.toneName = st.Tone
+	m.ppModelName = st.PostModel
+	m.Config.PostProcessing.Tone = st.Tone
+	m.Config.PostProcessing.Model = st.PostModel
+	m.Config.PostProcessing.Enabled = st.Tone != "off"
+	m.PostProcessor = m.Pipeline.PostProcessor()
+	if st.Tone == "off" {
+		return m.saveConfigCmd()
 	}
+	return tea.Batch(m.saveConfigCmd(), m.ppListModelsCmd())
 }
 
 func (m Model) ppListModelsCmd() tea.Cmd {
@@ -533,3 +573,11 @@ func (m Model) ppListModelsCmd() tea.Cmd {
 		return PPModelsListMsg{}
 	}
 }
+
+// ForwardEvents sends each pipeline event to the program until events is
+// closed. Run it in its own goroutine.
+func ForwardEvents(p *tea.Program, events <-chan pipeline.Event) {
+	for ev := range events {
+		p.Send(ev)
+	}
+}
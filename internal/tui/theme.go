@@ -95,6 +95,13 @@ func LoadTheme(name string) Theme {
 	return themes["synthwave"]
 }
 
+// HasTheme reports whether name (case-insensitive) is a built-in theme or
+// a registered custom theme.
+func HasTheme(name string) bool {
+	_, ok := themes[strings.ToLower(name)]
+	return ok
+}
+
 // NextTheme returns the theme after the given one in the cycle order.
 func NextTheme(current string) Theme {
 	current = strings.ToLower(current)
@@ -168,6 +175,11 @@ func applyTheme(t Theme) {
 		Background(t.Background).
 		Italic(true)
 
+	uncertainStyle = lipgloss.NewStyle().
+		Foreground(t.Warning).
+		Background(t.Background).
+		Underline(true)
+
 	hotkeyStyle = lipgloss.NewStyle().
 		Foreground(t.Secondary).
 		Background(t.Background)
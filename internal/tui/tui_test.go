@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/Danondso/palaver/internal/config"
+	"github.com/Danondso/palaver/internal/history"
+	"github.com/Danondso/palaver/internal/hotkey"
+	"github.com/Danondso/palaver/internal/pipeline"
 	"github.com/Danondso/palaver/internal/postprocess"
 )
 
@@ -49,94 +54,169 @@ func (m *mockPostProcessor) Rewrite(_ context.Context, text string) (string, err
 
 func newTestModel() Model {
 	cfg := config.Default()
-	return NewModel(cfg, &mockTranscriber{result: "test text"}, &postprocess.NoopPostProcessor{}, nil, nil, nil, log.New(io.Discard, "", 0), false)
+	logger := log.New(io.Discard, "", 0)
+	trans := &mockTranscriber{result: "test text"}
+	pp := &postprocess.NoopPostProcessor{}
+	pipe := pipeline.New(cfg, trans, pp, nil, nil, logger)
+	pipe.SetPasteFunc(func(string, int, string) error { return nil })
+	pipe.SetFocusFuncs(func() (string, error) { return "", nil }, func(string) error { return nil })
+	return NewModel(cfg, pipe, trans, pp, nil, nil, logger, false)
+}
+
+// event builds a pipeline event carrying the given status.
+func event(kind pipeline.EventKind, status pipeline.Status) pipeline.Event {
+	return pipeline.Event{Kind: kind, Status: status}
 }
 
 func TestInitialState(t *testing.T) {
 	m := newTestModel()
 	if m.State != StateIdle {
-		t.Errorf("expected StateIdle, got %d", m.State)
+		t.Errorf("expected StateIdle, got %s", m.State)
 	}
 	if m.LastTranscript != "" {
 		t.Error("expected empty transcript")
 	}
 }
 
-func TestRecordingStartedTransition(t *testing.T) {
+func TestPipelineEventMirrorsStatus(t *testing.T) {
 	m := newTestModel()
-	updated, _ := m.Update(RecordingStartedMsg{})
+	updated, _ := m.Update(event(pipeline.EventError, pipeline.Status{
+		State:          StateError,
+		LastTranscript: "hello world",
+		LastError:      "connection refused",
+	}))
 	model := updated.(Model)
-	if model.State != StateRecording {
-		t.Errorf("expected StateRecording, got %d", model.State)
+	if model.State != StateError {
+		t.Errorf("expected StateError, got %s", model.State)
+	}
+	if model.LastTranscript != "hello world" {
+		t.Errorf("expected 'hello world', got %q", model.LastTranscript)
+	}
+	if model.LastError != "connection refused" {
+		t.Errorf("expected 'connection refused', got %q", model.LastError)
 	}
 }
 
-func TestRecordingStoppedTransition(t *testing.T) {
+func TestStatusBarShowsServingBackend(t *testing.T) {
 	m := newTestModel()
-	m.State = StateRecording
-	updated, cmd := m.Update(RecordingStoppedMsg{WavData: []byte("wav")})
-	model := updated.(Model)
-	if model.State != StateTranscribing {
-		t.Errorf("expected StateTranscribing, got %d", model.State)
-	}
-	if cmd == nil {
-		t.Error("expected transcription command")
+	m.statusChecked = true
+	m.BackendOnline = true
+	updated, _ := m.Update(event(pipeline.EventTranscribed, pipeline.Status{State: StatePasting, Backend: "remote"}))
+	if got := updated.(Model).renderStatusBar(); !contains(got, "(remote)") {
+		t.Errorf("expected status bar to name the serving backend, got %q", got)
 	}
 }
 
-func TestTranscriptionResultTransition(t *testing.T) {
+func TestStatusBarShowsPasteBackend(t *testing.T) {
 	m := newTestModel()
-	m.State = StateTranscribing
-	updated, _ := m.Update(TranscriptionResultMsg{Text: "hello world"})
-	model := updated.(Model)
-	if model.State != StatePasting {
-		t.Errorf("expected StatePasting, got %d", model.State)
+	m.statusChecked = true
+	if contains(m.renderStatusBar(), "Paste:") {
+		t.Error("expected no paste backend without one set")
 	}
-	if model.LastTranscript != "hello world" {
-		t.Errorf("expected 'hello world', got %q", model.LastTranscript)
+	m.PasteBackend = "wtype"
+	if got := m.renderStatusBar(); !contains(got, "Paste: ✓ (wtype)") {
+		t.Errorf("expected the paste backend in the status bar, got %q", got)
+	}
+	m.PasteErr = "wtype not found"
+	if got := m.renderStatusBar(); !contains(got, "✗ (wtype)") {
+		t.Errorf("expected a failed probe marked, got %q", got)
+	}
+	if !contains(m.View(), "wtype not found") {
+		t.Error("expected the probe error shown")
+	}
+}
+
+func TestLowConfidenceSegmentsFlagged(t *testing.T) {
+	m := newTestModel()
+	updated, _ := m.Update(event(pipeline.EventPasted, pipeline.Status{
+		State:          StateIdle,
+		LastTranscript: "Ship it. Maybe Tuesday.",
+		Uncertain:      []string{"Maybe Tuesday."},
+	}))
+	view := updated.(Model).View()
+	if !contains(view, `low confidence: "Maybe Tuesday."`) {
+		t.Error("expected the low-confidence segment to be flagged")
+	}
+	if contains(view, `low confidence: "Ship it."`) {
+		t.Error("expected confident segments not to be flagged")
 	}
 }
 
-func TestBlankAudioTranscriptionSkipsPaste(t *testing.T) {
+func TestRecordingLatchedShowsTapToStop(t *testing.T) {
 	m := newTestModel()
-	m.State = StateTranscribing
-	updated, cmd := m.Update(TranscriptionResultMsg{Text: "[BLANK_AUDIO]"})
+	m.HotkeyMode = "hybrid"
+	updated, _ := m.Update(event(pipeline.EventRecordingLatched, pipeline.Status{State: StateRecording, Latched: true}))
 	model := updated.(Model)
-	if model.State != StateIdle {
-		t.Errorf("expected StateIdle, got %d", model.State)
+	if !model.Latched {
+		t.Fatal("expected Latched after a latched event")
 	}
-	if cmd != nil {
-		t.Error("expected no command for blank audio")
+	if !contains(model.View(), "tap to stop") {
+		t.Error("expected badge to say 'tap to stop' while latched")
+	}
+
+	updated, _ = model.Update(event(pipeline.EventRecordingStopped, pipeline.Status{State: StateTranscribing}))
+	if updated.(Model).Latched {
+		t.Error("expected Latched cleared after recording stops")
+	}
+}
+
+func TestViewShowsHotkeyModeHint(t *testing.T) {
+	tests := []struct {
+		mode string
+		hint string
+	}{
+		{"hold", "hold to record"},
+		{"toggle", "tap to start/stop"},
+		{"hybrid", "tap to latch"},
+	}
+	for _, tt := range tests {
+		m := newTestModel()
+		m.HotkeyMode = tt.mode
+		if !contains(m.View(), tt.hint) {
+			t.Errorf("mode %s: expected view to contain %q", tt.mode, tt.hint)
+		}
 	}
 }
 
-func TestTranscriptionErrorTransition(t *testing.T) {
+func TestPartialTranscriptShownWhileRecording(t *testing.T) {
 	m := newTestModel()
-	m.State = StateTranscribing
-	updated, cmd := m.Update(TranscriptionErrorMsg{Err: fmt.Errorf("connection refused")})
+	updated, _ := m.Update(event(pipeline.EventPartial, pipeline.Status{
+		State:          StateRecording,
+		LastTranscript: "previous text",
+		Partial:        "live words",
+	}))
 	model := updated.(Model)
-	if model.State != StateError {
-		t.Errorf("expected StateError, got %d", model.State)
+	if model.PartialTranscript != "live words" {
+		t.Errorf("expected partial 'live words', got %q", model.PartialTranscript)
 	}
-	if model.LastError != "connection refused" {
-		t.Errorf("expected 'connection refused', got %q", model.LastError)
+	view := model.View()
+	if !contains(view, "live words") {
+		t.Error("expected view to contain partial transcript")
 	}
-	if cmd == nil {
-		t.Error("expected error timeout command")
+	if contains(view, "previous text") {
+		t.Error("expected partial transcript to replace the last transcript while streaming")
+	}
+
+	updated, _ = model.Update(event(pipeline.EventTranscribed, pipeline.Status{State: StatePasting, LastTranscript: "live words done"}))
+	if updated.(Model).PartialTranscript != "" {
+		t.Error("expected partial transcript cleared on final result")
 	}
 }
 
-func TestErrorTimeoutTransition(t *testing.T) {
+func TestRecordingDiscardedReturnsToIdle(t *testing.T) {
 	m := newTestModel()
-	m.State = StateError
-	m.LastError = "some error"
-	updated, _ := m.Update(errorTimeoutMsg{})
+	m.State = StateRecording
+	m.AudioLevel = 0.3
+	updated, cmd := m.Update(event(pipeline.EventRecordingDiscarded, pipeline.Status{State: StateIdle}))
 	model := updated.(Model)
 	if model.State != StateIdle {
-		t.Errorf("expected StateIdle, got %d", model.State)
+		t.Errorf("expected StateIdle, got %s", model.State)
 	}
-	if model.LastError != "" {
-		t.Errorf("expected empty error, got %q", model.LastError)
+	if model.AudioLevel != 0 {
+		t.Errorf("expected AudioLevel reset, got %f", model.AudioLevel)
+	}
+	if cmd != nil {
+		t.Error("expected no command for discarded recording")
 	}
 }
 
@@ -273,7 +353,7 @@ func TestCustomThemeRegistrationAndCycle(t *testing.T) {
 		},
 	}
 
-	m := NewModel(cfg, &mockTranscriber{result: "test"}, &postprocess.NoopPostProcessor{}, nil, nil, nil, log.New(io.Discard, "", 0), false)
+	m := NewModel(cfg, nil, &mockTranscriber{result: "test"}, &postprocess.NoopPostProcessor{}, nil, nil, log.New(io.Discard, "", 0), false)
 
 	// Theme should be loaded and active.
 	if m.themeName != "testcustom" {
@@ -336,6 +416,39 @@ func TestCustomThemeSkipsBuiltinCollision(t *testing.T) {
 	}
 }
 
+func TestInputDevices(t *testing.T) {
+	m := newTestModel()
+	if contains(m.View(), "Devices:") {
+		t.Error("expected no devices line without a device watcher")
+	}
+	updated, _ := m.Update(InputDevicesMsg{Devices: []string{"Keychron K2 (/dev/input/event5)"}})
+	m = updated.(Model)
+	if got := m.View(); !contains(got, "Keychron K2") {
+		t.Errorf("expected the keyboard listed, got %q", got)
+	}
+	updated, _ = m.Update(InputDevicesMsg{})
+	if got := updated.(Model).View(); !contains(got, "Devices: none") {
+		t.Errorf("expected no keyboards reported, got %q", got)
+	}
+}
+
+func TestThemeMsg(t *testing.T) {
+	m := newTestModel()
+	defer applyTheme(LoadTheme("synthwave"))
+
+	if !HasTheme("Gruvbox") || HasTheme("neon") {
+		t.Fatal("HasTheme should accept built-in names case-insensitively and reject unknown ones")
+	}
+	updated, cmd := m.Update(ThemeMsg{Name: "Gruvbox"})
+	m = updated.(Model)
+	if m.themeName != "gruvbox" || m.Config.Theme != "gruvbox" {
+		t.Errorf("expected gruvbox to be applied and saved, got %s / %s", m.themeName, m.Config.Theme)
+	}
+	if cmd == nil {
+		t.Error("expected a config save command")
+	}
+}
+
 func TestAudioLevelTickUpdatesLevel(t *testing.T) {
 	m := newTestModel()
 	m.State = StateRecording
@@ -365,6 +478,55 @@ func TestAudioLevelTickResetsWhenNotRecording(t *testing.T) {
 	}
 }
 
+func TestListeningMsgArmsAndPauses(t *testing.T) {
+	m := newTestModel()
+	m.Trigger = "vad"
+	m.Recorder = &mockLevelSampler{level: 0.1}
+
+	updated, cmd := m.Update(event(pipeline.EventListening, pipeline.Status{Armed: true}))
+	model := updated.(Model)
+	if !model.Armed {
+		t.Fatal("expected Armed after an armed listening event")
+	}
+	if cmd == nil {
+		t.Error("expected audio level tick command when armed")
+	}
+	if !contains(model.View(), "Listening") {
+		t.Error("expected view to show Listening when armed")
+	}
+
+	// A segment starting while armed must not start a second tick chain.
+	updated, cmd = model.Update(event(pipeline.EventRecordingStarted, pipeline.Status{State: StateRecording, Armed: true}))
+	model = updated.(Model)
+	if cmd != nil {
+		t.Error("expected no extra tick command while already ticking")
+	}
+
+	updated, _ = model.Update(event(pipeline.EventListening, pipeline.Status{}))
+	model = updated.(Model)
+	if model.Armed {
+		t.Error("expected Armed cleared after pause")
+	}
+	if !contains(model.View(), "Paused") {
+		t.Error("expected view to show Paused when listening is paused")
+	}
+}
+
+func TestAudioLevelTickContinuesWhileArmed(t *testing.T) {
+	m := newTestModel()
+	m.State = StateIdle
+	m.Armed = true
+	m.Recorder = &mockLevelSampler{level: 0.3}
+	updated, cmd := m.Update(audioLevelTickMsg{})
+	model := updated.(Model)
+	if model.AudioLevel != 0.3 {
+		t.Errorf("expected AudioLevel 0.3, got %f", model.AudioLevel)
+	}
+	if cmd == nil {
+		t.Error("expected another tick command while armed")
+	}
+}
+
 func TestStatusCheckMsgUpdatesModel(t *testing.T) {
 	m := newTestModel()
 	updated, cmd := m.Update(StatusCheckMsg{MicDetected: true, BackendOnline: false})
@@ -418,7 +580,7 @@ func TestStatusBarAppearsInView(t *testing.T) {
 
 func TestRecordingStartedReturnsTickCmd(t *testing.T) {
 	m := newTestModel()
-	_, cmd := m.Update(RecordingStartedMsg{})
+	_, cmd := m.Update(event(pipeline.EventRecordingStarted, pipeline.Status{State: StateRecording}))
 	if cmd == nil {
 		t.Error("expected audio level tick command on recording start")
 	}
@@ -428,82 +590,13 @@ func TestRecordingStoppedResetsAudioLevel(t *testing.T) {
 	m := newTestModel()
 	m.State = StateRecording
 	m.AudioLevel = 0.7
-	updated, _ := m.Update(RecordingStoppedMsg{WavData: []byte("wav")})
+	updated, _ := m.Update(event(pipeline.EventRecordingStopped, pipeline.Status{State: StateTranscribing}))
 	model := updated.(Model)
 	if model.AudioLevel != 0 {
 		t.Errorf("expected AudioLevel 0 after stop, got %f", model.AudioLevel)
 	}
 }
 
-func TestTranscriptionResultWithPostProcessing(t *testing.T) {
-	m := newTestModel()
-	m.State = StateTranscribing
-	m.Config.PostProcessing.Enabled = true
-	m.toneName = "polite"
-	m.PostProcessor = &mockPostProcessor{result: "please help me"}
-	updated, cmd := m.Update(TranscriptionResultMsg{Text: "help me"})
-	model := updated.(Model)
-	if model.State != StatePostProcessing {
-		t.Errorf("expected StatePostProcessing, got %d", model.State)
-	}
-	if cmd == nil {
-		t.Error("expected post-process command")
-	}
-}
-
-func TestTranscriptionResultWithoutPostProcessing(t *testing.T) {
-	m := newTestModel()
-	m.State = StateTranscribing
-	m.Config.PostProcessing.Enabled = false
-	m.toneName = "off"
-	updated, cmd := m.Update(TranscriptionResultMsg{Text: "hello world"})
-	model := updated.(Model)
-	if model.State != StatePasting {
-		t.Errorf("expected StatePasting, got %d", model.State)
-	}
-	if cmd == nil {
-		t.Error("expected paste command")
-	}
-}
-
-func TestTranscriptionResultWithToneOff(t *testing.T) {
-	m := newTestModel()
-	m.State = StateTranscribing
-	m.Config.PostProcessing.Enabled = true
-	m.toneName = "off"
-	updated, _ := m.Update(TranscriptionResultMsg{Text: "hello"})
-	model := updated.(Model)
-	if model.State != StatePasting {
-		t.Errorf("expected StatePasting when tone is off, got %d", model.State)
-	}
-}
-
-func TestPostProcessResultTransition(t *testing.T) {
-	m := newTestModel()
-	m.State = StatePostProcessing
-	updated, cmd := m.Update(PostProcessResultMsg{Text: "rewritten text", OriginalText: "original"})
-	model := updated.(Model)
-	if model.State != StatePasting {
-		t.Errorf("expected StatePasting, got %d", model.State)
-	}
-	if cmd == nil {
-		t.Error("expected paste command")
-	}
-}
-
-func TestPostProcessErrorGracefulDegradation(t *testing.T) {
-	m := newTestModel()
-	m.State = StatePostProcessing
-	updated, cmd := m.Update(PostProcessErrorMsg{Err: fmt.Errorf("timeout"), OriginalText: "original text"})
-	model := updated.(Model)
-	if model.State != StatePasting {
-		t.Errorf("expected StatePasting on PP error (graceful degradation), got %d", model.State)
-	}
-	if cmd == nil {
-		t.Error("expected paste command with original text")
-	}
-}
-
 func TestToneCycleKeyP(t *testing.T) {
 	defer postprocess.ResetTones()
 
@@ -518,6 +611,9 @@ func TestToneCycleKeyP(t *testing.T) {
 	if !model.Config.PostProcessing.Enabled {
 		t.Error("expected post-processing enabled after cycling to formal")
 	}
+	if tone := model.Pipeline.Status().Tone; tone != "formal" {
+		t.Errorf("expected pipeline tone formal, got %s", tone)
+	}
 	if cmd == nil {
 		t.Error("expected save config command")
 	}
@@ -540,6 +636,9 @@ func TestToneCycleKeyPToOff(t *testing.T) {
 	if model.Config.PostProcessing.Enabled {
 		t.Error("expected post-processing disabled after cycling to off")
 	}
+	if tone := model.Pipeline.Status().Tone; tone != "off" {
+		t.Errorf("expected pipeline tone off, got %s", tone)
+	}
 }
 
 func TestModelCycleKeyM(t *testing.T) {
@@ -562,6 +661,31 @@ func TestModelCycleKeyM(t *testing.T) {
 	}
 }
 
+func TestSettingsEventAdoptsExternalToneChange(t *testing.T) {
+	m := newTestModel()
+	m.toneName = "off"
+	if err := m.Pipeline.SetPostProcessing("direct", "mistral"); err != nil {
+		t.Fatalf("SetPostProcessing: %v", err)
+	}
+	updated, cmd := m.Update(event(pipeline.EventSettings, m.Pipeline.Status()))
+	model := updated.(Model)
+	if model.toneName != "direct" || model.ppModelName != "mistral" {
+		t.Errorf("expected tone direct/mistral, got %s/%s", model.toneName, model.ppModelName)
+	}
+	if !model.Config.PostProcessing.Enabled || model.Config.PostProcessing.Tone != "direct" {
+		t.Errorf("expected config updated, got %+v", model.Config.PostProcessing)
+	}
+	if cmd == nil {
+		t.Error("expected save config command")
+	}
+
+	// The TUI's own change comes back as an event that needs no action.
+	_, cmd = model.Update(event(pipeline.EventSettings, model.Pipeline.Status()))
+	if cmd != nil {
+		t.Error("expected no command when settings already match")
+	}
+}
+
 func TestModelCycleKeyMIgnoredWhenOff(t *testing.T) {
 	m := newTestModel()
 	m.toneName = "off"
@@ -690,3 +814,318 @@ func TestPPModelsListKeepsConfiguredWhenFound(t *testing.T) {
 func testKeyMsg(key string) tea.KeyMsg {
 	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
 }
+
+func TestLanguageKeyCycles(t *testing.T) {
+	m := newTestModel()
+	m.Config.Transcription.Language = "de"
+	m.Config.Transcription.Languages = []string{"en", "DE"}
+	m.languages = languageChoices(&m.Config.Transcription)
+	if got := strings.Join(m.languages, ","); got != "auto,de,en" {
+		t.Fatalf("expected auto, the configured language, then the list; got %s", got)
+	}
+	m.language = "de"
+
+	for _, want := range []string{"en", "auto", "de"} {
+		updated, cmd := m.Update(testKeyMsg("l"))
+		m = updated.(Model)
+		if m.language != want || m.Pipeline.Status().Language != want {
+			t.Errorf("expected language %s, got model %s, pipeline %s", want, m.language, m.Pipeline.Status().Language)
+		}
+		if m.Config.Transcription.Language != want {
+			t.Errorf("expected config language %s, got %s", want, m.Config.Transcription.Language)
+		}
+		if cmd == nil {
+			t.Error("expected a config save command")
+		}
+	}
+}
+
+func newHistoryTestModel(t *testing.T) Model {
+	t.Helper()
+	m := newTestModel()
+	m.History = history.New(&config.HistoryConfig{Path: filepath.Join(t.TempDir(), "history.jsonl")})
+	return m
+}
+
+func TestPastedEventAppendsToOpenHistory(t *testing.T) {
+	m := newHistoryTestModel(t)
+	entry := &history.Entry{Raw: "hello there", Text: "hello there"}
+
+	// Closed browser: nothing to update.
+	updated, _ := m.Update(pipeline.Event{Kind: pipeline.EventPasted, Entry: entry})
+	if n := len(updated.(Model).historyView.entries); n != 0 {
+		t.Errorf("expected no entries while the browser is closed, got %d", n)
+	}
+
+	m.historyView = historyView{open: true}
+	updated, _ = m.Update(pipeline.Event{Kind: pipeline.EventPasted, Entry: entry})
+	model := updated.(Model)
+	if len(model.historyView.entries) != 1 || model.historyView.entries[0].Text != "hello there" {
+		t.Errorf("expected pasted entry appended to the open browser, got %+v", model.historyView.entries)
+	}
+
+	// A re-paste carries no entry.
+	updated, _ = model.Update(pipeline.Event{Kind: pipeline.EventPasted})
+	if n := len(updated.(Model).historyView.entries); n != 1 {
+		t.Errorf("expected re-paste not to add an entry, got %d", n)
+	}
+}
+
+func TestHistoryKeyOpensBrowser(t *testing.T) {
+	m := newHistoryTestModel(t)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	model := updated.(Model)
+	if !model.historyView.open {
+		t.Fatal("expected history view open after 'h'")
+	}
+	if cmd == nil {
+		t.Error("expected history load command")
+	}
+
+	// 'h' without a history store does nothing.
+	plain := newTestModel()
+	updated, _ = plain.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if updated.(Model).historyView.open {
+		t.Error("expected no history view when history is disabled")
+	}
+}
+
+func TestHistoryBrowserSearchAndNavigate(t *testing.T) {
+	m := newHistoryTestModel(t)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	m.historyView = historyView{open: true}
+	updated, _ := m.Update(historyLoadedMsg{entries: []history.Entry{
+		{Time: base, Raw: "first report", Text: "First report."},
+		{Time: base.Add(time.Minute), Raw: "lunch", Text: "Lunch."},
+		{Time: base.Add(2 * time.Minute), Raw: "second report", Text: "Second report."},
+	}})
+	model := updated.(Model)
+
+	if e, _ := model.historyView.selected(); e.Raw != "second report" {
+		t.Errorf("expected newest entry selected first, got %q", e.Raw)
+	}
+
+	press := func(m Model, keys ...tea.KeyMsg) Model {
+		for _, k := range keys {
+			next, _ := m.Update(k)
+			m = next.(Model)
+		}
+		return m
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	model = press(model, runes("j"), runes("j"), runes("j"))
+	if model.historyView.cursor != 2 {
+		t.Errorf("expected cursor clamped at 2, got %d", model.historyView.cursor)
+	}
+
+	model = press(model, runes("/"), runes("r"), runes("e"), runes("p"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.historyView.searching {
+		t.Error("expected enter to finish search input")
+	}
+	if n := len(model.historyView.visible()); n != 2 {
+		t.Errorf("expected 2 matches for 'rep', got %d", n)
+	}
+	if !contains(model.View(), "Second report.") {
+		t.Error("expected view to list matching entries")
+	}
+	if contains(model.View(), "Lunch.") {
+		t.Error("expected non-matching entries hidden")
+	}
+
+	model = press(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.historyView.open {
+		t.Error("expected esc to close the history view")
+	}
+}
+
+func TestHistoryRepaste(t *testing.T) {
+	m := newHistoryTestModel(t)
+	pasted := make(chan string, 1)
+	m.Pipeline.SetPasteFunc(func(text string, _ int, _ string) error {
+		pasted <- text
+		return nil
+	})
+	m.historyView = historyView{open: true, entries: []history.Entry{{Raw: "again", Text: "Again."}}}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model := updated.(Model)
+	if model.State != StatePasting {
+		t.Errorf("expected StatePasting after re-paste, got %s", model.State)
+	}
+	if model.historyView.open {
+		t.Error("expected history view closed after re-paste")
+	}
+	select {
+	case text := <-pasted:
+		if text != "Again." {
+			t.Errorf("expected re-paste of 'Again.', got %q", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected the pipeline to paste the entry")
+	}
+}
+
+func TestUndoKeyRefocusesPastedWindow(t *testing.T) {
+	m := newTestModel()
+	focused, activated := make(chan string, 1), make(chan string, 1)
+	erased := make(chan int, 1)
+	focused <- "42"
+	m.Pipeline.SetFocusFuncs(func() (string, error) {
+		w := <-focused
+		focused <- "7" // the terminal, once the user switches to it
+		return w, nil
+	}, func(w string) error {
+		activated <- w
+		return nil
+	})
+	m.Pipeline.SetBackspaceFunc(func(n int) error {
+		erased <- n
+		return nil
+	})
+	events, unsubscribe := m.Pipeline.Subscribe()
+	defer unsubscribe()
+	if err := m.Pipeline.Repaste("hello", 0); err != nil {
+		t.Fatalf("Repaste: %v", err)
+	}
+	for ev := range events {
+		if ev.Kind == pipeline.EventPasted {
+			break
+		}
+	}
+
+	updated, cmd := m.Update(testKeyMsg("u"))
+	if cmd == nil {
+		t.Fatal("expected u to return the undo command")
+	}
+	updated, _ = updated.(Model).Update(cmd())
+	m = updated.(Model)
+	m.LastTranscript = "hello"
+	select {
+	case w := <-activated:
+		if w != "42" {
+			t.Errorf("expected the pasted window to be refocused, got %q", w)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected u to refocus the pasted window")
+	}
+	select {
+	case n := <-erased:
+		if n != len("hello") {
+			t.Errorf("expected %d characters erased, got %d", len("hello"), n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("expected u to erase the last paste")
+	}
+	if !contains(m.View(), "u: undo") {
+		t.Error("expected the footer to offer undo")
+	}
+}
+
+func TestUndoKeyShowsError(t *testing.T) {
+	m := newTestModel()
+	updated, cmd := m.Update(testKeyMsg("u"))
+	updated, _ = updated.(Model).Update(cmd())
+	m = updated.(Model)
+	if !contains(m.View(), "Undo: "+pipeline.ErrNothingToUndo.Error()) {
+		t.Errorf("expected the status bar to say why undo failed, got:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(testKeyMsg("u"))
+	if contains(updated.(Model).View(), "Undo:") {
+		t.Error("expected a new u press to clear the old error")
+	}
+}
+
+// fakeCapture is a hotkey.Capture whose keys the test sends itself.
+type fakeCapture struct{}
+
+func (fakeCapture) Start(ctx context.Context, _ func(hotkey.KeyPress)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (fakeCapture) Stop()             {}
+func (fakeCapture) Devices() []string { return []string{"Test Keyboard (/dev/input/event0)"} }
+
+func TestBindHotkey(t *testing.T) {
+	m := newTestModel()
+	if contains(m.View(), "b: bind hotkey") {
+		t.Error("expected no bind key without a capture")
+	}
+	m.NewCapture = func() (hotkey.Capture, error) { return fakeCapture{}, nil }
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = updated.(Model)
+	if !m.bindView.open || cmd == nil {
+		t.Fatal("expected b to open the capture")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	defer m.bindView.stop()
+	if got := m.View(); !contains(got, "any of 1 devices") {
+		t.Errorf("expected the capture to be listening, got %q", got)
+	}
+
+	// Keys typed in the terminal do not reach the main view.
+	before := m.Config.Hotkey.Key
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m = updated.(Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.Config.Hotkey.Key != before {
+		t.Fatalf("expected nothing saved before a combo is captured, got %s", m.Config.Hotkey.Key)
+	}
+
+	press := hotkey.KeyPress{Name: "KEY_SPACE", Code: 57, Device: "Test Keyboard", Combo: "Ctrl+KEY_SPACE"}
+	updated, _ = m.Update(bindKeyMsg{press: press})
+	m = updated.(Model)
+	if got := m.View(); !contains(got, "Pressed: KEY_SPACE (code 57)") || contains(got, "New hotkey:") {
+		t.Errorf("expected the press shown but no combo yet, got %q", got)
+	}
+	press.Done = true
+	updated, _ = m.Update(bindKeyMsg{press: press})
+	m = updated.(Model)
+	if got := m.View(); !contains(got, "New hotkey: Ctrl+KEY_SPACE") {
+		t.Errorf("expected the captured combo, got %q", got)
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.Config.Hotkey.Key != "Ctrl+KEY_SPACE" || cmd == nil {
+		t.Errorf("expected the combo saved as hotkey.key, got %s", m.Config.Hotkey.Key)
+	}
+	if m.bindView.ctx.Err() == nil {
+		t.Error("expected the capture stopped after saving")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(Model).bindView.open {
+		t.Error("expected esc to close the capture")
+	}
+}
+
+func TestBindModelAddsBinding(t *testing.T) {
+	cfg := config.Default()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	var m tea.Model = NewBindModel(cfg, path, hotkey.ActionUndo, func() (hotkey.Capture, error) { return fakeCapture{}, nil })
+
+	m, _ = m.Update(m.Init()())
+	m, _ = m.Update(bindKeyMsg{press: hotkey.KeyPress{Name: "BTN_SIDE", Code: 0x113, Combo: "BTN_SIDE", Done: true}})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to save")
+	}
+	m, _ = m.Update(cmd())
+	if b := m.(BindModel); b.Err != nil || b.Saved != "BTN_SIDE" {
+		t.Fatalf("expected BTN_SIDE saved, got %q, %v", b.Saved, b.Err)
+	}
+
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Hotkey.Bindings) != 1 || loaded.Hotkey.Bindings[0].Key != "BTN_SIDE" || loaded.Hotkey.Bindings[0].Action != hotkey.ActionUndo {
+		t.Errorf("expected an undo binding for BTN_SIDE, got %+v", loaded.Hotkey.Bindings)
+	}
+	if loaded.Hotkey.Key != cfg.Hotkey.Key {
+		t.Errorf("expected hotkey.key unchanged, got %s", loaded.Hotkey.Key)
+	}
+}
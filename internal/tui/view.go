@@ -14,6 +14,7 @@ var (
 	borderStyle          lipgloss.Style
 	labelStyle           lipgloss.Style
 	transcriptStyle      lipgloss.Style
+	uncertainStyle       lipgloss.Style
 	hotkeyStyle          lipgloss.Style
 	quitStyle            lipgloss.Style
 	idleBadge            lipgloss.Style
@@ -65,23 +66,62 @@ func (m Model) View() string {
 		b.WriteString("\n")
 		b.WriteString(quitStyle.Render("  Hint: run 'palaver setup' to install a local backend, or set transcription.base_url in config"))
 	}
+	if m.PasteErr != "" {
+		b.WriteString("\n")
+		b.WriteString(quitStyle.Render("  Paste: " + m.PasteErr))
+	}
+	if m.LanguageWarn != "" {
+		b.WriteString("\n")
+		b.WriteString(statusBadStyle.Width(panelContentWidth).Render("  Language: " + m.LanguageWarn))
+	}
+	if m.undoErr != "" {
+		b.WriteString("\n")
+		b.WriteString(statusBadStyle.Render("  Undo: " + m.undoErr))
+	}
 	b.WriteString("\n\n")
 
 	// Status / Visualizer
 	b.WriteString(labelStyle.Render("Status:  "))
 	b.WriteString(m.renderBadge())
-	if m.State == StateRecording {
+	if m.State == StateRecording || (m.Armed && m.State == StateIdle) {
 		b.WriteString(bodyStyle.Render("  "))
 		b.WriteString(m.renderVisualizer())
 	}
 	b.WriteString("\n\n")
 
+	if m.historyView.open {
+		b.WriteString(m.renderHistory())
+	} else if m.bindView.open {
+		b.WriteString(m.bindView.render("hotkey.key", m.Config.Hotkey.Key))
+	} else {
+		m.renderMain(&b)
+	}
+
+	// Debug sub-panel (inside main panel)
+	if m.DebugMode || len(m.DebugEntries) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(m.renderDebugPanel())
+	}
+
+	return borderStyle.Width(panelWidthForStyle).Render(b.String())
+}
+
+// renderMain renders the last transcription, hotkey hint, and key footer.
+func (m Model) renderMain(b *strings.Builder) {
 	// Last transcription (word-wrapped)
 	b.WriteString(labelStyle.Render("Last transcription:"))
 	b.WriteString("\n")
-	if m.LastTranscript != "" {
+	if m.PartialTranscript != "" {
+		wrapped := transcriptStyle.Width(panelContentWidth).Render(fmt.Sprintf("%q …", m.PartialTranscript))
+		b.WriteString(wrapped)
+	} else if m.LastTranscript != "" {
 		wrapped := transcriptStyle.Width(panelContentWidth).Render(fmt.Sprintf("%q", m.LastTranscript))
 		b.WriteString(wrapped)
+		// Segments the backend was unsure of, worth checking before sending.
+		for _, seg := range m.Uncertain {
+			b.WriteString("\n")
+			b.WriteString(uncertainStyle.Width(panelContentWidth).Render(fmt.Sprintf("? low confidence: %q", seg)))
+		}
 	} else {
 		b.WriteString(bodyStyle.Render("(none yet)"))
 	}
@@ -89,25 +129,33 @@ func (m Model) View() string {
 
 	// Hotkey info
 	keyName := strings.TrimPrefix(m.HotkeyName, "KEY_")
-	b.WriteString(hotkeyStyle.Render(fmt.Sprintf("Hotkey: %s (hold to record)", keyName)))
+	b.WriteString(hotkeyStyle.Render(fmt.Sprintf("Hotkey: %s (%s)", keyName, m.hotkeyHint())))
 	b.WriteString("\n")
+	if m.InputDevices != nil {
+		b.WriteString(m.renderInputDevices())
+		b.WriteString("\n")
+	}
 	footer := "Press q to quit  t: theme (" + m.themeName + ")"
 	footer += "  p: tone (" + m.toneName + ")"
 	if m.Config.PostProcessing.Enabled && strings.ToLower(m.toneName) != "off" {
 		footer += "  m: model (" + m.ppModelName + ")"
 	}
+	if len(m.languages) > 1 {
+		footer += "  l: language (" + m.language + ")"
+	}
+	if m.LastTranscript != "" {
+		footer += "  u: undo"
+	}
+	if m.History != nil {
+		footer += "  h: history"
+	}
 	if m.Server != nil {
 		footer += "  r: restart server"
 	}
-	b.WriteString(quitStyle.Render(footer))
-
-	// Debug sub-panel (inside main panel)
-	if m.DebugMode || len(m.DebugEntries) > 0 {
-		b.WriteString("\n\n")
-		b.WriteString(m.renderDebugPanel())
+	if m.NewCapture != nil {
+		footer += "  b: bind hotkey"
 	}
-
-	return borderStyle.Width(panelWidthForStyle).Render(b.String())
+	b.WriteString(quitStyle.Render(footer))
 }
 
 const debugPanelMaxLines = 5
@@ -208,6 +256,9 @@ func (m Model) renderStatusBar() string {
 	default:
 		if m.BackendOnline {
 			backend = statusOkStyle.Render("✓")
+			if m.ServedBy != "" {
+				backend += quitStyle.Render(" (" + m.ServedBy + ")")
+			}
 		} else {
 			backend = statusBadStyle.Render("✗")
 		}
@@ -217,12 +268,46 @@ func (m Model) renderStatusBar() string {
 		modelName = "n/a"
 	}
 	model := quitStyle.Render(modelName)
-	return quitStyle.Render("Mic: ") + mic + quitStyle.Render("  Backend: ") + backend + quitStyle.Render("  Model: ") + model
+	bar := quitStyle.Render("Mic: ") + mic + quitStyle.Render("  Backend: ") + backend + quitStyle.Render("  Model: ") + model
+	if m.PasteBackend != "" {
+		paste := statusOkStyle.Render("✓")
+		if m.PasteErr != "" {
+			paste = statusBadStyle.Render("✗")
+		}
+		bar += quitStyle.Render("  Paste: ") + paste + quitStyle.Render(" ("+m.PasteBackend+")")
+	}
+	return bar
+}
+
+// renderInputDevices lists the devices the hotkey is read from.
+func (m Model) renderInputDevices() string {
+	if len(m.InputDevices) == 0 {
+		return statusBadStyle.Render("Devices: none (plug one in, or check hotkey.device)")
+	}
+	return quitStyle.Width(panelContentWidth).Render("Devices: " + strings.Join(m.InputDevices, ", "))
+}
+
+// hotkeyHint describes how the hotkey drives recording in the current mode.
+func (m Model) hotkeyHint() string {
+	if m.Trigger == "vad" {
+		return "tap to pause/resume listening"
+	}
+	switch m.HotkeyMode {
+	case "toggle":
+		return "tap to start/stop"
+	case "hybrid":
+		return "hold to record, tap to latch"
+	default:
+		return "hold to record"
+	}
 }
 
 func (m Model) renderBadge() string {
 	switch m.State {
 	case StateRecording:
+		if m.HotkeyMode == "toggle" || m.Latched {
+			return recordingBadge.Render("● Recording (tap to stop)...")
+		}
 		return recordingBadge.Render("● Recording...")
 	case StateTranscribing:
 		return transcribingBadge.Render("● Transcribing...")
@@ -237,6 +322,12 @@ func (m Model) renderBadge() string {
 		}
 		return errorBadge.Render(fmt.Sprintf("● Error: %s", errText))
 	default:
+		if m.Armed {
+			return idleBadge.Render("● Listening...")
+		}
+		if m.Trigger == "vad" {
+			return idleBadge.Render("● Paused")
+		}
 		return idleBadge.Render("● Idle")
 	}
 }
@@ -0,0 +1,132 @@
+// Package vocabulary fixes words a transcription backend consistently
+// mishears, such as product names and jargon.
+package vocabulary
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+// Vocabulary holds the configured terms and replacement rules. A nil
+// *Vocabulary leaves text unchanged.
+type Vocabulary struct {
+	terms []string
+	rules []rule
+}
+
+// rule replaces matches of re with to. Literal rules only match whole
+// words and insert to verbatim; regex rules expand $1-style references.
+type rule struct {
+	re      *regexp.Regexp
+	to      string
+	literal bool
+}
+
+// New compiles the [vocabulary] config. It returns an error naming the
+// first invalid rule.
+func New(cfg *config.VocabularyConfig) (*Vocabulary, error) {
+	v := &Vocabulary{}
+	// Terms come first so explicit replacements have the last word.
+	for _, term := range cfg.Terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		v.terms = append(v.terms, term)
+		v.rules = append(v.rules, literalRule(term, term))
+	}
+	for i, r := range cfg.Replace {
+		if r.From == "" {
+			return nil, fmt.Errorf("vocabulary replace %d: from is empty", i+1)
+		}
+		if !r.Regex {
+			v.rules = append(v.rules, literalRule(r.From, r.To))
+			continue
+		}
+		re, err := regexp.Compile(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary replace %d: %w", i+1, err)
+		}
+		v.rules = append(v.rules, rule{re: re, to: r.To})
+	}
+	return v, nil
+}
+
+func literalRule(from, to string) rule {
+	return rule{re: regexp.MustCompile("(?i)" + regexp.QuoteMeta(from)), to: to, literal: true}
+}
+
+// Apply runs every rule over text in order and returns the result.
+func (v *Vocabulary) Apply(text string) string {
+	if v == nil {
+		return text
+	}
+	for _, r := range v.rules {
+		if r.literal {
+			text = replaceWords(text, r.re, r.to)
+		} else {
+			text = r.re.ReplaceAllString(text, r.to)
+		}
+	}
+	return text
+}
+
+// Prompt returns base followed by the terms, for backends that accept a
+// prompt to bias recognition.
+func (v *Vocabulary) Prompt(base string) string {
+	if v == nil || len(v.terms) == 0 {
+		return base
+	}
+	terms := strings.Join(v.terms, ", ") + "."
+	if base == "" {
+		return terms
+	}
+	return base + " " + terms
+}
+
+// replaceWords replaces matches of re that are not part of a longer word.
+func replaceWords(text string, re *regexp.Regexp, to string) string {
+	var b strings.Builder
+	last := 0
+	for pos := 0; pos < len(text); {
+		loc := re.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if !wordBoundary(text, start, end) {
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + size
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(to)
+		last, pos = end, end
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// wordBoundary reports whether text[start:end] is not directly preceded or
+// followed by a letter or digit.
+func wordBoundary(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
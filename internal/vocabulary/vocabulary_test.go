@@ -0,0 +1,126 @@
+package vocabulary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Danondso/palaver/internal/config"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.VocabularyConfig
+		in   string
+		want string
+	}{
+		{
+			name: "no rules",
+			in:   "leave me alone",
+			want: "leave me alone",
+		},
+		{
+			name: "literal replacement ignores case",
+			cfg:  config.VocabularyConfig{Replace: []config.Replacement{{From: "cube control", To: "kubectl"}}},
+			in:   "Run Cube Control get pods, then cube control logs.",
+			want: "Run kubectl get pods, then kubectl logs.",
+		},
+		{
+			name: "literal replacement matches whole words only",
+			cfg:  config.VocabularyConfig{Replace: []config.Replacement{{From: "graph", To: "Grafana"}}},
+			in:   "graph the graphs in paragraph graph",
+			want: "Grafana the graphs in paragraph Grafana",
+		},
+		{
+			name: "word boundaries are unicode-aware",
+			cfg:  config.VocabularyConfig{Replace: []config.Replacement{{From: "bar", To: "Bar"}}},
+			in:   "bar barü übar bar.",
+			want: "Bar barü übar Bar.",
+		},
+		{
+			name: "literal replacement inserts dollar signs verbatim",
+			cfg:  config.VocabularyConfig{Replace: []config.Replacement{{From: "five dollars", To: "$5"}}},
+			in:   "it costs five dollars",
+			want: "it costs $5",
+		},
+		{
+			name: "literal punctuation is not a pattern",
+			cfg:  config.VocabularyConfig{Replace: []config.Replacement{{From: "a.b", To: "x"}}},
+			in:   "a.b acb",
+			want: "x acb",
+		},
+		{
+			name: "regex replacement expands groups",
+			cfg: config.VocabularyConfig{Replace: []config.Replacement{
+				{From: `(?i)\bticket (\d+)\b`, To: "PAL-$1", Regex: true},
+			}},
+			in:   "Fixed ticket 42 and Ticket 7.",
+			want: "Fixed PAL-42 and PAL-7.",
+		},
+		{
+			name: "terms restore spelling",
+			cfg:  config.VocabularyConfig{Terms: []string{"Palaver", "PostgreSQL"}},
+			in:   "palaver stores it in postgresql, not palavers",
+			want: "Palaver stores it in PostgreSQL, not palavers",
+		},
+		{
+			name: "rules apply in order after terms",
+			cfg: config.VocabularyConfig{
+				Terms: []string{"Kubernetes"},
+				Replace: []config.Replacement{
+					{From: "cooper netties", To: "kubernetes"},
+					{From: "kubernetes", To: "K8s"},
+				},
+			},
+			in:   "deploy to cooper netties",
+			want: "deploy to K8s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := New(&tt.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := v.Apply(tt.in); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    config.Replacement
+		wantErr string
+	}{
+		{name: "empty from", rule: config.Replacement{To: "x"}, wantErr: "replace 1: from is empty"},
+		{name: "bad regex", rule: config.Replacement{From: "(unclosed", Regex: true}, wantErr: "replace 1: error parsing regexp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&config.VocabularyConfig{Replace: []config.Replacement{tt.rule}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	v, err := New(&config.VocabularyConfig{Terms: []string{"Palaver", " ", "Grafana"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Prompt(""); got != "Palaver, Grafana." {
+		t.Errorf("unexpected prompt: %q", got)
+	}
+	if got := v.Prompt("Meeting notes."); got != "Meeting notes. Palaver, Grafana." {
+		t.Errorf("unexpected prompt with base: %q", got)
+	}
+	var none *Vocabulary
+	if none.Prompt("base") != "base" || none.Apply("text") != "text" {
+		t.Error("expected a nil vocabulary to change nothing")
+	}
+}